@@ -9,6 +9,7 @@ import (
 
 	"github.com/ekristen/azure-nuke/pkg/common"
 
+	_ "github.com/ekristen/azure-nuke/pkg/commands/config"
 	_ "github.com/ekristen/azure-nuke/pkg/commands/list"
 	_ "github.com/ekristen/azure-nuke/pkg/commands/run"
 
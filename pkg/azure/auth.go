@@ -2,10 +2,16 @@ package azure
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"net/http"
 	"os"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 
 	"github.com/hashicorp/go-azure-sdk/sdk/auth"
@@ -13,15 +19,112 @@ import (
 	"github.com/hashicorp/go-azure-sdk/sdk/environments"
 )
 
+const (
+	// AuthMethodClientCredentials authenticates as an app registration with a client secret, certificate or
+	// federated token
+	AuthMethodClientCredentials = "client-credentials"
+	// AuthMethodAzureCLI authenticates with the account that is logged in to the azure cli (az login)
+	AuthMethodAzureCLI = "azure-cli"
+	// AuthMethodManagedIdentity authenticates with the system-assigned managed identity of the host, or the
+	// user-assigned managed identity if a client-id is provided
+	AuthMethodManagedIdentity = "managed-identity"
+	// AuthMethodDeviceCode authenticates a user interactively with a device code
+	AuthMethodDeviceCode = "device-code"
+	// AuthMethodDefault authenticates with the azidentity DefaultAzureCredential chain (environment, workload
+	// identity, managed identity, azure cli and azure developer cli)
+	AuthMethodDefault = "default"
+)
+
+// AuthMethods are the supported authentication methods
+var AuthMethods = []string{
+	AuthMethodClientCredentials,
+	AuthMethodAzureCLI,
+	AuthMethodManagedIdentity,
+	AuthMethodDeviceCode,
+	AuthMethodDefault,
+}
+
 func ConfigureAuth(
 	ctx context.Context,
-	environment, tenantID, clientID, clientSecret, clientCertFile, clientFedTokenFile string) (*Authorizers, error) {
+	environment, authMethod, tenantID, clientID, clientSecret, clientCertFile, clientFedTokenFile string,
+) (*Authorizers, error) {
 	env, err := environments.FromName(environment)
 	if err != nil {
 		return nil, err
 	}
 
-	authorizers := &Authorizers{}
+	cloudConfig, err := NewCloudConfiguration(env)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := NewPipeline(nil)
+
+	authorizers := &Authorizers{
+		Environment: env,
+		ClientOptions: azcore.ClientOptions{
+			Cloud:     cloudConfig,
+			Transport: pipeline.HTTPClient(),
+			Retry: policy.RetryOptions{
+				// Note: throttling (429) is retried by the pipeline, honouring the Retry-After header
+				StatusCodes: []int{
+					http.StatusRequestTimeout,
+					http.StatusInternalServerError,
+					http.StatusBadGateway,
+					http.StatusServiceUnavailable,
+					http.StatusGatewayTimeout,
+				},
+			},
+		},
+		Pipeline: pipeline,
+	}
+
+	if authMethod != "" && authMethod != AuthMethodClientCredentials {
+		err = configureTokenCredentialAuth(authorizers, authMethod, tenantID, clientID)
+	} else {
+		err = configureClientCredentialsAuth(ctx, authorizers,
+			tenantID, clientID, clientSecret, clientCertFile, clientFedTokenFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Note: the identity is resolved up front so that a nuke never removes the identity it is running as
+	identity, err := ResolveIdentity(ctx, authorizers.ResourceManager)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve the identity of the caller: %w", err)
+	}
+	authorizers.Identity = identity
+
+	if err := validateIdentity(identity, tenantID, clientID); err != nil {
+		return nil, err
+	}
+
+	return authorizers, nil
+}
+
+// validateIdentity checks that the token was issued by the tenant that is nuked and, when a client-id is configured,
+// to that client. The azure cli, the default credential chain and a managed identity pick the account from the
+// environment of the host, so without this check a run could authenticate to a different tenant than intended.
+func validateIdentity(identity *Identity, tenantID, clientID string) error {
+	if tenantID != "" && !strings.EqualFold(identity.TenantID, tenantID) {
+		return fmt.Errorf("authenticated to tenant %q instead of tenant %q", identity.TenantID, tenantID)
+	}
+
+	if clientID != "" && !identity.IsApplication(&clientID) {
+		return fmt.Errorf("authenticated as client %q instead of client %q", identity.AppID, clientID)
+	}
+
+	return nil
+}
+
+// configureClientCredentialsAuth configures the authorizers for an app registration with a client secret,
+// certificate or federated token
+func configureClientCredentialsAuth(
+	ctx context.Context, authorizers *Authorizers,
+	tenantID, clientID, clientSecret, clientCertFile, clientFedTokenFile string,
+) error {
+	env := authorizers.Environment
 
 	credentials := auth.Credentials{
 		Environment: *env,
@@ -36,9 +139,11 @@ func ConfigureAuth(
 		credentials.EnableAuthenticatingUsingClientSecret = true
 		credentials.ClientSecret = clientSecret
 
-		creds, err := azidentity.NewClientSecretCredential(tenantID, clientID, clientSecret, &azidentity.ClientSecretCredentialOptions{})
+		creds, err := azidentity.NewClientSecretCredential(tenantID, clientID, clientSecret, &azidentity.ClientSecretCredentialOptions{
+			ClientOptions: authorizers.ClientOptions,
+		})
 		if err != nil {
-			return nil, err
+			return err
 		}
 		authorizers.IdentityCreds = creds
 	} else if clientCertFile != "" {
@@ -48,24 +153,26 @@ func ConfigureAuth(
 
 		certData, err := os.ReadFile(clientCertFile)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		certs, pkey, err := azidentity.ParseCertificates(certData, nil)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		creds, err := azidentity.NewClientCertificateCredential(tenantID, clientID, certs, pkey, &azidentity.ClientCertificateCredentialOptions{})
+		creds, err := azidentity.NewClientCertificateCredential(tenantID, clientID, certs, pkey, &azidentity.ClientCertificateCredentialOptions{
+			ClientOptions: authorizers.ClientOptions,
+		})
 		if err != nil {
-			return nil, err
+			return err
 		}
 		authorizers.IdentityCreds = creds
 	} else if clientFedTokenFile != "" {
 		logrus.Debug("authentication type: federated token")
 		token, err := os.ReadFile(clientFedTokenFile)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		credentials.EnableAuthenticationUsingOIDC = true
 		credentials.OIDCAssertionToken = string(token)
@@ -74,21 +181,22 @@ func ConfigureAuth(
 			ClientID:      clientID,
 			TenantID:      tenantID,
 			TokenFilePath: clientFedTokenFile,
+			ClientOptions: authorizers.ClientOptions,
 		})
 		if err != nil {
-			return nil, err
+			return err
 		}
 		authorizers.IdentityCreds = creds
 	}
 
 	graphAuthorizer, err := auth.NewAuthorizerFromCredentials(ctx, credentials, env.MicrosoftGraph)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	mgmtAuthorizer, err := auth.NewAuthorizerFromCredentials(ctx, credentials, env.ResourceManager)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	authorizers.Management = autorest.AutorestAuthorizer(mgmtAuthorizer)
@@ -97,5 +205,126 @@ func ConfigureAuth(
 	authorizers.MicrosoftGraph = graphAuthorizer
 	authorizers.ResourceManager = mgmtAuthorizer
 
-	return authorizers, nil
+	return nil
+}
+
+// configureTokenCredentialAuth configures the authorizers with an azidentity credential for the auth method, the
+// hamilton and go-azure-sdk authorizers request their tokens from the same credential as the azcore based clients.
+func configureTokenCredentialAuth(authorizers *Authorizers, authMethod, tenantID, clientID string) error {
+	logrus.Debugf("authentication type: %s", authMethod)
+
+	creds, err := newTokenCredential(authMethod, tenantID, clientID, authorizers.ClientOptions)
+	if err != nil {
+		return err
+	}
+
+	graphAuthorizer, err := newTokenCredentialAuthorizer(creds, authorizers.Environment.MicrosoftGraph)
+	if err != nil {
+		return err
+	}
+
+	mgmtAuthorizer, err := newTokenCredentialAuthorizer(creds, authorizers.Environment.ResourceManager)
+	if err != nil {
+		return err
+	}
+
+	authorizers.IdentityCreds = creds
+
+	authorizers.Management = autorest.AutorestAuthorizer(mgmtAuthorizer)
+	authorizers.Graph = autorest.AutorestAuthorizer(graphAuthorizer)
+
+	authorizers.MicrosoftGraph = graphAuthorizer
+	authorizers.ResourceManager = mgmtAuthorizer
+
+	return nil
+}
+
+// newTokenCredential creates the azidentity credential for the auth method
+func newTokenCredential(
+	authMethod, tenantID, clientID string, clientOptions azcore.ClientOptions,
+) (azcore.TokenCredential, error) {
+	switch authMethod {
+	case AuthMethodAzureCLI:
+		// Note: the azure cli always authenticates as the account that is logged in
+		if clientID != "" {
+			return nil, fmt.Errorf("a client-id is not supported by the %s auth method", AuthMethodAzureCLI)
+		}
+
+		return azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{
+			TenantID: tenantID,
+		})
+	case AuthMethodManagedIdentity:
+		opts := &azidentity.ManagedIdentityCredentialOptions{
+			ClientOptions: clientOptions,
+		}
+		if clientID != "" {
+			opts.ID = azidentity.ClientID(clientID)
+		}
+		return azidentity.NewManagedIdentityCredential(opts)
+	case AuthMethodDeviceCode:
+		return azidentity.NewDeviceCodeCredential(&azidentity.DeviceCodeCredentialOptions{
+			ClientOptions: clientOptions,
+			TenantID:      tenantID,
+			ClientID:      clientID,
+			UserPrompt: func(_ context.Context, msg azidentity.DeviceCodeMessage) error {
+				_, err := fmt.Fprintln(os.Stderr, msg.Message)
+				return err
+			},
+		})
+	case AuthMethodDefault:
+		return azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{
+			ClientOptions: clientOptions,
+			TenantID:      tenantID,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported auth method: %s", authMethod)
+	}
+}
+
+// ConfigureReplay configures the authorizers to replay a recording instead of authenticating, every client sends its
+// requests to the replayer and the identity is the one that was recorded for the tenant
+func ConfigureReplay(environment, tenantID string, replayer *Replayer) (*Authorizers, error) {
+	env, err := environments.FromName(environment)
+	if err != nil {
+		return nil, err
+	}
+
+	cloudConfig, err := NewCloudConfiguration(env)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := replayer.Identity(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	graphAuthorizer, err := newTokenCredentialAuthorizer(replayCredential{}, env.MicrosoftGraph)
+	if err != nil {
+		return nil, err
+	}
+
+	mgmtAuthorizer, err := newTokenCredentialAuthorizer(replayCredential{}, env.ResourceManager)
+	if err != nil {
+		return nil, err
+	}
+
+	// Note: nothing is sent to azure, so there is nothing to rate limit either
+	pipeline := NewPipeline(replayer)
+	pipeline.SetRateLimit(math.MaxInt32, math.MaxInt32)
+
+	return &Authorizers{
+		Graph:           autorest.AutorestAuthorizer(graphAuthorizer),
+		Management:      autorest.AutorestAuthorizer(mgmtAuthorizer),
+		MicrosoftGraph:  graphAuthorizer,
+		ResourceManager: mgmtAuthorizer,
+		IdentityCreds:   replayCredential{},
+		Environment:     env,
+		ClientOptions: azcore.ClientOptions{
+			Cloud:     cloudConfig,
+			Transport: pipeline.HTTPClient(),
+		},
+		Pipeline: pipeline,
+		Identity: identity,
+	}, nil
 }
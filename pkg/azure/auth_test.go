@@ -0,0 +1,282 @@
+package azure
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+func TestConfigureAuthManagedIdentity(t *testing.T) {
+	requests := fakeManagedIdentityEndpoint(t, "tenant")
+
+	authorizers, err := ConfigureAuth(context.TODO(),
+		"global", AuthMethodManagedIdentity, "tenant", "user-assigned", "", "", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://management.azure.com", http.NoBody)
+
+	token, err := authorizers.ResourceManager.Token(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, fakeAccessToken("https://management.azure.com"), token.AccessToken)
+	assert.Equal(t, "Bearer", token.TokenType)
+
+	token, err = authorizers.MicrosoftGraph.Token(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, fakeAccessToken("https://graph.microsoft.com"), token.AccessToken)
+
+	accessToken, err := authorizers.IdentityCreds.GetToken(context.TODO(), policy.TokenRequestOptions{
+		Scopes: []string{"https://management.azure.com/.default"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fakeAccessToken("https://management.azure.com"), accessToken.Token)
+
+	assert.Equal(t, &Identity{ObjectID: "object-id", AppID: "user-assigned", TenantID: "tenant"}, authorizers.Identity)
+
+	assert.NotNil(t, authorizers.Management)
+	assert.NotNil(t, authorizers.Graph)
+
+	// Note: the authorizers share the credential and its token cache, so every resource is only requested once
+	assert.Equal(t, int64(2), requests.Load())
+}
+
+func TestConfigureAuthAzureCLI(t *testing.T) {
+	calls := fakeAzureCLI(t, "cli-app", "tenant")
+
+	authorizers, err := ConfigureAuth(context.TODO(), "global", AuthMethodAzureCLI, "tenant", "", "", "", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, &Identity{ObjectID: "object-id", AppID: "cli-app", TenantID: "tenant"}, authorizers.Identity)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://graph.microsoft.com", http.NoBody)
+	token, err := authorizers.MicrosoftGraph.Token(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, fakeAccessTokenFor("https://graph.microsoft.com", "cli-app", "tenant"), token.AccessToken)
+
+	data, err := os.ReadFile(calls)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"account get-access-token -o json --resource https://management.azure.com --tenant tenant",
+		"account get-access-token -o json --resource https://graph.microsoft.com --tenant tenant",
+	}, strings.Split(strings.TrimSpace(string(data)), "\n"))
+}
+
+func TestConfigureAuthDefault(t *testing.T) {
+	// Note: the environment and workload identity credentials of the chain are not configured, so the managed
+	// identity credential authenticates, AZURE_CLIENT_ID selects its user-assigned identity
+	unsetEnv(t, "AZURE_TENANT_ID", "AZURE_CLIENT_SECRET", "AZURE_CLIENT_CERTIFICATE_PATH", "AZURE_USERNAME",
+		"AZURE_FEDERATED_TOKEN_FILE")
+	t.Setenv("AZURE_CLIENT_ID", "user-assigned")
+
+	requests := fakeManagedIdentityEndpoint(t, "tenant")
+
+	authorizers, err := ConfigureAuth(context.TODO(), "global", AuthMethodDefault, "tenant", "", "", "", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, &Identity{ObjectID: "object-id", AppID: "user-assigned", TenantID: "tenant"}, authorizers.Identity)
+	assert.Equal(t, int64(1), requests.Load())
+}
+
+func TestConfigureAuthValidation(t *testing.T) {
+	cases := map[string]struct {
+		authMethod string
+		tenantID   string
+		clientID   string
+		err        string
+	}{
+		"azure-cli": {
+			authMethod: AuthMethodAzureCLI,
+			tenantID:   "tenant",
+		},
+		"azure-cli with another tenant": {
+			authMethod: AuthMethodAzureCLI,
+			tenantID:   "other-tenant",
+			err:        `authenticated to tenant "tenant" instead of tenant "other-tenant"`,
+		},
+		"azure-cli with a client-id": {
+			authMethod: AuthMethodAzureCLI,
+			tenantID:   "tenant",
+			clientID:   "cli-app",
+			err:        "a client-id is not supported by the azure-cli auth method",
+		},
+		"default": {
+			authMethod: AuthMethodDefault,
+			tenantID:   "tenant",
+			clientID:   "user-assigned",
+		},
+		"default with another tenant": {
+			authMethod: AuthMethodDefault,
+			tenantID:   "other-tenant",
+			err:        `authenticated to tenant "tenant" instead of tenant "other-tenant"`,
+		},
+		"default with another client-id": {
+			authMethod: AuthMethodDefault,
+			tenantID:   "tenant",
+			clientID:   "someone-else",
+			err:        `authenticated as client "user-assigned" instead of client "someone-else"`,
+		},
+		"managed-identity": {
+			authMethod: AuthMethodManagedIdentity,
+			tenantID:   "tenant",
+		},
+		"managed-identity with another tenant": {
+			authMethod: AuthMethodManagedIdentity,
+			tenantID:   "other-tenant",
+			err:        `authenticated to tenant "tenant" instead of tenant "other-tenant"`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			unsetEnv(t, "AZURE_TENANT_ID", "AZURE_CLIENT_SECRET", "AZURE_CLIENT_CERTIFICATE_PATH", "AZURE_USERNAME",
+				"AZURE_FEDERATED_TOKEN_FILE")
+			t.Setenv("AZURE_CLIENT_ID", "user-assigned")
+
+			fakeAzureCLI(t, "cli-app", "tenant")
+			fakeManagedIdentityEndpoint(t, "tenant")
+
+			_, err := ConfigureAuth(context.TODO(), "global", tc.authMethod, tc.tenantID, tc.clientID, "", "", "")
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
+
+// unsetEnv unsets the environment variables for the duration of the test, the credentials of the default chain check
+// if a variable is set rather than if it is empty
+func unsetEnv(t *testing.T, keys ...string) {
+	t.Helper()
+
+	for _, key := range keys {
+		t.Setenv(key, "")
+		if err := os.Unsetenv(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// fakeManagedIdentityEndpoint serves managed identity tokens issued by the tenant, the app service variables point
+// the managed identity credential at it. The tokens are issued to the requested client ID, or to "system-assigned".
+func fakeManagedIdentityEndpoint(t *testing.T, tenantID string) *atomic.Int64 {
+	t.Helper()
+
+	var requests atomic.Int64
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		if r.Header.Get("X-IDENTITY-HEADER") != "fake-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		resource := r.URL.Query().Get("resource")
+
+		clientID := r.URL.Query().Get("client_id")
+		if clientID == "" {
+			clientID = "system-assigned"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"access_token":"%s","expires_on":"%d","resource":"%s","token_type":"Bearer"}`,
+			fakeAccessTokenFor(resource, clientID, tenantID), time.Now().Add(time.Hour).Unix(), resource)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("IDENTITY_ENDPOINT", server.URL)
+	t.Setenv("IDENTITY_HEADER", "fake-secret")
+
+	return &requests
+}
+
+// fakeAzureCLI puts an az binary on the path that hands out tokens issued by the tenant to the app, the arguments of
+// every call are written to the returned file
+func fakeAzureCLI(t *testing.T, appID, tenantID string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+
+	var tokens strings.Builder
+	for _, resource := range []string{"https://management.azure.com", "https://graph.microsoft.com"} {
+		_, _ = fmt.Fprintf(&tokens, "  %s) token=%s ;;\n", resource, fakeAccessTokenFor(resource, appID, tenantID))
+	}
+
+	script := fmt.Sprintf(`#!/bin/sh
+echo "$*" >> %q
+while [ $# -gt 0 ]; do
+  if [ "$1" = "--resource" ]; then resource="$2"; fi
+  shift
+done
+case "$resource" in
+%s  *) echo "unknown resource $resource" >&2; exit 1 ;;
+esac
+echo '{"accessToken":"'$token'","expires_on":%d,"tokenType":"Bearer"}'
+`, calls, tokens.String(), time.Now().Add(time.Hour).Unix())
+
+	if err := os.WriteFile(filepath.Join(dir, "az"), []byte(script), 0o700); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return calls
+}
+
+// fakeAccessToken returns an unsigned jwt for the resource as it would be issued to a user-assigned managed identity
+func fakeAccessToken(resource string) string {
+	return fakeAccessTokenFor(resource, "user-assigned", "tenant")
+}
+
+// fakeAccessTokenFor returns an unsigned jwt for the resource as it would be issued by the tenant to the app
+func fakeAccessTokenFor(resource, appID, tenantID string) string {
+	claims := fmt.Sprintf(`{"aud":%q,"oid":"object-id","appid":%q,"tid":%q}`, resource, appID, tenantID)
+	return "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".signature"
+}
+
+func TestParseIdentity(t *testing.T) {
+	identity, err := parseIdentity(fakeAccessToken("https://management.azure.com"))
+	assert.NoError(t, err)
+	assert.True(t, identity.IsObject(ptr.String("OBJECT-ID")))
+	assert.True(t, identity.IsApplication(ptr.String("user-assigned")))
+	assert.False(t, identity.IsObject(ptr.String("someone-else")))
+
+	// Note: v2 tokens carry the application ID in the azp claim
+	claims := base64.RawURLEncoding.EncodeToString([]byte(`{"oid":"object-id","azp":"app-id"}`))
+	identity, err = parseIdentity("header." + claims + ".signature")
+	assert.NoError(t, err)
+	assert.Equal(t, "app-id", identity.AppID)
+
+	_, err = parseIdentity("not-a-jwt")
+	assert.Error(t, err)
+
+	var unknown *Identity
+	assert.False(t, unknown.IsObject(ptr.String("object-id")))
+	assert.False(t, unknown.IsApplication(ptr.String("app-id")))
+}
+
+func TestConfigureAuthUnsupportedMethod(t *testing.T) {
+	_, err := ConfigureAuth(context.TODO(), "global", "password", "tenant", "", "", "", "")
+	assert.Error(t, err)
+}
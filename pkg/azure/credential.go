@@ -0,0 +1,68 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+
+	"github.com/hashicorp/go-azure-sdk/sdk/auth"
+	"github.com/hashicorp/go-azure-sdk/sdk/environments"
+)
+
+// tokenCredentialAuthorizer is an auth.Authorizer backed by an azidentity credential, it allows the hamilton and
+// go-azure-sdk clients to share the credential (and its token cache) with the azcore based clients
+type tokenCredentialAuthorizer struct {
+	credential azcore.TokenCredential
+	scope      string
+}
+
+var _ auth.Authorizer = &tokenCredentialAuthorizer{}
+
+// newTokenCredentialAuthorizer creates an authorizer that requests tokens for the api from the credential
+func newTokenCredentialAuthorizer(credential azcore.TokenCredential, api environments.Api) (auth.Authorizer, error) {
+	scope, err := environments.Scope(api)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenCredentialAuthorizer{
+		credential: credential,
+		scope:      *scope,
+	}, nil
+}
+
+// Token returns an access token for the api of the authorizer
+func (a *tokenCredentialAuthorizer) Token(ctx context.Context, _ *http.Request) (*oauth2.Token, error) {
+	token, err := a.credential.GetToken(ctx, policy.TokenRequestOptions{
+		Scopes: []string{a.scope},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Token{
+		AccessToken: token.Token,
+		TokenType:   "Bearer",
+		Expiry:      token.ExpiresOn,
+	}, nil
+}
+
+// AuxiliaryTokens returns no tokens, auxiliary tenants are not supported by the credential based authorizer
+func (a *tokenCredentialAuthorizer) AuxiliaryTokens(_ context.Context, _ *http.Request) ([]*oauth2.Token, error) {
+	return nil, nil
+}
+
+// replayCredential hands out a static token, a replayed request is never sent to azure so the token is never checked
+type replayCredential struct{}
+
+var _ azcore.TokenCredential = replayCredential{}
+
+// GetToken returns the static token
+func (replayCredential) GetToken(_ context.Context, _ policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: redacted, ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
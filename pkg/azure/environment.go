@@ -0,0 +1,41 @@
+package azure
+
+import (
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/hashicorp/go-azure-sdk/sdk/environments"
+)
+
+// NewCloudConfiguration converts a go-azure-sdk environment into the equivalent azcore cloud configuration. This
+// allows the azcore based clients (and azidentity credentials) to target the same cloud as everything else.
+func NewCloudConfiguration(env *environments.Environment) (cloud.Configuration, error) {
+	if env == nil {
+		return cloud.Configuration{}, fmt.Errorf("environment is required")
+	}
+
+	endpoint, ok := env.ResourceManager.Endpoint()
+	if !ok {
+		return cloud.Configuration{}, fmt.Errorf("environment %s has no resource manager endpoint", env.Name)
+	}
+
+	audience, err := environments.Resource(env.ResourceManager)
+	if err != nil {
+		return cloud.Configuration{}, err
+	}
+
+	config := cloud.Configuration{
+		Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+			cloud.ResourceManager: {
+				Audience: *audience,
+				Endpoint: *endpoint,
+			},
+		},
+	}
+
+	if env.Authorization != nil {
+		config.ActiveDirectoryAuthorityHost = env.Authorization.LoginEndpoint
+	}
+
+	return config, nil
+}
@@ -0,0 +1,127 @@
+package azure
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/go-azure-sdk/sdk/auth"
+)
+
+// Identity is the identity that is running the nuke, it is resolved from the claims of the access token so that the
+// resources it depends on (its app registration, credentials and role assignments) are never removed.
+type Identity struct {
+	// ObjectID is the object ID of the user, service principal or managed identity
+	ObjectID string
+	// AppID is the application (client) ID the token was issued to
+	AppID string
+	// TenantID is the tenant the token was issued by
+	TenantID string
+}
+
+// String returns a human-readable description of the identity
+func (i *Identity) String() string {
+	if i == nil {
+		return "unknown"
+	}
+
+	return fmt.Sprintf("object-id=%s app-id=%s", i.ObjectID, i.AppID)
+}
+
+// IsObject checks if the object ID belongs to the identity, it is safe to call on a nil identity
+func (i *Identity) IsObject(objectID *string) bool {
+	if i == nil || i.ObjectID == "" || objectID == nil {
+		return false
+	}
+
+	return strings.EqualFold(i.ObjectID, *objectID)
+}
+
+// IsApplication checks if the application (client) ID belongs to the identity, it is safe to call on a nil identity
+func (i *Identity) IsApplication(appID *string) bool {
+	if i == nil || i.AppID == "" || appID == nil {
+		return false
+	}
+
+	return strings.EqualFold(i.AppID, *appID)
+}
+
+// ResolveIdentity requests a token from the authorizer and resolves the identity from its claims, the signature of
+// the token is not verified as the token was just issued to us.
+func ResolveIdentity(ctx context.Context, authorizer auth.Authorizer) (*Identity, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := authorizer.Token(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseIdentity(token.AccessToken)
+}
+
+// tokenClaims are the claims of an access token that azure-nuke relies on
+type tokenClaims struct {
+	ObjectID string `json:"oid"`
+	AppID    string `json:"appid"`
+	AZP      string `json:"azp"`
+	TenantID string `json:"tid"`
+	// Roles are the app roles granted to an application, i.e. the microsoft graph application permissions
+	Roles []string `json:"roles"`
+	// Scope are the space separated delegated permissions granted to a user
+	Scope string `json:"scp"`
+}
+
+// Permissions returns the app roles and delegated permissions of the token
+func (c *tokenClaims) Permissions() []string {
+	return append(append([]string{}, c.Roles...), strings.Fields(c.Scope)...)
+}
+
+// parseTokenClaims decodes the claims of a jwt access token
+func parseTokenClaims(accessToken string) (*tokenClaims, error) {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("access token is not a jwt")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode access token claims: %w", err)
+	}
+
+	claims := &tokenClaims{}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, fmt.Errorf("unable to decode access token claims: %w", err)
+	}
+
+	return claims, nil
+}
+
+// parseIdentity resolves the identity from the claims of a jwt access token, v1 tokens carry the application ID in
+// the appid claim and v2 tokens in the azp claim
+func parseIdentity(accessToken string) (*Identity, error) {
+	claims, err := parseTokenClaims(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.ObjectID == "" {
+		return nil, fmt.Errorf("access token has no oid claim")
+	}
+
+	identity := &Identity{
+		ObjectID: claims.ObjectID,
+		AppID:    claims.AppID,
+		TenantID: claims.TenantID,
+	}
+	if identity.AppID == "" {
+		identity.AppID = claims.AZP
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,254 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hashicorp/go-azure-sdk/resource-manager/resourcegraph/2022-10-01/resources"
+)
+
+const (
+	// DiscoveryARM discovers resources by having every lister enumerate its own resources via resource manager
+	DiscoveryARM = "arm"
+	// DiscoveryResourceGraph pre-fetches an inventory of all resources via azure resource graph, listers use it to
+	// skip their own enumeration in the scopes where none of their resources exist
+	DiscoveryResourceGraph = "resource-graph"
+)
+
+// Discoveries are the supported discovery backends
+var Discoveries = []string{DiscoveryARM, DiscoveryResourceGraph}
+
+const (
+	// inventoryPageSize is the maximum number of rows resource graph returns per page
+	inventoryPageSize = 1000
+	// inventorySubscriptionBatch is the maximum number of subscriptions resource graph accepts per query
+	inventorySubscriptionBatch = 1000
+	// inventoryTimeout is how long building the inventory may take, it is far longer than the timeout used to
+	// discover the tenant as large tenants can have hundreds of thousands of resources
+	inventoryTimeout = 5 * time.Minute
+	// inventoryMaxAge is how long the inventory is used to skip listings, resources created since it was built are
+	// not part of it. Resource graph itself lags behind resource manager, the inventory is never fully up to date.
+	inventoryMaxAge = 10 * time.Minute
+)
+
+const inventoryResourceGroupsQuery = `resourcecontainers
+| where type =~ 'microsoft.resources/subscriptions/resourcegroups'
+| project id, name, type, location, resourceGroup = name, subscriptionId, tags
+| order by id asc`
+
+const inventoryResourcesQuery = `resources
+| project id, name, type, kind, location, resourceGroup, subscriptionId, tags
+| order by id asc`
+
+// InventoryResource is a single resource as it was returned by azure resource graph
+type InventoryResource struct {
+	ID             string            `json:"id"`
+	Name           string            `json:"name"`
+	Type           string            `json:"type"`
+	Kind           string            `json:"kind"`
+	Location       string            `json:"location"`
+	ResourceGroup  string            `json:"resourceGroup"`
+	SubscriptionID string            `json:"subscriptionId"`
+	Tags           map[string]string `json:"tags"`
+}
+
+// Inventory is a snapshot of the resource groups and resources of a set of subscriptions. Resource types and resource
+// group names are compared case-insensitively as resource graph does not preserve the casing used by the providers.
+type Inventory struct {
+	// builtAt is when the inventory was created, see Recent
+	builtAt time.Time
+	// resourceGroups is keyed by subscription ID
+	resourceGroups map[string][]*InventoryResource
+	// groupNames is keyed by subscription ID and lowercase resource group name
+	groupNames map[string]map[string]bool
+	// resources is keyed by subscription ID, lowercase resource group name and lowercase resource type
+	resources map[string]map[string]map[string][]*InventoryResource
+}
+
+// NewInventory creates an empty inventory for the subscriptions, a subscription that is not part of the inventory is
+// treated as unknown and never causes a lister to be skipped.
+func NewInventory(subscriptionIDs []string) *Inventory {
+	inv := &Inventory{
+		builtAt:        time.Now(),
+		resourceGroups: make(map[string][]*InventoryResource),
+		groupNames:     make(map[string]map[string]bool),
+		resources:      make(map[string]map[string]map[string][]*InventoryResource),
+	}
+
+	for _, subscriptionID := range subscriptionIDs {
+		inv.resourceGroups[subscriptionID] = make([]*InventoryResource, 0)
+		inv.groupNames[subscriptionID] = make(map[string]bool)
+		inv.resources[subscriptionID] = make(map[string]map[string][]*InventoryResource)
+	}
+
+	return inv
+}
+
+// AddResourceGroup adds a resource group to the inventory
+func (i *Inventory) AddResourceGroup(rg *InventoryResource) {
+	if _, ok := i.resourceGroups[rg.SubscriptionID]; !ok {
+		return
+	}
+
+	i.resourceGroups[rg.SubscriptionID] = append(i.resourceGroups[rg.SubscriptionID], rg)
+	i.groupNames[rg.SubscriptionID][strings.ToLower(rg.Name)] = true
+}
+
+// AddResource adds a resource to the inventory
+func (i *Inventory) AddResource(r *InventoryResource) {
+	groups, ok := i.resources[r.SubscriptionID]
+	if !ok {
+		return
+	}
+
+	rg := strings.ToLower(r.ResourceGroup)
+	if _, ok := groups[rg]; !ok {
+		groups[rg] = make(map[string][]*InventoryResource)
+	}
+
+	resourceType := strings.ToLower(r.Type)
+	groups[rg][resourceType] = append(groups[rg][resourceType], r)
+}
+
+// HasSubscription checks if the subscription is part of the inventory
+func (i *Inventory) HasSubscription(subscriptionID string) bool {
+	_, ok := i.resources[subscriptionID]
+	return ok
+}
+
+// Recent checks if the inventory is recent enough to skip listings, once it is older the resources are listed as if
+// there was no inventory. This is safe to call on a nil inventory.
+func (i *Inventory) Recent() bool {
+	return i != nil && time.Since(i.builtAt) <= inventoryMaxAge
+}
+
+// ResourceGroups returns the resource groups of the subscription
+func (i *Inventory) ResourceGroups(subscriptionID string) []*InventoryResource {
+	return i.resourceGroups[subscriptionID]
+}
+
+// Resources returns the resources of the given types in the subscription, if the resource group is empty the resources
+// of the entire subscription are returned.
+func (i *Inventory) Resources(subscriptionID, resourceGroup string, resourceTypes ...string) []*InventoryResource {
+	var found []*InventoryResource
+
+	for rg, byType := range i.resources[subscriptionID] {
+		if resourceGroup != "" && rg != strings.ToLower(resourceGroup) {
+			continue
+		}
+
+		for _, resourceType := range resourceTypes {
+			found = append(found, byType[strings.ToLower(resourceType)]...)
+		}
+	}
+
+	return found
+}
+
+// Contains checks if any resource of the given types exists in the subscription or resource group. It always returns
+// true for subscriptions and resource groups that are not part of the inventory, i.e. a resource group created after
+// the inventory was built.
+func (i *Inventory) Contains(subscriptionID, resourceGroup string, resourceTypes ...string) bool {
+	if !i.HasSubscription(subscriptionID) {
+		return true
+	}
+
+	if resourceGroup != "" && !i.groupNames[subscriptionID][strings.ToLower(resourceGroup)] {
+		return true
+	}
+
+	return len(i.Resources(subscriptionID, resourceGroup, resourceTypes...)) > 0
+}
+
+// NewResourceGraphInventory builds the inventory of the subscriptions with a couple of azure resource graph queries,
+// one for the resource groups and one for the resources, instead of listing every resource type per resource group.
+func NewResourceGraphInventory(
+	pctx context.Context, authorizers *Authorizers, subscriptionIDs []string,
+) (*Inventory, error) {
+	ctx, cancel := context.WithTimeout(pctx, inventoryTimeout)
+	defer cancel()
+
+	log := logrus.WithField("handler", "NewResourceGraphInventory")
+
+	client, err := resources.NewResourcesClientWithBaseURI(authorizers.Environment.ResourceManager)
+	if err != nil {
+		return nil, err
+	}
+	client.Client.Authorizer = authorizers.Management
+	authorizers.Pipeline.ConfigureResourceManager(client.Client)
+
+	inv := NewInventory(subscriptionIDs)
+
+	for start := 0; start < len(subscriptionIDs); start += inventorySubscriptionBatch {
+		end := min(start+inventorySubscriptionBatch, len(subscriptionIDs))
+		batch := subscriptionIDs[start:end]
+
+		log.Tracef("querying resource groups of %d subscriptions", len(batch))
+		if err := queryResourceGraph(ctx, client, inventoryResourceGroupsQuery, batch, inv.AddResourceGroup); err != nil {
+			return nil, fmt.Errorf("unable to query resource groups: %w", err)
+		}
+
+		log.Tracef("querying resources of %d subscriptions", len(batch))
+		if err := queryResourceGraph(ctx, client, inventoryResourcesQuery, batch, inv.AddResource); err != nil {
+			return nil, fmt.Errorf("unable to query resources: %w", err)
+		}
+	}
+
+	return inv, nil
+}
+
+// queryResourceGraph runs the query against the subscriptions and hands every row to the add function, following the
+// skip token until all pages have been read
+func queryResourceGraph(
+	ctx context.Context, client *resources.ResourcesClient, query string, subscriptionIDs []string,
+	add func(*InventoryResource),
+) error {
+	format := resources.ResultFormatObjectArray
+
+	var skipToken *string
+	for {
+		res, err := client.Resources(ctx, resources.QueryRequest{
+			Query:         query,
+			Subscriptions: &subscriptionIDs,
+			Options: &resources.QueryRequestOptions{
+				ResultFormat: &format,
+				SkipToken:    skipToken,
+				Top:          ptr.Int64(inventoryPageSize),
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		if res.Model == nil {
+			return fmt.Errorf("empty response from resource graph")
+		}
+
+		// Note: the data is returned as an untyped value, it is round-tripped to decode it into the inventory rows
+		raw, err := json.Marshal(res.Model.Data)
+		if err != nil {
+			return err
+		}
+
+		var rows []*InventoryResource
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return fmt.Errorf("unable to decode resource graph data: %w", err)
+		}
+
+		for _, row := range rows {
+			add(row)
+		}
+
+		if ptr.ToString(res.Model.SkipToken) == "" {
+			return nil
+		}
+
+		skipToken = res.Model.SkipToken
+	}
+}
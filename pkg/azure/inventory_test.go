@@ -0,0 +1,121 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+
+	"github.com/hashicorp/go-azure-sdk/sdk/auth/autorest"
+	"github.com/hashicorp/go-azure-sdk/sdk/environments"
+)
+
+type testAuthorizer struct{}
+
+func (a *testAuthorizer) Token(_ context.Context, _ *http.Request) (*oauth2.Token, error) {
+	return &oauth2.Token{AccessToken: "fake-token", TokenType: "Bearer"}, nil
+}
+
+func (a *testAuthorizer) AuxiliaryTokens(_ context.Context, _ *http.Request) ([]*oauth2.Token, error) {
+	return nil, nil
+}
+
+func TestResourceGraphInventory(t *testing.T) {
+	pages := map[string]string{
+		"resourcecontainers": `{"data":[
+			{"id":"/subscriptions/sub-a/resourceGroups/rg-one","name":"rg-one","location":"eastus","subscriptionId":"sub-a"},
+			{"id":"/subscriptions/sub-a/resourceGroups/rg-two","name":"rg-two","location":"westus","subscriptionId":"sub-a"}
+		]}`,
+		"resources": `{"$skipToken":"next","data":[
+			{"id":"/subscriptions/sub-a/resourceGroups/rg-one/providers/Microsoft.Compute/disks/disk",
+			 "name":"disk","type":"microsoft.compute/disks","resourceGroup":"rg-one","subscriptionId":"sub-a"}
+		]}`,
+		"resources/next": `{"data":[
+			{"id":"/subscriptions/sub-a/resourceGroups/RG-TWO/providers/Microsoft.Network/virtualNetworks/vnet",
+			 "name":"vnet","type":"microsoft.network/virtualnetworks","resourceGroup":"RG-TWO","subscriptionId":"sub-a"}
+		]}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query   string `json:"query"`
+			Options struct {
+				SkipToken string `json:"$skipToken"`
+			} `json:"options"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		key := "resources"
+		if strings.HasPrefix(req.Query, "resourcecontainers") {
+			key = "resourcecontainers"
+		}
+		if req.Options.SkipToken != "" {
+			key += "/" + req.Options.SkipToken
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(pages[key]))
+	}))
+	defer server.Close()
+
+	env := environments.AzurePublic()
+	env.ResourceManager = environments.ResourceManagerAPI(server.URL)
+
+	inv, err := NewResourceGraphInventory(context.TODO(), &Authorizers{
+		Management:  autorest.AutorestAuthorizer(&testAuthorizer{}),
+		Environment: env,
+	}, []string{"sub-a"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, inv.ResourceGroups("sub-a"), 2)
+	assert.Len(t, inv.Resources("sub-a", "", "microsoft.compute/disks", "microsoft.network/virtualnetworks"), 2)
+
+	assert.True(t, inv.Contains("sub-a", "rg-one", "Microsoft.Compute/disks"))
+	assert.False(t, inv.Contains("sub-a", "rg-one", "microsoft.network/virtualnetworks"))
+	assert.True(t, inv.Contains("sub-a", "rg-two", "microsoft.network/virtualnetworks"))
+	assert.True(t, inv.Contains("sub-a", "", "microsoft.network/virtualnetworks"))
+	assert.False(t, inv.Contains("sub-a", "", "microsoft.keyvault/vaults"))
+
+	// Note: subscriptions that are not part of the inventory are unknown and must never be skipped
+	assert.True(t, inv.Contains("sub-b", "rg-one", "microsoft.compute/disks"))
+
+	// Note: neither are resource groups that were created after the inventory was built
+	assert.True(t, inv.Contains("sub-a", "rg-new", "microsoft.network/virtualnetworks"))
+
+	opts := &ListerOpts{SubscriptionID: "sub-a", ResourceGroup: "rg-one"}
+	assert.False(t, opts.SkipByInventory("microsoft.network/virtualnetworks"))
+
+	opts.Inventory = inv
+	assert.True(t, opts.SkipByInventory("microsoft.network/virtualnetworks"))
+	assert.False(t, opts.SkipByInventory("microsoft.compute/disks"))
+
+	opts.ResourceGroup = "rg-new"
+	assert.False(t, opts.SkipByInventory("microsoft.network/virtualnetworks"))
+}
+
+func TestSkipByInventoryOnlyWhileRecent(t *testing.T) {
+	inv := NewInventory([]string{"sub-a"})
+	inv.AddResourceGroup(&InventoryResource{Name: "rg", SubscriptionID: "sub-a"})
+
+	opts := &ListerOpts{SubscriptionID: "sub-a", ResourceGroup: "rg", Inventory: inv}
+	assert.True(t, inv.Recent())
+	assert.True(t, opts.SkipByInventory("microsoft.compute/disks"))
+
+	inv.builtAt = time.Now().Add(-inventoryMaxAge - time.Minute)
+	assert.False(t, inv.Recent())
+	assert.False(t, opts.SkipByInventory("microsoft.compute/disks"))
+
+	var missing *Inventory
+	assert.False(t, missing.Recent())
+}
@@ -0,0 +1,127 @@
+package azure
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/ekristen/libnuke/pkg/resource"
+)
+
+// ListFunc lists every resource of a resource type in a subscription
+type ListFunc func(ctx context.Context) ([]resource.Resource, error)
+
+type resourceGroupGetter interface {
+	GetResourceGroup() string
+}
+
+// Listings share the subscription wide listing of the resource group scoped resource types between the scanners of
+// the resource groups. Every resource of a type in the subscription is listed once, and the lister of each resource
+// group picks the resources of its resource group from it, instead of every resource group being listed on its own.
+type Listings struct {
+	// resourceGroups are the resource groups that are scanned, keyed by subscription/resource group in lower case,
+	// resources in other resource groups are dropped from the listings. Every resource group is kept if it is nil.
+	resourceGroups map[string]bool
+
+	lock    sync.Mutex
+	entries map[string]*listing
+}
+
+// listing is the subscription wide listing of a resource type, grouped by resource group
+type listing struct {
+	lock   sync.Mutex
+	listed bool
+	groups map[string][]resource.Resource
+	taken  map[string]bool
+}
+
+// NewListings creates the listings for the resource groups that are scanned, keyed by subscription ID
+func NewListings(resourceGroups map[string][]string) *Listings {
+	l := &Listings{
+		entries: make(map[string]*listing),
+	}
+
+	if resourceGroups != nil {
+		l.resourceGroups = make(map[string]bool)
+		for subscriptionID, names := range resourceGroups {
+			for _, name := range names {
+				l.resourceGroups[listingKey(subscriptionID, name)] = true
+			}
+		}
+	}
+
+	return l
+}
+
+// Includes checks if the resource group is scanned, the listers use it to skip the child resources (i.e. the backup
+// policies of a vault) of the resources in resource groups that are not scanned
+func (l *Listings) Includes(subscriptionID, resourceGroup string) bool {
+	if l == nil || l.resourceGroups == nil {
+		return true
+	}
+
+	return l.resourceGroups[listingKey(subscriptionID, resourceGroup)]
+}
+
+// ResourceGroup returns the resources of the resource type in the resource group. The subscription is listed with
+// the list function the first time any of its resource groups asks for the resource type, the resources of every
+// other resource group are then kept until their resource group asks for them. A resource group that asks for the
+// same resource type again (i.e. to check if its resources have been removed) results in a new listing, so that no
+// resource group is ever handed a stale listing twice.
+func (l *Listings) ResourceGroup(
+	ctx context.Context, subscriptionID, resourceGroup, resourceType string, list ListFunc,
+) ([]resource.Resource, error) {
+	entry := l.entry(listingKey(subscriptionID, resourceType))
+
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	key := strings.ToLower(resourceGroup)
+
+	if !entry.listed || entry.taken[key] {
+		found, err := list(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		entry.listed = true
+		entry.groups = make(map[string][]resource.Resource)
+		entry.taken = make(map[string]bool)
+
+		for _, r := range found {
+			group := ""
+			if getter, ok := r.(resourceGroupGetter); ok {
+				group = getter.GetResourceGroup()
+			}
+
+			if !l.Includes(subscriptionID, group) {
+				continue
+			}
+
+			entry.groups[strings.ToLower(group)] = append(entry.groups[strings.ToLower(group)], r)
+		}
+	}
+
+	resources := entry.groups[key]
+	delete(entry.groups, key)
+	entry.taken[key] = true
+
+	return resources, nil
+}
+
+func (l *Listings) entry(key string) *listing {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	entry, ok := l.entries[key]
+	if !ok {
+		entry = &listing{}
+		l.entries[key] = entry
+	}
+
+	return entry
+}
+
+func listingKey(parts ...string) string {
+	return strings.ToLower(strings.Join(parts, "/"))
+}
@@ -0,0 +1,121 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/resource"
+)
+
+type listingsTestResource struct {
+	name          string
+	resourceGroup string
+}
+
+func (r *listingsTestResource) Remove(_ context.Context) error {
+	return nil
+}
+
+func (r *listingsTestResource) GetResourceGroup() string {
+	return r.resourceGroup
+}
+
+func TestListings(t *testing.T) {
+	ctx := context.Background()
+	listings := NewListings(map[string][]string{"sub-a": {"rg-one", "rg-two"}})
+
+	calls := 0
+	list := func(_ context.Context) ([]resource.Resource, error) {
+		calls++
+		return []resource.Resource{
+			&listingsTestResource{name: "one", resourceGroup: "RG-ONE"},
+			&listingsTestResource{name: "two", resourceGroup: "rg-two"},
+			&listingsTestResource{name: "other", resourceGroup: "rg-other"},
+		}, nil
+	}
+
+	names := func(resources []resource.Resource) []string {
+		found := make([]string, 0, len(resources))
+		for _, r := range resources {
+			found = append(found, r.(*listingsTestResource).name)
+		}
+		return found
+	}
+
+	found, err := listings.ResourceGroup(ctx, "sub-a", "rg-one", "Disk", list)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"one"}, names(found))
+
+	found, err = listings.ResourceGroup(ctx, "SUB-A", "rg-two", "Disk", list)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"two"}, names(found))
+	assert.Equal(t, 1, calls, "the subscription is listed once for every resource group")
+
+	found, err = listings.ResourceGroup(ctx, "sub-a", "rg-empty", "Disk", list)
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+	assert.Equal(t, 1, calls)
+
+	found, err = listings.ResourceGroup(ctx, "sub-a", "rg-one", "Disk", list)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"one"}, names(found))
+	assert.Equal(t, 2, calls, "a resource group that lists again gets a new listing")
+
+	_, err = listings.ResourceGroup(ctx, "sub-a", "rg-one", "Snapshot", list)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls, "every resource type has its own listing")
+
+	failing := func(_ context.Context) ([]resource.Resource, error) {
+		return nil, errors.New("throttled")
+	}
+
+	_, err = listings.ResourceGroup(ctx, "sub-a", "rg-one", "VirtualMachine", failing)
+	assert.Error(t, err)
+
+	found, err = listings.ResourceGroup(ctx, "sub-a", "rg-one", "VirtualMachine", list)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"one"}, names(found), "a failed listing is not kept")
+
+	assert.True(t, listings.Includes("sub-a", "RG-TWO"))
+	assert.False(t, listings.Includes("sub-a", "rg-other"))
+	assert.False(t, listings.Includes("sub-b", "rg-one"))
+}
+
+func TestListerOptsListByResourceGroup(t *testing.T) {
+	list := func(_ context.Context) ([]resource.Resource, error) {
+		return []resource.Resource{
+			&listingsTestResource{name: "one", resourceGroup: "rg-one"},
+			&listingsTestResource{name: "two", resourceGroup: "rg-two"},
+		}, nil
+	}
+
+	// Note: without listings only the resource group being listed is kept
+	opts := &ListerOpts{SubscriptionID: "sub-a", ResourceGroup: "rg-two"}
+
+	found, err := opts.ListByResourceGroup(context.Background(), "Disk", list)
+	assert.NoError(t, err)
+	assert.Len(t, found, 1)
+	assert.Equal(t, "two", found[0].(*listingsTestResource).name)
+	assert.True(t, opts.ListsResourceGroup("RG-TWO"))
+	assert.False(t, opts.ListsResourceGroup("rg-one"))
+}
+
+func TestListerOptsResourceGroupFromID(t *testing.T) {
+	index := NewResourceGroupIndex()
+	index.Add("sub-a", &ResourceGroupDetails{Name: "My-RG"})
+
+	opts := &ListerOpts{SubscriptionID: "sub-a", ResourceGroupDetails: index}
+
+	id := "/subscriptions/sub-a/resourceGroups/MY-RG/providers/Microsoft.Compute/virtualMachines/vm"
+	assert.Equal(t, "My-RG", *opts.ResourceGroupFromID(&id))
+
+	id = "/subscriptions/sub-a/resourceGroups/other/providers/Microsoft.Compute/virtualMachines/vm"
+	assert.Equal(t, "other", *opts.ResourceGroupFromID(&id))
+
+	id = "/subscriptions/sub-a/providers/Microsoft.Compute/sshPublicKeys/key"
+	assert.Nil(t, opts.ResourceGroupFromID(&id))
+	assert.Nil(t, opts.ResourceGroupFromID(nil))
+}
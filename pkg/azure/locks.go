@@ -0,0 +1,169 @@
+package azure
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Lock is a management lock, while it exists the resources in its scope cannot be removed
+type Lock struct {
+	ID    string
+	Name  string
+	Level string
+	// Scope is the ID of the subscription, resource group or resource the lock is applied to
+	Scope string
+	// Kept is set when the lock is not going to be removed, either because it is filtered or because the
+	// ManagementLock resource type does not run, the resources it blocks are then kept as well
+	Kept bool
+	// Failed is set when the removal of the lock failed, the resources it blocks are then failed instead of held
+	Failed bool
+}
+
+// LockTarget describes a resource that management locks can block the removal of
+type LockTarget struct {
+	// ID is the resource ID, when it is known the locks on the resource, on any of its parents and on any of its
+	// children are found. A resource cannot be removed while anything below it is locked (i.e. a resource group).
+	ID string
+
+	// SubscriptionID, ResourceGroup and Name are used when the resource ID is not known, the locks on the
+	// subscription and resource group are found and resource level locks are matched by the name of the resource
+	SubscriptionID string
+	ResourceGroup  string
+	Name           string
+}
+
+// Locks are the management locks of a tenant, they are discovered before scanning so that resources can be checked
+// against them as they are enqueued and removed. It is safe for concurrent use.
+type Locks struct {
+	mu    sync.RWMutex
+	locks map[string]*Lock
+}
+
+// NewLocks returns an empty set of locks
+func NewLocks() *Locks {
+	return &Locks{
+		locks: make(map[string]*Lock),
+	}
+}
+
+// Add adds a discovered lock
+func (l *Locks) Add(lock *Lock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.locks[strings.ToLower(lock.ID)] = lock
+}
+
+// Removed is called once a lock has been removed, it no longer blocks any resource
+func (l *Locks) Removed(id string) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.locks, strings.ToLower(id))
+}
+
+// Failed is called when the removal of a lock failed, the resources it blocks are not held any longer as it might
+// never be removed. A later successful removal still calls Removed.
+func (l *Locks) Failed(id string) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if lock, ok := l.locks[strings.ToLower(id)]; ok {
+		lock.Failed = true
+	}
+}
+
+// Len returns the number of locks
+func (l *Locks) Len() int {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return len(l.locks)
+}
+
+// Blocking returns the locks that block the removal of the target, kept locks are returned first. It is safe to call
+// on nil locks.
+func (l *Locks) Blocking(target LockTarget) []*Lock {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var blocking []*Lock
+	for _, lock := range l.locks {
+		if target.blockedBy(lock.Scope) {
+			blocking = append(blocking, lock)
+		}
+	}
+
+	sort.Slice(blocking, func(i, j int) bool {
+		if blocking[i].Kept != blocking[j].Kept {
+			return blocking[i].Kept
+		}
+
+		return blocking[i].ID < blocking[j].ID
+	})
+
+	return blocking
+}
+
+// blockedBy checks if a lock applied to the scope blocks the removal of the target
+func (t LockTarget) blockedBy(scope string) bool {
+	scope = strings.ToLower(strings.TrimSuffix(scope, "/"))
+	if scope == "" {
+		return false
+	}
+
+	if t.ID != "" {
+		id := strings.ToLower(strings.TrimSuffix(t.ID, "/"))
+		return id == scope || strings.HasPrefix(id, scope+"/") || strings.HasPrefix(scope, id+"/")
+	}
+
+	if t.SubscriptionID == "" {
+		return false
+	}
+
+	parent := "/subscriptions/" + strings.ToLower(t.SubscriptionID)
+	if t.ResourceGroup != "" {
+		parent += "/resourcegroups/" + strings.ToLower(t.ResourceGroup)
+	}
+
+	if parent == scope || strings.HasPrefix(parent, scope+"/") {
+		return true
+	}
+
+	// Note: resource level locks are scoped to `<resource group>/providers/<namespace>/<type>/<name>`, without the ID
+	// of the resource they are matched by the name of the top level resource
+	if t.ResourceGroup == "" || t.Name == "" || !strings.HasPrefix(scope, parent+"/providers/") {
+		return false
+	}
+
+	parts := strings.Split(strings.TrimPrefix(scope, parent+"/providers/"), "/")
+
+	return len(parts) >= 3 && parts[2] == strings.ToLower(t.Name)
+}
+
+// LockScope returns the scope a lock is applied to from the ID of the lock
+func LockScope(lockID string) string {
+	index := strings.LastIndex(strings.ToLower(lockID), "/providers/microsoft.authorization/locks/")
+	if index < 0 {
+		return ""
+	}
+
+	return lockID[:index]
+}
@@ -0,0 +1,102 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocksBlocking(t *testing.T) {
+	locks := NewLocks()
+	locks.Add(&Lock{
+		ID:    "/subscriptions/sub-a/providers/Microsoft.Authorization/locks/subscription",
+		Name:  "subscription",
+		Scope: "/subscriptions/sub-a",
+	})
+	locks.Add(&Lock{
+		ID:    "/subscriptions/sub-b/resourceGroups/rg-one/providers/Microsoft.Authorization/locks/group",
+		Name:  "group",
+		Scope: "/subscriptions/sub-b/resourceGroups/rg-one",
+		Kept:  true,
+	})
+	locks.Add(&Lock{
+		ID: "/subscriptions/sub-b/resourceGroups/rg-two/providers/Microsoft.Network/virtualNetworks/vnet" +
+			"/providers/Microsoft.Authorization/locks/vnet",
+		Name:  "vnet",
+		Scope: "/subscriptions/sub-b/resourceGroups/rg-two/providers/Microsoft.Network/virtualNetworks/vnet",
+	})
+
+	cases := []struct {
+		name   string
+		target LockTarget
+		want   []string
+	}{
+		{
+			name:   "subscription lock by id",
+			target: LockTarget{ID: "/subscriptions/SUB-A/resourceGroups/rg/providers/Microsoft.Compute/disks/disk"},
+			want:   []string{"subscription"},
+		},
+		{
+			name:   "subscription lock without id",
+			target: LockTarget{SubscriptionID: "sub-a", Name: "policy"},
+			want:   []string{"subscription"},
+		},
+		{
+			name:   "resource group lock without id",
+			target: LockTarget{SubscriptionID: "sub-b", ResourceGroup: "rg-one", Name: "disk"},
+			want:   []string{"group"},
+		},
+		{
+			name:   "resource lock on child resource",
+			target: LockTarget{ID: "/subscriptions/sub-b/resourceGroups/rg-two/providers/Microsoft.Network/virtualNetworks/vnet/subnets/default"},
+			want:   []string{"vnet"},
+		},
+		{
+			name:   "resource lock on parent resource group",
+			target: LockTarget{ID: "/subscriptions/sub-b/resourceGroups/rg-two"},
+			want:   []string{"vnet"},
+		},
+		{
+			name:   "resource lock by name",
+			target: LockTarget{SubscriptionID: "sub-b", ResourceGroup: "rg-two", Name: "vnet"},
+			want:   []string{"vnet"},
+		},
+		{
+			name:   "similar resource group name",
+			target: LockTarget{ID: "/subscriptions/sub-b/resourceGroups/rg-one-more"},
+		},
+		{
+			name:   "other resource",
+			target: LockTarget{SubscriptionID: "sub-b", ResourceGroup: "rg-two", Name: "disk"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var names []string
+			for _, lock := range locks.Blocking(tc.target) {
+				names = append(names, lock.Name)
+			}
+
+			assert.Equal(t, tc.want, names)
+		})
+	}
+
+	locks.Failed("/subscriptions/SUB-A/providers/Microsoft.Authorization/locks/subscription")
+	assert.True(t, locks.Blocking(LockTarget{SubscriptionID: "sub-a"})[0].Failed)
+
+	locks.Removed("/subscriptions/sub-a/providers/Microsoft.Authorization/locks/subscription")
+	assert.Empty(t, locks.Blocking(LockTarget{SubscriptionID: "sub-a"}))
+	assert.Equal(t, 2, locks.Len())
+
+	var nilLocks *Locks
+	assert.Empty(t, nilLocks.Blocking(LockTarget{SubscriptionID: "sub-a"}))
+}
+
+func TestLockScope(t *testing.T) {
+	assert.Equal(t, "/subscriptions/sub-a/resourceGroups/rg",
+		LockScope("/subscriptions/sub-a/resourceGroups/rg/providers/Microsoft.Authorization/locks/lock"))
+	assert.Equal(t, "/subscriptions/sub-a",
+		LockScope("/subscriptions/sub-a/providers/microsoft.authorization/locks/lock"))
+	assert.Equal(t, "", LockScope("/subscriptions/sub-a/resourceGroups/rg"))
+}
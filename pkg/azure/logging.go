@@ -0,0 +1,57 @@
+package azure
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ScopeTenant is the scope of the resources that belong to the tenant itself (i.e. Entra ID objects)
+	ScopeTenant = "tenant"
+	// ScopeManagementGroup is the scope of the resources that belong to a management group
+	ScopeManagementGroup = "management-group"
+	// ScopeSubscription is the scope of the resources that belong to a subscription
+	ScopeSubscription = "subscription"
+	// ScopeResourceGroup is the scope of the resources that belong to a resource group
+	ScopeResourceGroup = "resource-group"
+)
+
+// Scope returns the name of the scope being listed, as it is logged in the scope field
+func (o *ListerOpts) Scope() string {
+	switch {
+	case o.ResourceGroup != "":
+		return ScopeResourceGroup
+	case o.SubscriptionID != "":
+		return ScopeSubscription
+	case o.ManagementGroupID != "":
+		return ScopeManagementGroup
+	default:
+		return ScopeTenant
+	}
+}
+
+// Logger returns the logger of the lister of the resource type, its entries have the resource type and the scope
+// being listed as fields. The keys of the fields are documented in the logging section of docs/cli-options.md.
+func (o *ListerOpts) Logger(resourceType string) *logrus.Entry {
+	log := logrus.
+		WithField("component", "lister").
+		WithField("resource_type", resourceType).
+		WithField("scope", o.Scope())
+
+	if o.TenantID != "" {
+		log = log.WithField("tenant_id", o.TenantID)
+	}
+
+	if o.ManagementGroupID != "" {
+		log = log.WithField("management_group", o.ManagementGroupID)
+	}
+
+	if o.SubscriptionID != "" {
+		log = log.WithField("subscription_id", o.SubscriptionID)
+	}
+
+	if o.ResourceGroup != "" {
+		log = log.WithField("resource_group", o.ResourceGroup)
+	}
+
+	return log
+}
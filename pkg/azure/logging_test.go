@@ -0,0 +1,54 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListerOptsLogger(t *testing.T) {
+	cases := []struct {
+		name   string
+		opts   *ListerOpts
+		fields logrus.Fields
+	}{
+		{
+			name: "tenant",
+			opts: &ListerOpts{TenantID: "tenant-a"},
+			fields: logrus.Fields{
+				"component": "lister", "resource_type": "Test", "scope": ScopeTenant, "tenant_id": "tenant-a",
+			},
+		},
+		{
+			name: "management-group",
+			opts: &ListerOpts{TenantID: "tenant-a", ManagementGroupID: "mg-a"},
+			fields: logrus.Fields{
+				"component": "lister", "resource_type": "Test", "scope": ScopeManagementGroup, "tenant_id": "tenant-a",
+				"management_group": "mg-a",
+			},
+		},
+		{
+			name: "subscription",
+			opts: &ListerOpts{TenantID: "tenant-a", SubscriptionID: "sub-a"},
+			fields: logrus.Fields{
+				"component": "lister", "resource_type": "Test", "scope": ScopeSubscription, "tenant_id": "tenant-a",
+				"subscription_id": "sub-a",
+			},
+		},
+		{
+			name: "resource-group",
+			opts: &ListerOpts{TenantID: "tenant-a", SubscriptionID: "sub-a", ResourceGroup: "rg-a"},
+			fields: logrus.Fields{
+				"component": "lister", "resource_type": "Test", "scope": ScopeResourceGroup, "tenant_id": "tenant-a",
+				"subscription_id": "sub-a", "resource_group": "rg-a",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.fields, tc.opts.Logger("Test").Data)
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gotidy/ptr"
+
+	"github.com/hashicorp/go-azure-helpers/resourcemanager/commonids"
+	"github.com/hashicorp/go-azure-sdk/resource-manager/managementgroups/2021-04-01/managementgroups"
+)
+
+// ManagementGroup is a single node of the management group hierarchy of a tenant.
+type ManagementGroup struct {
+	// ID is the full resource ID of the management group (i.e. /providers/Microsoft.Management/managementGroups/name)
+	ID string
+	// Name is the name of the management group, this is what is used in the resource ID. For the tenant root group
+	// this is the same as the tenant ID.
+	Name        string
+	DisplayName string
+	// Parent is the name of the parent management group, this is empty for the tenant root group.
+	Parent string
+	// Children are the names of the direct child management groups.
+	Children []string
+	// SubscriptionIDs are the IDs of the subscriptions that are direct children of the management group.
+	SubscriptionIDs []string
+}
+
+// ManagementGroups is the flattened management group hierarchy of a tenant, keyed by management group name.
+type ManagementGroups map[string]*ManagementGroup
+
+// Names returns the sorted names of all the management groups.
+func (m ManagementGroups) Names() []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Find looks up a management group by name or, failing that, by display name. Display names are not guaranteed
+// to be unique, so an error is returned if more than one management group has the same display name.
+func (m ManagementGroups) Find(name string) (*ManagementGroup, error) {
+	if mg, ok := m[name]; ok {
+		return mg, nil
+	}
+
+	var found *ManagementGroup
+	for _, mgName := range m.Names() {
+		mg := m[mgName]
+		if !strings.EqualFold(mg.DisplayName, name) {
+			continue
+		}
+
+		if found != nil {
+			return nil, fmt.Errorf("management group display name is ambiguous: %s", name)
+		}
+
+		found = mg
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("management group not found: %s", name)
+	}
+
+	return found, nil
+}
+
+// Select returns the requested management groups (by name or display name) along with all of their descendants.
+func (m ManagementGroups) Select(names []string) (ManagementGroups, error) {
+	selected := ManagementGroups{}
+
+	var walk func(mg *ManagementGroup)
+	walk = func(mg *ManagementGroup) {
+		selected[mg.Name] = mg
+		for _, child := range mg.Children {
+			if c, ok := m[child]; ok {
+				walk(c)
+			}
+		}
+	}
+
+	for _, name := range names {
+		mg, err := m.Find(name)
+		if err != nil {
+			return nil, err
+		}
+
+		walk(mg)
+	}
+
+	return selected, nil
+}
+
+// SubscriptionIDs returns the IDs of all subscriptions that are direct children of any of the management groups.
+func (m ManagementGroups) SubscriptionIDs() []string {
+	var subscriptionIDs []string
+	for _, name := range m.Names() {
+		for _, subscriptionID := range m[name].SubscriptionIDs {
+			if !slices.Contains(subscriptionIDs, subscriptionID) {
+				subscriptionIDs = append(subscriptionIDs, subscriptionID)
+			}
+		}
+	}
+
+	return subscriptionIDs
+}
+
+// ListManagementGroups discovers the entire management group hierarchy of a tenant by expanding the tenant root
+// group recursively. The root group always has the same name as the tenant ID.
+func ListManagementGroups(ctx context.Context, authorizers *Authorizers, tenantID string) (ManagementGroups, error) {
+	client, err := managementgroups.NewManagementGroupsClientWithBaseURI(authorizers.Environment.ResourceManager)
+	if err != nil {
+		return nil, err
+	}
+	client.Client.Authorizer = authorizers.Management
+	authorizers.Pipeline.ConfigureResourceManager(client.Client)
+
+	expand := managementgroups.ManagementGroupExpandTypeChildren
+	res, err := client.Get(ctx, commonids.NewManagementGroupID(tenantID), managementgroups.GetOperationOptions{
+		Expand:  &expand,
+		Recurse: ptr.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Model == nil {
+		return nil, fmt.Errorf("unable to get tenant root management group")
+	}
+
+	root := &ManagementGroup{
+		ID:   ptr.ToString(res.Model.Id),
+		Name: ptr.ToString(res.Model.Name),
+	}
+
+	groups := ManagementGroups{
+		root.Name: root,
+	}
+
+	if res.Model.Properties == nil {
+		return groups, nil
+	}
+
+	root.DisplayName = ptr.ToString(res.Model.Properties.DisplayName)
+
+	if res.Model.Properties.Children != nil {
+		addManagementGroupChildren(groups, root, *res.Model.Properties.Children)
+	}
+
+	return groups, nil
+}
+
+func addManagementGroupChildren(groups ManagementGroups, parent *ManagementGroup, children []managementgroups.ManagementGroupChildInfo) {
+	for _, child := range children {
+		if child.Type == nil {
+			continue
+		}
+
+		switch *child.Type {
+		case managementgroups.ManagementGroupChildTypeSubscriptions:
+			parent.SubscriptionIDs = append(parent.SubscriptionIDs, ptr.ToString(child.Name))
+		case managementgroups.ManagementGroupChildTypeMicrosoftPointManagementManagementGroups:
+			mg := &ManagementGroup{
+				ID:          ptr.ToString(child.Id),
+				Name:        ptr.ToString(child.Name),
+				DisplayName: ptr.ToString(child.DisplayName),
+				Parent:      parent.Name,
+			}
+
+			groups[mg.Name] = mg
+			parent.Children = append(parent.Children, mg.Name)
+
+			if child.Children != nil {
+				addManagementGroupChildren(groups, mg, *child.Children)
+			}
+		}
+	}
+}
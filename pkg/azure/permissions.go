@@ -0,0 +1,118 @@
+package azure
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Permissions are the permissions a resource type needs to be listed and removed. Tenant scoped resource types need
+// microsoft graph permissions, every other resource type needs azure rbac actions.
+type Permissions struct {
+	// GraphList are the microsoft graph permissions that allow listing, any one of them is sufficient
+	GraphList []string
+	// GraphDelete are the microsoft graph permissions that allow removal, any one of them is sufficient
+	GraphDelete []string
+
+	// List are the azure rbac actions required for listing, all of them are required
+	List []string
+	// Delete are the azure rbac actions required for removal, all of them are required
+	Delete []string
+}
+
+var (
+	permissionsLock sync.RWMutex
+	permissions     = make(map[string]Permissions)
+)
+
+// RegisterPermissions registers the permissions a resource type needs, they are used by the preflight check
+func RegisterPermissions(resourceType string, p Permissions) {
+	permissionsLock.Lock()
+	defer permissionsLock.Unlock()
+
+	permissions[resourceType] = p
+}
+
+// GetPermissions returns the permissions registered for the resource type
+func GetPermissions(resourceType string) (Permissions, bool) {
+	permissionsLock.RLock()
+	defer permissionsLock.RUnlock()
+
+	p, ok := permissions[resourceType]
+	return p, ok
+}
+
+// RolePermission is the set of actions granted by a single role definition
+type RolePermission struct {
+	Actions    []string
+	NotActions []string
+}
+
+// Allows checks if the role grants the action, the actions of a role can contain wildcards (i.e. `*/read` or
+// `Microsoft.Compute/*`) and an action excluded by the not actions of the role is never granted by that role.
+func (p RolePermission) Allows(action string) bool {
+	allowed := false
+	for _, pattern := range p.Actions {
+		if matchAction(pattern, action) {
+			allowed = true
+			break
+		}
+	}
+
+	if !allowed {
+		return false
+	}
+
+	for _, pattern := range p.NotActions {
+		if matchAction(pattern, action) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// EffectivePermissions are the permissions granted by all roles assigned to the caller at a scope
+type EffectivePermissions []RolePermission
+
+// Allows checks if any of the roles grants the action
+func (e EffectivePermissions) Allows(action string) bool {
+	for _, p := range e {
+		if p.Allows(action) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Missing returns the actions that are not granted by any of the roles
+func (e EffectivePermissions) Missing(actions []string) []string {
+	var missing []string
+	for _, action := range actions {
+		if !e.Allows(action) {
+			missing = append(missing, action)
+		}
+	}
+
+	return missing
+}
+
+// matchAction matches an action against an action pattern of a role definition, case-insensitively
+func matchAction(pattern, action string) bool {
+	if !strings.Contains(pattern, "*") {
+		return strings.EqualFold(pattern, action)
+	}
+
+	parts := strings.Split(pattern, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+
+	re, err := regexp.Compile("(?i)^" + strings.Join(parts, ".*") + "$")
+	if err != nil {
+		return false
+	}
+
+	return re.MatchString(action)
+}
@@ -0,0 +1,546 @@
+package azure
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Azure/go-autorest/autorest"
+
+	"github.com/hashicorp/go-azure-sdk/sdk/client"
+	"github.com/hashicorp/go-azure-sdk/sdk/client/resourcemanager"
+
+	"github.com/manicminer/hamilton/msgraph"
+)
+
+const (
+	// DefaultRequestRate is the number of requests per second allowed per subscription (or per host for requests that
+	// are not scoped to a subscription), resource manager refills its own buckets at 25 requests per second
+	DefaultRequestRate = 20
+	// DefaultRequestBurst is the number of requests that can be sent at once before the request rate applies
+	DefaultRequestBurst = 100
+	// DefaultMaxRetries is how many times a throttled request is retried before the throttled response is returned
+	DefaultMaxRetries = 5
+	// DefaultMaxRetryAfter caps how long a single retry waits, regardless of what the server asked for
+	DefaultMaxRetryAfter = 60 * time.Second
+
+	// rateLimitLowWatermark is the remaining number of requests (as reported by the x-ms-ratelimit-remaining-*
+	// headers) below which the request rate of the subscription is reduced until the bucket has recovered
+	rateLimitLowWatermark = 10
+	// rateLimitReducedFactor is the factor the request rate is reduced by when throttling is near or happening
+	rateLimitReducedFactor = 0.1
+)
+
+type requestStartKey struct{}
+
+// rateLimitRemainingPrefix is the canonical prefix of the headers resource manager uses to report how many requests
+// are left in the bucket (i.e. x-ms-ratelimit-remaining-subscription-reads)
+var rateLimitRemainingPrefix = http.CanonicalHeaderKey("x-ms-ratelimit-remaining-")
+
+// Pipeline is the http layer shared by all the clients (autorest, go-azure-sdk, hamilton and azcore). It limits the
+// request rate per subscription with a token bucket, slows down when resource manager reports that the bucket is
+// almost empty, and retries throttled requests after the delay requested via the Retry-After header.
+type Pipeline struct {
+	transport http.RoundTripper
+
+	rate          float64
+	burst         float64
+	maxRetries    int
+	maxRetryAfter time.Duration
+
+	lock    sync.Mutex
+	buckets map[string]*tokenBucket
+
+	recorder *Recorder
+	observer RequestObserver
+
+	requests        atomic.Int64
+	throttled       atomic.Int64
+	retries         atomic.Int64
+	exhausted       atomic.Int64
+	rateLimitWait   atomic.Int64
+	retryWait       atomic.Int64
+	lowestRemaining atomic.Int64
+}
+
+// PipelineMetrics is a snapshot of the throttling metrics of a pipeline
+type PipelineMetrics struct {
+	// Requests is the number of requests sent, including retries
+	Requests int64
+	// Throttled is the number of responses that asked the client to back off (429, or 503 with a Retry-After)
+	Throttled int64
+	// Retries is the number of throttled requests that were retried
+	Retries int64
+	// Exhausted is the number of throttled responses that were returned as all retries had been used
+	Exhausted int64
+	// RateLimitWait is the total time requests waited on the token buckets
+	RateLimitWait time.Duration
+	// RetryWait is the total time requests waited before being retried
+	RetryWait time.Duration
+	// LowestRemaining is the lowest remaining number of requests reported by resource manager, -1 if never reported
+	LowestRemaining int64
+}
+
+// RequestObserver is notified of every request sent by a pipeline, including the retries of throttled requests, i.e.
+// to collect metrics. The status is 0 if no response was received.
+type RequestObserver interface {
+	ObserveRequest(req *http.Request, status int, duration time.Duration, throttled bool)
+}
+
+// NewPipeline creates a pipeline on top of the transport, http.DefaultTransport is used if the transport is nil
+func NewPipeline(transport http.RoundTripper) *Pipeline {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	p := &Pipeline{
+		transport:     transport,
+		rate:          DefaultRequestRate,
+		burst:         DefaultRequestBurst,
+		maxRetries:    DefaultMaxRetries,
+		maxRetryAfter: DefaultMaxRetryAfter,
+		buckets:       make(map[string]*tokenBucket),
+	}
+	p.lowestRemaining.Store(-1)
+
+	return p
+}
+
+// SetRateLimit changes the request rate and burst used for buckets created from now on
+func (p *Pipeline) SetRateLimit(rate float64, burst int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.rate = rate
+	p.burst = float64(burst)
+}
+
+// SetRecorder records the exchanges of every client of the pipeline with the recorder
+func (p *Pipeline) SetRecorder(recorder *Recorder) {
+	p.recorder = recorder
+}
+
+// SetObserver notifies the observer of every request sent by the clients of the pipeline
+func (p *Pipeline) SetObserver(observer RequestObserver) {
+	p.observer = observer
+}
+
+// SetRetries changes how often and for how long at most throttled requests are retried
+func (p *Pipeline) SetRetries(maxRetries int, maxRetryAfter time.Duration) {
+	p.maxRetries = maxRetries
+	p.maxRetryAfter = maxRetryAfter
+}
+
+// HTTPClient returns a http client that sends its requests through the pipeline
+func (p *Pipeline) HTTPClient() *http.Client {
+	return &http.Client{Transport: p}
+}
+
+// ConfigureAutorest configures an autorest based client (the track 1 sdk) to send its requests through the pipeline.
+// This is a no-op on a nil pipeline.
+func (p *Pipeline) ConfigureAutorest(c *autorest.Client) {
+	if p == nil {
+		return
+	}
+
+	c.Sender = p.HTTPClient()
+	// Note: throttling is retried by the pipeline, this only covers the automatic provider registration
+	c.RetryAttempts = 1
+	c.RetryDuration = time.Second * 2
+}
+
+// ConfigureResourceManager configures a go-azure-sdk resource manager client. Its transport cannot be replaced, so
+// requests are rate limited before they are sent and the remaining requests are read from the responses, throttled
+// requests are retried by the client itself which also honours the Retry-After header. This is a no-op on a nil
+// pipeline.
+func (p *Pipeline) ConfigureResourceManager(c *resourcemanager.Client) {
+	if p == nil {
+		return
+	}
+
+	c.RequestMiddlewares = &[]client.RequestMiddleware{
+		func(req *http.Request) (*http.Request, error) {
+			p.requests.Add(1)
+			if err := p.wait(req.Context(), rateLimitKey(req)); err != nil {
+				return nil, err
+			}
+
+			// Note: a replayed request is sent to the local server of the replayer, the transport of the client
+			// cannot be replaced with the replayer itself
+			if replayer, ok := p.transport.(*Replayer); ok {
+				replayer.redirect(req)
+			}
+
+			req = req.WithContext(context.WithValue(req.Context(), requestStartKey{}, time.Now()))
+
+			return p.recorder.capture(req)
+		},
+	}
+	c.ResponseMiddlewares = &[]client.ResponseMiddleware{
+		func(req *http.Request, resp *http.Response) (*http.Response, error) {
+			if start, ok := req.Context().Value(requestStartKey{}).(time.Time); ok {
+				p.notify(req, resp, time.Since(start))
+			}
+
+			p.observe(req, resp)
+			recordSystemData(req, resp)
+			p.recorder.record(req, resp)
+			return resp, nil
+		},
+	}
+}
+
+// ConfigureGraph configures a hamilton microsoft graph client to send its requests through the pipeline. This is a
+// no-op on a nil pipeline.
+func (p *Pipeline) ConfigureGraph(c *msgraph.Client) {
+	if p == nil {
+		return
+	}
+
+	c.HttpClient = p.HTTPClient()
+}
+
+// RoundTrip implements http.RoundTripper
+func (p *Pipeline) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := rateLimitKey(req)
+
+	req, err := p.recorder.capture(req)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 && hasBody(req) {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+
+		if err := p.wait(req.Context(), key); err != nil {
+			return nil, err
+		}
+
+		p.requests.Add(1)
+
+		start := time.Now()
+		resp, err := p.transport.RoundTrip(r)
+		p.notify(r, resp, time.Since(start))
+		if err != nil {
+			return nil, err
+		}
+
+		p.observe(r, resp)
+
+		if !isThrottled(resp) {
+			recordSystemData(r, resp)
+			p.recorder.record(r, resp)
+			return resp, nil
+		}
+
+		// Note: a request whose body cannot be read again cannot be retried
+		if attempt >= p.maxRetries || (hasBody(req) && req.GetBody == nil) {
+			p.exhausted.Add(1)
+			p.recorder.record(r, resp)
+			return resp, nil
+		}
+
+		delay := p.retryAfter(resp, attempt)
+
+		logrus.
+			WithField("component", "pipeline").
+			WithField("key", key).
+			WithField("status", resp.StatusCode).
+			WithField("attempt", attempt+1).
+			Warnf("request throttled, retrying in %s", delay)
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		p.retries.Add(1)
+		p.retryWait.Add(int64(delay))
+
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Metrics returns a snapshot of the throttling metrics
+func (p *Pipeline) Metrics() PipelineMetrics {
+	return PipelineMetrics{
+		Requests:        p.requests.Load(),
+		Throttled:       p.throttled.Load(),
+		Retries:         p.retries.Load(),
+		Exhausted:       p.exhausted.Load(),
+		RateLimitWait:   time.Duration(p.rateLimitWait.Load()),
+		RetryWait:       time.Duration(p.retryWait.Load()),
+		LowestRemaining: p.lowestRemaining.Load(),
+	}
+}
+
+// LogMetrics logs the throttling metrics, this is a no-op on a nil pipeline
+func (p *Pipeline) LogMetrics(log *logrus.Entry) {
+	if p == nil {
+		return
+	}
+
+	m := p.Metrics()
+
+	log.
+		WithField("requests", m.Requests).
+		WithField("throttled", m.Throttled).
+		WithField("retries", m.Retries).
+		WithField("exhausted", m.Exhausted).
+		WithField("rate_limit_wait", m.RateLimitWait.Round(time.Millisecond).String()).
+		WithField("retry_wait", m.RetryWait.Round(time.Millisecond).String()).
+		WithField("lowest_remaining", m.LowestRemaining).
+		Info("throttling metrics")
+}
+
+// notify notifies the observer of a request that was sent
+func (p *Pipeline) notify(req *http.Request, resp *http.Response, duration time.Duration) {
+	if p.observer == nil {
+		return
+	}
+
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+
+	p.observer.ObserveRequest(req, status, duration, resp != nil && isThrottled(resp))
+}
+
+// wait blocks until the bucket of the key has a token available
+func (p *Pipeline) wait(ctx context.Context, key string) error {
+	delay := p.bucket(key).take()
+	if delay <= 0 {
+		return nil
+	}
+
+	p.rateLimitWait.Add(int64(delay))
+
+	return sleep(ctx, delay)
+}
+
+// observe records the throttling state reported by the response and adjusts the request rate of its bucket
+func (p *Pipeline) observe(req *http.Request, resp *http.Response) {
+	if resp == nil {
+		return
+	}
+
+	b := p.bucket(rateLimitKey(req))
+
+	if isThrottled(resp) {
+		p.throttled.Add(1)
+		b.reduce()
+		return
+	}
+
+	remaining := int64(-1)
+	for name, values := range resp.Header {
+		if !strings.HasPrefix(name, rateLimitRemainingPrefix) || len(values) == 0 {
+			continue
+		}
+
+		v, err := strconv.ParseInt(values[0], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		if remaining == -1 || v < remaining {
+			remaining = v
+		}
+	}
+
+	if remaining == -1 {
+		return
+	}
+
+	for {
+		lowest := p.lowestRemaining.Load()
+		if lowest != -1 && lowest <= remaining {
+			break
+		}
+		if p.lowestRemaining.CompareAndSwap(lowest, remaining) {
+			break
+		}
+	}
+
+	if remaining < rateLimitLowWatermark {
+		if b.reduce() {
+			logrus.
+				WithField("component", "pipeline").
+				WithField("key", rateLimitKey(req)).
+				WithField("remaining", remaining).
+				Debug("rate limit almost reached, slowing down")
+		}
+		return
+	}
+
+	b.restore()
+}
+
+// retryAfter returns how long to wait before retrying a throttled response, the delay requested by the server is
+// used if there is one, otherwise the delay grows exponentially with every attempt
+func (p *Pipeline) retryAfter(resp *http.Response, attempt int) time.Duration {
+	delay := time.Duration(math.Pow(2, float64(attempt))) * time.Second
+
+	for _, header := range []string{"Retry-After-Ms", "X-Ms-Retry-After-Ms"} {
+		if v := resp.Header.Get(header); v != "" {
+			if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+				delay = time.Duration(ms) * time.Millisecond
+				break
+			}
+		}
+	}
+
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if seconds, err := strconv.ParseInt(v, 10, 64); err == nil {
+			delay = time.Duration(seconds) * time.Second
+		} else if at, err := http.ParseTime(v); err == nil {
+			delay = time.Until(at)
+		}
+	}
+
+	if delay < 0 {
+		delay = 0
+	}
+
+	if delay > p.maxRetryAfter {
+		delay = p.maxRetryAfter
+	}
+
+	return delay
+}
+
+func (p *Pipeline) bucket(key string) *tokenBucket {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	b, ok := p.buckets[key]
+	if !ok {
+		b = newTokenBucket(p.rate, p.burst)
+		p.buckets[key] = b
+	}
+
+	return b
+}
+
+// rateLimitKey returns the key of the token bucket for the request, resource manager throttles per subscription so
+// requests scoped to a subscription share a bucket, every other request shares a bucket with its host
+func rateLimitKey(req *http.Request) string {
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(parts) >= 2 && strings.EqualFold(parts[0], "subscriptions") {
+		return "subscription/" + strings.ToLower(parts[1])
+	}
+
+	return req.URL.Host
+}
+
+func hasBody(req *http.Request) bool {
+	return req.Body != nil && req.Body != http.NoBody
+}
+
+func isThrottled(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusServiceUnavailable:
+		return resp.Header.Get("Retry-After") != ""
+	default:
+		return false
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// tokenBucket is a token bucket rate limiter, tokens are taken ahead of time so that concurrent requests queue up
+// behind each other instead of all waking up at the same time
+type tokenBucket struct {
+	lock sync.Mutex
+
+	rate     float64
+	baseRate float64
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(rate, burst float64) *tokenBucket {
+	return &tokenBucket{
+		rate:     rate,
+		baseRate: rate,
+		burst:    burst,
+		tokens:   burst,
+		last:     time.Now(),
+	}
+}
+
+// take takes a token and returns how long to wait before it may be used
+func (b *tokenBucket) take() time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.refill()
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// reduce lowers the rate of the bucket, it returns false if the rate was already reduced
+func (b *tokenBucket) reduce() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.rate < b.baseRate {
+		return false
+	}
+
+	b.refill()
+	b.rate = b.baseRate * rateLimitReducedFactor
+
+	return true
+}
+
+// restore resets the rate of the bucket to its original rate
+func (b *tokenBucket) restore() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.refill()
+	b.rate = b.baseRate
+}
+
+func (b *tokenBucket) refill() {
+	now := time.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
@@ -0,0 +1,101 @@
+package azure
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineRetriesThrottledRequests(t *testing.T) {
+	var requests atomic.Int64
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= 2 {
+			w.Header().Set("Retry-After-Ms", "10")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.Header().Set("x-ms-ratelimit-remaining-subscription-reads", "5")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	p := NewPipeline(nil)
+
+	resp, err := p.HTTPClient().Get(server.URL + "/subscriptions/sub-a/resourceGroups")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	_ = resp.Body.Close()
+
+	m := p.Metrics()
+	assert.Equal(t, int64(3), m.Requests)
+	assert.Equal(t, int64(2), m.Throttled)
+	assert.Equal(t, int64(2), m.Retries)
+	assert.Equal(t, int64(0), m.Exhausted)
+	assert.Equal(t, int64(5), m.LowestRemaining)
+	assert.Equal(t, 20*time.Millisecond, m.RetryWait)
+
+	// Note: the remaining requests were below the low watermark, so the subscription is slowed down
+	assert.Less(t, p.bucket("subscription/sub-a").rate, float64(DefaultRequestRate))
+}
+
+func TestPipelineReturnsThrottledResponseWhenRetriesAreExhausted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	p := NewPipeline(nil)
+	p.SetRetries(1, time.Second)
+
+	resp, err := p.HTTPClient().Get(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	_ = resp.Body.Close()
+
+	m := p.Metrics()
+	assert.Equal(t, int64(2), m.Requests)
+	assert.Equal(t, int64(1), m.Retries)
+	assert.Equal(t, int64(1), m.Exhausted)
+}
+
+func TestPipelineRateLimitsPerSubscription(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	p := NewPipeline(nil)
+	p.SetRateLimit(50, 1)
+
+	for _, path := range []string{"/subscriptions/sub-a", "/subscriptions/sub-b", "/subscriptions/sub-a"} {
+		resp, err := p.HTTPClient().Get(server.URL + path)
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+
+	// Note: only the second request for sub-a had to wait for a token, sub-b has a bucket of its own
+	wait := p.Metrics().RateLimitWait
+	assert.Greater(t, wait, time.Duration(0))
+	assert.LessOrEqual(t, wait, 20*time.Millisecond)
+}
+
+func TestRateLimitKey(t *testing.T) {
+	cases := map[string]string{
+		"https://management.azure.com/subscriptions/SUB-A/resourceGroups/rg": "subscription/sub-a",
+		"https://management.azure.com/providers/Microsoft.Management/x":      "management.azure.com",
+		"https://graph.microsoft.com/v1.0/users":                             "graph.microsoft.com",
+	}
+
+	for url, expected := range cases {
+		req, err := http.NewRequest(http.MethodGet, url, http.NoBody)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, rateLimitKey(req), url)
+	}
+}
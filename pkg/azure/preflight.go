@@ -0,0 +1,353 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization"
+
+	"github.com/ekristen/libnuke/pkg/registry"
+)
+
+const (
+	// PreflightOK means the caller has the permissions
+	PreflightOK = "ok"
+	// PreflightMissing means the caller is missing at least one of the permissions
+	PreflightMissing = "missing"
+	// PreflightUnknown means the permissions could not be determined, either because none are registered for the
+	// resource type or because the permissions of the caller could not be read
+	PreflightUnknown = "unknown"
+)
+
+// preflightTimeout is how long checking the permissions of a single scope may take
+const preflightTimeout = time.Minute
+
+// PreflightCheck is the result of checking the permissions of a single resource type in a single scope
+type PreflightCheck struct {
+	ResourceType string
+	// Scope is the tenant, management group or subscription the resource type is checked in
+	Scope  string
+	List   string
+	Delete string
+	// Missing are the permissions the caller is missing, or the reason the permissions are unknown
+	Missing []string
+}
+
+// PreflightResult is the result of the permission preflight check of a tenant
+type PreflightResult struct {
+	TenantID string
+	Identity *Identity
+	// GraphPermissions are the microsoft graph app roles (or delegated permissions) of the caller
+	GraphPermissions []string
+	// RoleAssignments are the names of the roles assigned to the caller, keyed by scope
+	RoleAssignments map[string][]string
+	Checks          []*PreflightCheck
+}
+
+// OK checks if the caller has every permission that is needed, unknown permissions are not treated as missing
+func (r *PreflightResult) OK() bool {
+	for _, check := range r.Checks {
+		if check.List == PreflightMissing || check.Delete == PreflightMissing {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Preflight checks if the caller has the permissions to list and remove every resource type that would run against
+// the tenant. Tenant scoped resource types are checked against the microsoft graph permissions of the access token,
+// every other resource type against the roles assigned to the caller at the management group or subscription.
+func Preflight(
+	ctx context.Context, tenant *Tenant, resourceTypes map[registry.Scope][]string,
+) (*PreflightResult, error) {
+	log := logrus.WithField("handler", "Preflight").WithField("tenant_id", tenant.ID)
+
+	result := &PreflightResult{
+		TenantID:        tenant.ID,
+		Identity:        tenant.Authorizers.Identity,
+		RoleAssignments: make(map[string][]string),
+	}
+
+	if len(resourceTypes[TenantScope]) > 0 {
+		log.Trace("checking microsoft graph permissions")
+
+		graphPermissions, err := graphPermissions(ctx, tenant.Authorizers)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read microsoft graph permissions: %w", err)
+		}
+
+		result.GraphPermissions = graphPermissions
+
+		for _, resourceType := range resourceTypes[TenantScope] {
+			result.Checks = append(result.Checks, checkGraphPermissions("tenant", resourceType, graphPermissions))
+		}
+	}
+
+	rbac := &rbacPermissions{
+		authorizers: tenant.Authorizers,
+		roles:       make(map[string]*armauthorization.RoleDefinition),
+	}
+
+	scopes := make([]preflightScope, 0)
+	if len(resourceTypes[ManagementGroupScope]) > 0 {
+		for _, name := range tenant.ManagementGroups.Names() {
+			scopes = append(scopes, preflightScope{
+				name:          fmt.Sprintf("management-group/%s", name),
+				id:            tenant.ManagementGroups[name].ID,
+				resourceTypes: resourceTypes[ManagementGroupScope],
+			})
+		}
+	}
+
+	// Note: resource group scoped resource types are checked at the subscription, a role that is only assigned to
+	// some of the resource groups of a subscription is not enough to nuke the subscription
+	subResourceTypes := append(append([]string{},
+		resourceTypes[SubscriptionScope]...), resourceTypes[ResourceGroupScope]...)
+	if len(subResourceTypes) > 0 {
+		for _, subscriptionID := range tenant.SubscriptionIds {
+			scopes = append(scopes, preflightScope{
+				name:          fmt.Sprintf("subscription/%s", subscriptionID),
+				id:            fmt.Sprintf("/subscriptions/%s", subscriptionID),
+				resourceTypes: subResourceTypes,
+			})
+		}
+	}
+
+	for _, scope := range scopes {
+		log.Tracef("checking role assignments of %s", scope.name)
+
+		effective, roleNames, err := rbac.EffectivePermissions(ctx, scope.id)
+		if err != nil {
+			log.WithError(err).Warnf("unable to read the role assignments of %s", scope.name)
+		}
+
+		result.RoleAssignments[scope.name] = roleNames
+
+		for _, resourceType := range scope.resourceTypes {
+			if err != nil {
+				result.Checks = append(result.Checks, &PreflightCheck{
+					ResourceType: resourceType,
+					Scope:        scope.name,
+					List:         PreflightUnknown,
+					Delete:       PreflightUnknown,
+					Missing:      []string{fmt.Sprintf("unable to read role assignments: %s", err)},
+				})
+				continue
+			}
+
+			result.Checks = append(result.Checks, checkRBACPermissions(scope.name, resourceType, effective))
+		}
+	}
+
+	return result, nil
+}
+
+type preflightScope struct {
+	name          string
+	id            string
+	resourceTypes []string
+}
+
+// graphPermissions returns the microsoft graph app roles or delegated permissions from the claims of the token
+func graphPermissions(ctx context.Context, authorizers *Authorizers) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := authorizers.MicrosoftGraph.Token(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := parseTokenClaims(token.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return claims.Permissions(), nil
+}
+
+// checkGraphPermissions checks the registered microsoft graph permissions of a resource type, any one of the
+// registered permissions is sufficient
+func checkGraphPermissions(scope, resourceType string, granted []string) *PreflightCheck {
+	check := &PreflightCheck{
+		ResourceType: resourceType,
+		Scope:        scope,
+		List:         PreflightUnknown,
+		Delete:       PreflightUnknown,
+	}
+
+	p, ok := GetPermissions(resourceType)
+	if !ok || len(p.GraphList) == 0 {
+		check.Missing = []string{"no permissions registered for the resource type"}
+		return check
+	}
+
+	hasAny := func(required []string) bool {
+		for _, r := range required {
+			if slices.ContainsFunc(granted, func(g string) bool { return strings.EqualFold(g, r) }) {
+				return true
+			}
+		}
+		return false
+	}
+
+	check.List = PreflightOK
+	if !hasAny(p.GraphList) {
+		check.List = PreflightMissing
+		check.Missing = append(check.Missing, strings.Join(p.GraphList, " or "))
+	}
+
+	check.Delete = PreflightOK
+	if !hasAny(p.GraphDelete) {
+		check.Delete = PreflightMissing
+		check.Missing = append(check.Missing, strings.Join(p.GraphDelete, " or "))
+	}
+
+	return check
+}
+
+// checkRBACPermissions checks the registered azure rbac actions of a resource type, all registered actions are
+// required
+func checkRBACPermissions(scope, resourceType string, effective EffectivePermissions) *PreflightCheck {
+	check := &PreflightCheck{
+		ResourceType: resourceType,
+		Scope:        scope,
+		List:         PreflightUnknown,
+		Delete:       PreflightUnknown,
+	}
+
+	p, ok := GetPermissions(resourceType)
+	if !ok || len(p.List) == 0 {
+		check.Missing = []string{"no permissions registered for the resource type"}
+		return check
+	}
+
+	check.List = PreflightOK
+	if missing := effective.Missing(p.List); len(missing) > 0 {
+		check.List = PreflightMissing
+		check.Missing = append(check.Missing, missing...)
+	}
+
+	check.Delete = PreflightOK
+	if missing := effective.Missing(p.Delete); len(missing) > 0 {
+		check.Delete = PreflightMissing
+		check.Missing = append(check.Missing, missing...)
+	}
+
+	return check
+}
+
+// rbacPermissions resolves the effective azure rbac permissions of the caller, the role definitions are cached as
+// the same roles are usually assigned in many scopes
+type rbacPermissions struct {
+	authorizers *Authorizers
+	roles       map[string]*armauthorization.RoleDefinition
+}
+
+// EffectivePermissions returns the permissions granted to the caller at the scope along with the names of the roles,
+// this includes roles assigned to groups the caller is a member of and roles inherited from parent scopes. Deny
+// assignments and conditions are not taken into account.
+func (p *rbacPermissions) EffectivePermissions(pctx context.Context, scope string) (EffectivePermissions, []string, error) {
+	ctx, cancel := context.WithTimeout(pctx, preflightTimeout)
+	defer cancel()
+
+	if p.authorizers.Identity == nil {
+		return nil, nil, fmt.Errorf("the identity of the caller is unknown")
+	}
+
+	clientOptions := &arm.ClientOptions{ClientOptions: p.authorizers.ClientOptions}
+
+	client, err := armauthorization.NewRoleAssignmentsClient("", p.authorizers.IdentityCreds, clientOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defClient, err := armauthorization.NewRoleDefinitionsClient(p.authorizers.IdentityCreds, clientOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var effective EffectivePermissions
+	var roleNames []string
+
+	pager := client.NewListForScopePager(scope, &armauthorization.RoleAssignmentsClientListForScopeOptions{
+		Filter: ptr.String(fmt.Sprintf("assignedTo('%s')", p.authorizers.Identity.ObjectID)),
+	})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, assignment := range page.Value {
+			if assignment.Properties == nil || !appliesToScope(ptr.ToString(assignment.Properties.Scope), scope) {
+				continue
+			}
+
+			roleDefinitionID := ptr.ToString(assignment.Properties.RoleDefinitionID)
+			role, ok := p.roles[roleDefinitionID]
+			if !ok {
+				res, err := defClient.GetByID(ctx, roleDefinitionID, nil)
+				if err != nil {
+					return nil, nil, err
+				}
+
+				role = &res.RoleDefinition
+				p.roles[roleDefinitionID] = role
+			}
+
+			if role.Properties == nil {
+				continue
+			}
+
+			roleNames = append(roleNames, ptr.ToString(role.Properties.RoleName))
+
+			for _, permission := range role.Properties.Permissions {
+				effective = append(effective, RolePermission{
+					Actions:    stringValues(permission.Actions),
+					NotActions: stringValues(permission.NotActions),
+				})
+			}
+		}
+	}
+
+	slices.Sort(roleNames)
+
+	return effective, slices.Compact(roleNames), nil
+}
+
+// appliesToScope checks if a role assigned at the assignment scope applies to the scope, the role assignments listed
+// for a scope include the ones below the scope (i.e. on a resource group) which do not apply to the scope itself
+func appliesToScope(assignmentScope, scope string) bool {
+	assignmentScope = strings.ToLower(strings.TrimSuffix(assignmentScope, "/"))
+	scope = strings.ToLower(strings.TrimSuffix(scope, "/"))
+
+	if assignmentScope == scope {
+		return true
+	}
+
+	return !strings.HasPrefix(assignmentScope, scope+"/")
+}
+
+// stringValues dereferences a slice of string pointers, nil pointers are skipped
+func stringValues(values []*string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != nil {
+			result = append(result, *v)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,150 @@
+package azure
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+
+	"github.com/hashicorp/go-azure-sdk/sdk/environments"
+
+	"github.com/ekristen/libnuke/pkg/registry"
+)
+
+type testCredential struct{}
+
+func (c *testCredential) GetToken(_ context.Context, _ policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "fake-token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+// testGraphAuthorizer hands out a token with the given microsoft graph app roles
+type testGraphAuthorizer struct {
+	roles string
+}
+
+func (a *testGraphAuthorizer) Token(_ context.Context, _ *http.Request) (*oauth2.Token, error) {
+	claims := base64.RawURLEncoding.EncodeToString([]byte(`{"oid":"caller","roles":` + a.roles + `}`))
+	return &oauth2.Token{AccessToken: "header." + claims + ".signature", TokenType: "Bearer"}, nil
+}
+
+func (a *testGraphAuthorizer) AuxiliaryTokens(_ context.Context, _ *http.Request) ([]*oauth2.Token, error) {
+	return nil, nil
+}
+
+func TestRolePermissionAllows(t *testing.T) {
+	contributor := RolePermission{
+		Actions:    []string{"*"},
+		NotActions: []string{"Microsoft.Authorization/*/Delete", "Microsoft.Authorization/*/Write"},
+	}
+
+	assert.True(t, contributor.Allows("Microsoft.Compute/disks/delete"))
+	assert.True(t, contributor.Allows("Microsoft.Authorization/roleAssignments/read"))
+	assert.False(t, contributor.Allows("Microsoft.Authorization/roleAssignments/delete"))
+
+	reader := RolePermission{Actions: []string{"*/read"}}
+	assert.True(t, reader.Allows("microsoft.compute/disks/read"))
+	assert.False(t, reader.Allows("Microsoft.Compute/disks/delete"))
+
+	effective := EffectivePermissions{reader, {Actions: []string{"Microsoft.Compute/*"}}}
+	assert.Empty(t, effective.Missing([]string{"Microsoft.Compute/disks/delete", "Microsoft.Web/sites/read"}))
+	assert.Equal(t, []string{"Microsoft.Web/sites/delete"}, effective.Missing([]string{"Microsoft.Web/sites/delete"}))
+}
+
+func TestAppliesToScope(t *testing.T) {
+	assert.True(t, appliesToScope("/subscriptions/sub-a", "/subscriptions/SUB-A"))
+	assert.True(t, appliesToScope("/", "/subscriptions/sub-a"))
+	assert.True(t, appliesToScope("/providers/Microsoft.Management/managementGroups/root", "/subscriptions/sub-a"))
+	assert.False(t, appliesToScope("/subscriptions/sub-a/resourceGroups/rg", "/subscriptions/sub-a"))
+}
+
+func TestPreflight(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/providers/Microsoft.Authorization/roleAssignments"):
+			assert.Equal(t, "assignedTo('caller')", r.URL.Query().Get("$filter"))
+			_, _ = w.Write([]byte(`{"value":[
+				{"properties":{"scope":"/subscriptions/sub-a","roleDefinitionId":"/providers/Microsoft.Authorization/roleDefinitions/reader"}},
+				{"properties":{"scope":"/subscriptions/sub-a/resourceGroups/rg","roleDefinitionId":"/providers/Microsoft.Authorization/roleDefinitions/owner"}}
+			]}`))
+		case strings.HasSuffix(r.URL.Path, "/roleDefinitions/reader"):
+			_, _ = w.Write([]byte(`{"properties":{"roleName":"Reader","permissions":[{"actions":["*/read"]}]}}`))
+		case strings.HasSuffix(r.URL.Path, "/roleDefinitions/owner"):
+			_, _ = w.Write([]byte(`{"properties":{"roleName":"Owner","permissions":[{"actions":["*"]}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	env := environments.AzurePublic()
+	env.ResourceManager = environments.ResourceManagerAPI(server.URL)
+
+	cloudConfig, err := NewCloudConfiguration(env)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	RegisterPermissions("TestPreflightUser", Permissions{
+		GraphList:   []string{"User.Read.All", "User.ReadWrite.All"},
+		GraphDelete: []string{"User.ReadWrite.All"},
+	})
+	RegisterPermissions("TestPreflightDisk", Permissions{
+		List:   []string{"Microsoft.Compute/disks/read"},
+		Delete: []string{"Microsoft.Compute/disks/delete"},
+	})
+
+	tenant := &Tenant{
+		ID:              "tenant",
+		SubscriptionIds: []string{"sub-a"},
+		Authorizers: &Authorizers{
+			MicrosoftGraph: &testGraphAuthorizer{roles: `["User.Read.All"]`},
+			IdentityCreds:  &testCredential{},
+			Environment:    env,
+			Identity:       &Identity{ObjectID: "caller"},
+			ClientOptions: azcore.ClientOptions{
+				Cloud:                           cloudConfig,
+				InsecureAllowCredentialWithHTTP: true,
+				Retry:                           policy.RetryOptions{MaxRetries: -1},
+			},
+		},
+	}
+
+	result, err := Preflight(context.TODO(), tenant, map[registry.Scope][]string{
+		TenantScope:        {"TestPreflightUser"},
+		ResourceGroupScope: {"TestPreflightDisk", "TestPreflightUnregistered"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.False(t, result.OK())
+	assert.Equal(t, []string{"User.Read.All"}, result.GraphPermissions)
+
+	// Note: the owner role is only assigned to a resource group, so it does not apply to the subscription
+	assert.Equal(t, []string{"Reader"}, result.RoleAssignments["subscription/sub-a"])
+
+	if !assert.Len(t, result.Checks, 3) {
+		return
+	}
+
+	assert.Equal(t, &PreflightCheck{
+		ResourceType: "TestPreflightUser", Scope: "tenant",
+		List: PreflightOK, Delete: PreflightMissing, Missing: []string{"User.ReadWrite.All"},
+	}, result.Checks[0])
+	assert.Equal(t, &PreflightCheck{
+		ResourceType: "TestPreflightDisk", Scope: "subscription/sub-a",
+		List: PreflightOK, Delete: PreflightMissing, Missing: []string{"Microsoft.Compute/disks/delete"},
+	}, result.Checks[1])
+	assert.Equal(t, PreflightUnknown, result.Checks[2].List)
+}
@@ -1,7 +1,11 @@
 package azure
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"time"
 
 	libnuke "github.com/ekristen/libnuke/pkg/nuke"
@@ -13,13 +17,36 @@ import (
 type Prompt struct {
 	Parameters *libnuke.Parameters
 	Tenant     *Tenant
+
+	// Tenants is used instead of Tenant when running against multiple tenants, this results in a single
+	// consolidated prompt for all tenants instead of one per tenant.
+	Tenants []*Tenant
+
+	// Stop is set when the resources are stopped instead of removed
+	Stop bool
+
+	// input is where the tenant IDs are read from when prompting for multiple tenants, defaults to stdin
+	input io.Reader
+}
+
+// action returns what is done to the tenants for the prompt
+func (p *Prompt) action() string {
+	if p.Stop {
+		return "stop the compute resources of"
+	}
+
+	return "nuke"
 }
 
 func (p *Prompt) Prompt() error {
+	if len(p.Tenants) > 0 {
+		return p.promptMultiple()
+	}
+
 	forceSleep := time.Duration(p.Parameters.ForceSleep) * time.Second
 
-	fmt.Printf("Do you really want to nuke the tenant and subscriptions with "+
-		"the ID %s?\n", p.Tenant.ID)
+	fmt.Printf("Do you really want to %s the tenant and subscriptions with "+
+		"the ID %s?\n", p.action(), p.Tenant.ID)
 	if p.Parameters.Force {
 		fmt.Printf("Waiting %v before continuing.\n", forceSleep)
 		time.Sleep(forceSleep)
@@ -32,3 +59,45 @@ func (p *Prompt) Prompt() error {
 
 	return nil
 }
+
+func (p *Prompt) promptMultiple() error {
+	forceSleep := time.Duration(p.Parameters.ForceSleep) * time.Second
+
+	fmt.Printf("Do you really want to %s the following %d tenants and their subscriptions?\n", p.action(), len(p.Tenants))
+	for _, tenant := range p.Tenants {
+		fmt.Printf("  - %s (subscriptions: %d)\n", tenant.ID, len(tenant.SubscriptionIds))
+	}
+
+	if p.Parameters.Force {
+		fmt.Printf("Waiting %v before continuing.\n", forceSleep)
+		time.Sleep(forceSleep)
+		return nil
+	}
+
+	// Note: every tenant ID has to be entered, as for a single tenant, a single reader is used for all of them so
+	// that the IDs can be piped in as well
+	input := p.input
+	if input == nil {
+		input = os.Stdin
+	}
+
+	reader := bufio.NewReader(input)
+
+	fmt.Printf("Do you want to continue? Enter the ID of every tenant, one per line, to continue.\n")
+	for _, tenant := range p.Tenants {
+		fmt.Print("> ")
+
+		text, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+
+		if strings.TrimSpace(text) != tenant.ID {
+			return fmt.Errorf("aborted")
+		}
+	}
+
+	fmt.Println()
+
+	return nil
+}
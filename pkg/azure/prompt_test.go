@@ -0,0 +1,55 @@
+package azure
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	libnuke "github.com/ekristen/libnuke/pkg/nuke"
+)
+
+func TestPromptMultipleTenants(t *testing.T) {
+	tenants := []*Tenant{
+		{ID: "00000000-0000-0000-0000-000000000001"},
+		{ID: "00000000-0000-0000-0000-000000000002"},
+	}
+
+	cases := map[string]struct {
+		input string
+		err   bool
+	}{
+		"every tenant ID": {
+			input: "00000000-0000-0000-0000-000000000001\n 00000000-0000-0000-0000-000000000002 \n",
+		},
+		"number of tenants": {
+			input: "2\n",
+			err:   true,
+		},
+		"wrong order": {
+			input: "00000000-0000-0000-0000-000000000002\n00000000-0000-0000-0000-000000000001\n",
+			err:   true,
+		},
+		"missing tenant ID": {
+			input: "00000000-0000-0000-0000-000000000001\n",
+			err:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := &Prompt{
+				Parameters: &libnuke.Parameters{},
+				Tenants:    tenants,
+				input:      strings.NewReader(tc.input),
+			}
+
+			err := p.Prompt()
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gotidy/ptr"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2021-04-01/resources" //nolint:staticcheck
+)
+
+const (
+	// QuarantineMarkedAtTag is the tag that records when a resource was quarantined
+	QuarantineMarkedAtTag = "azure-nuke/marked-at"
+	// QuarantineDeleteAfterTag is the tag that records when the grace period of a quarantined resource expires
+	QuarantineDeleteAfterTag = "azure-nuke/delete-after"
+)
+
+// QuarantineMark records when a resource was quarantined and when it can be removed
+type QuarantineMark struct {
+	MarkedAt    time.Time `json:"marked_at"`
+	DeleteAfter time.Time `json:"delete_after"`
+}
+
+// Expired checks if the grace period of the resource has expired
+func (m *QuarantineMark) Expired(now time.Time) bool {
+	return !now.Before(m.DeleteAfter)
+}
+
+// Tags returns the tags that record the mark on a resource
+func (m *QuarantineMark) Tags() map[string]*string {
+	return map[string]*string{
+		QuarantineMarkedAtTag:    ptr.String(m.MarkedAt.Format(time.RFC3339)),
+		QuarantineDeleteAfterTag: ptr.String(m.DeleteAfter.Format(time.RFC3339)),
+	}
+}
+
+// Quarantine marks the resources that would be removed instead of removing them, they are only removed by a later
+// run once their grace period has expired. Resources that can be tagged are marked with tags, so that removing the
+// tags releases them, every other resource (i.e. Entra objects) is recorded in a local state file.
+type Quarantine struct {
+	GracePeriod time.Duration
+
+	path  string
+	now   time.Time
+	lock  sync.Mutex
+	marks map[string]*QuarantineMark
+}
+
+type quarantineState struct {
+	Marks map[string]*QuarantineMark `json:"marks"`
+}
+
+// NewQuarantine creates a quarantine with the state file at the path, the state file does not need to exist yet
+func NewQuarantine(path string, gracePeriod time.Duration) (*Quarantine, error) {
+	q := &Quarantine{
+		GracePeriod: gracePeriod,
+		path:        path,
+		now:         time.Now().UTC().Truncate(time.Second),
+		marks:       make(map[string]*QuarantineMark),
+	}
+
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	state := &quarantineState{}
+	if err := json.Unmarshal(raw, state); err != nil {
+		return nil, fmt.Errorf("unable to read quarantine state %s: %w", path, err)
+	}
+
+	for key, mark := range state.Marks {
+		q.marks[strings.ToLower(key)] = mark
+	}
+
+	return q, nil
+}
+
+// Now returns the time of the run, every mark and expiry is relative to it
+func (q *Quarantine) Now() time.Time {
+	return q.now
+}
+
+// NewMark returns the mark for a resource that is quarantined by this run
+func (q *Quarantine) NewMark() *QuarantineMark {
+	return &QuarantineMark{
+		MarkedAt:    q.now,
+		DeleteAfter: q.now.Add(q.GracePeriod),
+	}
+}
+
+// TaggedMark returns the mark recorded in the tags of a resource, nil if the resource is not marked
+func (q *Quarantine) TaggedMark(tags map[string]*string) *QuarantineMark {
+	deleteAfter, err := time.Parse(time.RFC3339, ptr.ToString(tags[QuarantineDeleteAfterTag]))
+	if err != nil {
+		return nil
+	}
+
+	// Note: the time of the mark is informational, the grace period is only determined by the delete-after tag
+	markedAt, _ := time.Parse(time.RFC3339, ptr.ToString(tags[QuarantineMarkedAtTag]))
+
+	return &QuarantineMark{
+		MarkedAt:    markedAt,
+		DeleteAfter: deleteAfter,
+	}
+}
+
+// StateMark returns the mark recorded in the state file for the resource, nil if the resource is not marked
+func (q *Quarantine) StateMark(key string) *QuarantineMark {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.marks[strings.ToLower(key)]
+}
+
+// SetStateMark records the mark of the resource in the state file, a nil mark removes the resource from it
+func (q *Quarantine) SetStateMark(key string, mark *QuarantineMark) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if mark == nil {
+		delete(q.marks, strings.ToLower(key))
+		return
+	}
+
+	q.marks[strings.ToLower(key)] = mark
+}
+
+// Tag marks a resource manager resource by merging the tags of the mark into its tags
+func (q *Quarantine) Tag(ctx context.Context, authorizers *Authorizers, id string, mark *QuarantineMark) error {
+	endpoint, ok := authorizers.Environment.ResourceManager.Endpoint()
+	if !ok {
+		return fmt.Errorf("environment %s has no resource manager endpoint", authorizers.Environment.Name)
+	}
+
+	client := resources.NewTagsClientWithBaseURI(*endpoint, "")
+	client.Authorizer = authorizers.Management
+	authorizers.Pipeline.ConfigureAutorest(&client.Client)
+
+	// Note: the scope is joined to the path of the request with a slash of its own
+	_, err := client.UpdateAtScope(ctx, strings.TrimPrefix(id, "/"), resources.TagsPatchResource{
+		Operation:  resources.TagsPatchOperationMerge,
+		Properties: &resources.Tags{Tags: mark.Tags()},
+	})
+
+	return err
+}
+
+// Save writes the state file
+func (q *Quarantine) Save() error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	raw, err := json.MarshalIndent(&quarantineState{Marks: q.marks}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(q.path, raw, 0600)
+}
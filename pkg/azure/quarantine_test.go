@@ -0,0 +1,54 @@
+package azure
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuarantineTaggedMark(t *testing.T) {
+	q, err := NewQuarantine(filepath.Join(t.TempDir(), "quarantine.json"), 24*time.Hour)
+	assert.NoError(t, err)
+
+	mark := q.NewMark()
+	assert.Equal(t, q.Now().Add(24*time.Hour), mark.DeleteAfter)
+	assert.False(t, mark.Expired(q.Now()))
+	assert.True(t, mark.Expired(mark.DeleteAfter))
+
+	tagged := q.TaggedMark(mark.Tags())
+	assert.NotNil(t, tagged)
+	assert.True(t, mark.DeleteAfter.Equal(tagged.DeleteAfter))
+	assert.True(t, mark.MarkedAt.Equal(tagged.MarkedAt))
+
+	assert.Nil(t, q.TaggedMark(nil), "untagged resource")
+	assert.Nil(t, q.TaggedMark(map[string]*string{
+		QuarantineMarkedAtTag: ptr.String(mark.MarkedAt.Format(time.RFC3339)),
+	}), "resource without the delete-after tag")
+	assert.Nil(t, q.TaggedMark(map[string]*string{
+		QuarantineDeleteAfterTag: ptr.String("tomorrow"),
+	}), "resource with an invalid delete-after tag")
+}
+
+func TestQuarantineState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quarantine.json")
+
+	q, err := NewQuarantine(path, time.Hour)
+	assert.NoError(t, err)
+
+	mark := q.NewMark()
+	q.SetStateMark("AzureADUser/00000000-0000-0000-0000-00000000000A", mark)
+	q.SetStateMark("AzureADGroup/00000000-0000-0000-0000-00000000000B", q.NewMark())
+	q.SetStateMark("AzureADGroup/00000000-0000-0000-0000-00000000000B", nil)
+	assert.NoError(t, q.Save())
+
+	loaded, err := NewQuarantine(path, time.Hour)
+	assert.NoError(t, err)
+
+	found := loaded.StateMark("azureaduser/00000000-0000-0000-0000-00000000000a")
+	assert.NotNil(t, found)
+	assert.True(t, mark.DeleteAfter.Equal(found.DeleteAfter))
+	assert.Nil(t, loaded.StateMark("AzureADGroup/00000000-0000-0000-0000-00000000000B"))
+}
@@ -0,0 +1,425 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hashicorp/go-azure-sdk/sdk/environments"
+)
+
+const (
+	// RecordingExchangesDir is the directory of a recording that holds the http exchanges, one file per exchange
+	RecordingExchangesDir = "exchanges"
+	// RecordingIdentitiesDir is the directory of a recording that holds the identity of every tenant
+	RecordingIdentitiesDir = "identities"
+
+	redacted = "REDACTED"
+)
+
+var (
+	// redactedHeaders match the headers that carry credentials, their values are never written to a recording
+	redactedHeaders = regexp.MustCompile(`(?i)authorization|token|cookie|secret|signature`)
+	// redactedBody match the credentials in a body, a jwt or a token field of a token response
+	redactedBody = []*regexp.Regexp{
+		regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+		regexp.MustCompile(`"(access_token|refresh_token|id_token|client_secret)"\s*:\s*"[^"]*"`),
+	}
+)
+
+// Exchange is a single http request and its response, as it is written to a recording
+type Exchange struct {
+	// Key identifies the request regardless of the host it was sent to, see exchangeKey
+	Key      string          `json:"key"`
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Request  ExchangeMessage `json:"request"`
+	Response ExchangeMessage `json:"response"`
+}
+
+// ExchangeMessage is the request or response of an exchange
+type ExchangeMessage struct {
+	Status int         `json:"status,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// responseHeader returns the header of the response to serve, the body is served as it was read by the client and
+// not as it was sent, so its length and encoding are not kept
+func (e *Exchange) responseHeader() http.Header {
+	header := make(http.Header, len(e.Response.Header))
+	for name, values := range e.Response.Header {
+		if strings.EqualFold(name, "Content-Length") || strings.EqualFold(name, "Content-Encoding") {
+			continue
+		}
+		header[name] = values
+	}
+
+	return header
+}
+
+type requestBodyKey struct{}
+
+// Recorder writes every resource manager and microsoft graph exchange of the pipelines it is set on to a directory,
+// the credentials in the headers and bodies are redacted. Requests to any other host (i.e. to request a token) are
+// not recorded.
+type Recorder struct {
+	dir   string
+	hosts map[string]bool
+	seq   atomic.Int64
+}
+
+// NewRecorder creates a recorder that writes to the directory, the hosts that are recorded are taken from the
+// resource manager and microsoft graph endpoints of the environment
+func NewRecorder(dir string, env *environments.Environment) (*Recorder, error) {
+	for _, sub := range []string{RecordingExchangesDir, RecordingIdentitiesDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0700); err != nil {
+			return nil, err
+		}
+	}
+
+	r := &Recorder{
+		dir:   dir,
+		hosts: make(map[string]bool),
+	}
+
+	for _, api := range []environments.Api{env.ResourceManager, env.MicrosoftGraph} {
+		if api == nil {
+			continue
+		}
+
+		endpoint, ok := api.Endpoint()
+		if !ok {
+			continue
+		}
+
+		u, err := url.Parse(*endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		r.hosts[strings.ToLower(u.Host)] = true
+	}
+
+	return r, nil
+}
+
+// RecordIdentity writes the identity that the tenant was authenticated as, it is used instead of authenticating when
+// the recording is replayed
+func (r *Recorder) RecordIdentity(tenantID string, identity *Identity) error {
+	data, err := json.MarshalIndent(identity, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(r.dir, RecordingIdentitiesDir, tenantID+".json"), data, 0600)
+}
+
+// capture reads the body of the request so that it can be recorded, the request is returned with a new body that
+// can be read again. This is a no-op on a nil recorder.
+func (r *Recorder) capture(req *http.Request) (*http.Request, error) {
+	if r == nil || !r.hosts[strings.ToLower(req.URL.Host)] {
+		return req, nil
+	}
+
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	captured := req.WithContext(context.WithValue(req.Context(), requestBodyKey{}, body))
+	if body != nil {
+		captured.Body = io.NopCloser(bytes.NewReader(body))
+		captured.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	return captured, nil
+}
+
+// record writes the exchange of a request that was captured, the body of the response is replaced so that the client
+// can still read it. This is a no-op on a nil recorder.
+func (r *Recorder) record(req *http.Request, resp *http.Response) {
+	if r == nil || resp == nil || !r.hosts[strings.ToLower(req.URL.Host)] {
+		return
+	}
+
+	reqBody, _ := req.Context().Value(requestBodyKey{}).([]byte)
+
+	var respBody []byte
+	if resp.Body != nil {
+		var err error
+		respBody, err = io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+		if err != nil {
+			return
+		}
+	}
+
+	exchange := &Exchange{
+		Key:    exchangeKey(req, reqBody),
+		Method: req.Method,
+		URL:    redactBody(req.URL.String()),
+		Request: ExchangeMessage{
+			Header: redactHeader(req.Header),
+			Body:   redactBody(string(reqBody)),
+		},
+		Response: ExchangeMessage{
+			Status: resp.StatusCode,
+			Header: redactHeader(resp.Header),
+			Body:   redactBody(string(respBody)),
+		},
+	}
+
+	data, err := json.MarshalIndent(exchange, "", "  ")
+	if err == nil {
+		name := fmt.Sprintf("%06d.json", r.seq.Add(1))
+		err = os.WriteFile(filepath.Join(r.dir, RecordingExchangesDir, name), data, 0600)
+	}
+	if err != nil {
+		logrus.WithField("component", "recorder").WithError(err).Warn("unable to record exchange")
+	}
+}
+
+// Replayer serves the exchanges of a recording instead of sending the requests, the responses to the same request are
+// served in the order they were recorded, the last one is served again once all of them have been served. It is
+// the transport of the pipelines, the go-azure-sdk clients whose transport cannot be replaced are sent to its local
+// server instead.
+type Replayer struct {
+	dir string
+
+	lock      sync.Mutex
+	exchanges map[string][]*Exchange
+	served    map[string]int
+	unmatched atomic.Int64
+
+	listener net.Listener
+	server   *http.Server
+}
+
+// NewReplayer loads the exchanges of a recording and starts the local server of the replayer, it has to be closed
+func NewReplayer(dir string) (*Replayer, error) {
+	files, err := filepath.Glob(filepath.Join(dir, RecordingExchangesDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no exchanges recorded in %s", dir)
+	}
+
+	// Note: the files are named by the sequence they were recorded in
+	sort.Strings(files)
+
+	r := &Replayer{
+		dir:       dir,
+		exchanges: make(map[string][]*Exchange),
+		served:    make(map[string]int),
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		exchange := &Exchange{}
+		if err := json.Unmarshal(data, exchange); err != nil {
+			return nil, fmt.Errorf("unable to read exchange %s: %w", file, err)
+		}
+
+		r.exchanges[exchange.Key] = append(r.exchanges[exchange.Key], exchange)
+	}
+
+	r.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	r.server = &http.Server{Handler: r, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		_ = r.server.Serve(r.listener)
+	}()
+
+	return r, nil
+}
+
+// Identity returns the identity that the tenant was authenticated as when the recording was made
+func (r *Replayer) Identity(tenantID string) (*Identity, error) {
+	data, err := os.ReadFile(filepath.Join(r.dir, RecordingIdentitiesDir, tenantID+".json"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("tenant %s is not in the recording", tenantID)
+		}
+		return nil, err
+	}
+
+	identity := &Identity{}
+	if err := json.Unmarshal(data, identity); err != nil {
+		return nil, err
+	}
+
+	return identity, nil
+}
+
+// Unmatched returns the number of requests that had no recorded exchange
+func (r *Replayer) Unmatched() int64 {
+	return r.unmatched.Load()
+}
+
+// Close stops the local server of the replayer
+func (r *Replayer) Close() error {
+	return r.server.Close()
+}
+
+// redirect sends the request to the local server of the replayer instead of the host it was meant for
+func (r *Replayer) redirect(req *http.Request) {
+	req.URL.Scheme = "http"
+	req.URL.Host = r.listener.Addr().String()
+	req.Host = ""
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	exchange := r.next(req, body)
+	header := exchange.responseHeader()
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.Response.Status, http.StatusText(exchange.Response.Status)),
+		StatusCode:    exchange.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(exchange.Response.Body)),
+		ContentLength: int64(len(exchange.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// ServeHTTP implements http.Handler
+func (r *Replayer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	exchange := r.next(req, body)
+
+	for name, values := range exchange.responseHeader() {
+		w.Header()[name] = values
+	}
+
+	w.WriteHeader(exchange.Response.Status)
+	_, _ = io.WriteString(w, exchange.Response.Body)
+}
+
+// next returns the exchange to serve for the request, a not found error in the format of resource manager is
+// served if nothing was recorded for the request
+func (r *Replayer) next(req *http.Request, body []byte) *Exchange {
+	key := exchangeKey(req, body)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	exchanges := r.exchanges[key]
+	if len(exchanges) == 0 {
+		r.unmatched.Add(1)
+
+		logrus.
+			WithField("component", "replayer").
+			WithField("method", req.Method).
+			WithField("path", req.URL.Path).
+			Warn("no exchange recorded for request")
+
+		return &Exchange{
+			Response: ExchangeMessage{
+				Status: http.StatusNotFound,
+				Header: http.Header{"Content-Type": []string{"application/json"}},
+				Body:   `{"error":{"code":"NotRecorded","message":"no exchange recorded for the request"}}`,
+			},
+		}
+	}
+
+	i := r.served[key]
+	if i < len(exchanges)-1 {
+		r.served[key] = i + 1
+	}
+
+	return exchanges[i]
+}
+
+// exchangeKey identifies a request regardless of the host it was sent to, as the replayed requests of the go-azure-sdk
+// clients are sent to the local server. The query is sorted and the body is hashed so that paged queries (i.e. of
+// resource graph) with the same path are told apart.
+func exchangeKey(req *http.Request, body []byte) string {
+	key := fmt.Sprintf("%s %s?%s", req.Method, req.URL.Path, req.URL.Query().Encode())
+
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		key += " " + hex.EncodeToString(sum[:8])
+	}
+
+	return strings.ToLower(key)
+}
+
+// readRequestBody reads the body of the request, the body is replaced so that it can be read again
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func redactHeader(header http.Header) http.Header {
+	redactedHeader := make(http.Header, len(header))
+	for name, values := range header {
+		if redactedHeaders.MatchString(name) {
+			redactedHeader[name] = []string{redacted}
+			continue
+		}
+
+		redactedHeader[name] = values
+	}
+
+	return redactedHeader
+}
+
+func redactBody(body string) string {
+	body = redactedBody[0].ReplaceAllString(body, redacted)
+	return redactedBody[1].ReplaceAllString(body, fmt.Sprintf(`"$1":%q`, redacted))
+}
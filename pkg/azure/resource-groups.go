@@ -0,0 +1,95 @@
+package azure
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ResourceGroupDetails are the properties of a resource group that are inherited by the resources in it
+type ResourceGroupDetails struct {
+	Name     string
+	Location string
+	Tags     map[string]*string
+
+	// Filtered is set when the resource group is filtered and the resources in it have to be filtered with it
+	Filtered bool
+}
+
+// ResourceGroupIndex holds the details of the resource groups of a tenant, keyed by subscription ID and name. Resource
+// group names are case-insensitive, so the lookup is as well.
+type ResourceGroupIndex struct {
+	lock   sync.RWMutex
+	groups map[string]*ResourceGroupDetails
+}
+
+func NewResourceGroupIndex() *ResourceGroupIndex {
+	return &ResourceGroupIndex{
+		groups: make(map[string]*ResourceGroupDetails),
+	}
+}
+
+// Add adds the resource group to the index, replacing the details of a resource group with the same name
+func (i *ResourceGroupIndex) Add(subscriptionID string, details *ResourceGroupDetails) {
+	if i == nil || details == nil {
+		return
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	i.groups[resourceGroupKey(subscriptionID, details.Name)] = details
+}
+
+// Get returns the details of the resource group, nil if it is not known
+func (i *ResourceGroupIndex) Get(subscriptionID, name string) *ResourceGroupDetails {
+	if i == nil || name == "" {
+		return nil
+	}
+
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	return i.groups[resourceGroupKey(subscriptionID, name)]
+}
+
+// All returns the details of every resource group of the subscription, sorted by name
+func (i *ResourceGroupIndex) All(subscriptionID string) []*ResourceGroupDetails {
+	if i == nil {
+		return nil
+	}
+
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	prefix := resourceGroupKey(subscriptionID, "")
+
+	var found []*ResourceGroupDetails
+	for key, details := range i.groups {
+		if strings.HasPrefix(key, prefix) {
+			found = append(found, details)
+		}
+	}
+
+	sort.Slice(found, func(a, b int) bool {
+		return found[a].Name < found[b].Name
+	})
+
+	return found
+}
+
+// Len returns the number of resource groups in the index
+func (i *ResourceGroupIndex) Len() int {
+	if i == nil {
+		return 0
+	}
+
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	return len(i.groups)
+}
+
+func resourceGroupKey(subscriptionID, name string) string {
+	return strings.ToLower(subscriptionID + "/" + name)
+}
@@ -1,15 +1,27 @@
 package azure
 
 import (
+	"context"
+	"fmt"
+	"reflect"
 	"regexp"
+	"strings"
+
+	"github.com/gotidy/ptr"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 
 	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/azure-nuke/pkg/terraform"
 )
 
 const (
-	TenantScope        registry.Scope = "tenant"
-	SubscriptionScope  registry.Scope = "subscription"
-	ResourceGroupScope registry.Scope = "resource-group"
+	TenantScope          registry.Scope = "tenant"
+	ManagementGroupScope registry.Scope = "management-group"
+	SubscriptionScope    registry.Scope = "subscription"
+	ResourceGroupScope   registry.Scope = "resource-group"
 )
 
 var (
@@ -17,13 +29,161 @@ var (
 )
 
 type ListerOpts struct {
-	Authorizers    *Authorizers
-	TenantID       string
-	SubscriptionID string
-	ResourceGroup  string
-	ResourceGroups []string
-	Region         string
-	Regions        []string
+	Authorizers       *Authorizers
+	TenantID          string
+	ManagementGroupID string
+	SubscriptionID    string
+	ResourceGroup     string
+	ResourceGroups    []string
+	Region            string
+	Regions           []string
+
+	// Inventory is the resource graph inventory of the tenant, it is nil when the resources are discovered by
+	// the listers themselves.
+	Inventory *Inventory
+
+	// Locks are the management locks of the tenant, they are only set for subscription and resource group scoped
+	// resources so that their removal can wait for the locks that block it.
+	Locks *Locks
+
+	// ResourceGroupDetails are the resource groups of the tenant, they are only set for subscription and resource group
+	// scoped resources so that the resources inherit the location and tags of their resource group.
+	ResourceGroupDetails *ResourceGroupIndex
+
+	// Quarantine is set when the resources are quarantined instead of removed right away
+	Quarantine *Quarantine
+
+	// Stop is set when the resources are stopped instead of removed, see the Stopper interface of the resources
+	Stop bool
+
+	// Listings are the subscription wide listings of the resource group scoped resource types of the tenant, they are
+	// only set for resource group scoped resources. See ListByResourceGroup.
+	Listings *Listings
+
+	// Terraform is the state of the resources that are managed by terraform, it is nil when no terraform state is
+	// configured
+	Terraform *terraform.State
+}
+
+// ResourceManagerEndpoint returns the base URI of the resource manager API for the configured environment, this is
+// what needs to be passed to the autorest based clients via their WithBaseURI constructors.
+func (o *ListerOpts) ResourceManagerEndpoint() string {
+	endpoint, ok := o.Authorizers.Environment.ResourceManager.Endpoint()
+	if !ok {
+		return ""
+	}
+
+	return *endpoint
+}
+
+// MicrosoftGraphEndpoint returns the base URI of the microsoft graph API for the configured environment.
+func (o *ListerOpts) MicrosoftGraphEndpoint() string {
+	endpoint, ok := o.Authorizers.Environment.MicrosoftGraph.Endpoint()
+	if !ok {
+		return ""
+	}
+
+	return *endpoint
+}
+
+// ARMClientOptions returns a new copy of the options for the azcore based resource manager clients. A copy is
+// returned so that the caller is free to modify it (i.e. to pin an API version).
+func (o *ListerOpts) ARMClientOptions() *arm.ClientOptions {
+	return &arm.ClientOptions{
+		ClientOptions: o.Authorizers.ClientOptions,
+	}
+}
+
+// ManagementGroupScopeID returns the resource ID of the management group being listed, this is the scope that
+// management group level resources (policy assignments, role assignments, etc.) are attached to.
+func (o *ListerOpts) ManagementGroupScopeID() string {
+	return fmt.Sprintf("/providers/Microsoft.Management/managementGroups/%s", o.ManagementGroupID)
+}
+
+// SkipByInventory checks if the inventory shows that none of the resource types (i.e. microsoft.compute/disks) exist
+// in the subscription or resource group being listed, in which case the lister can skip its own enumeration. This
+// always returns false when there is no inventory or when it is no longer recent, see Inventory.Recent.
+func (o *ListerOpts) SkipByInventory(resourceTypes ...string) bool {
+	if !o.Inventory.Recent() {
+		return false
+	}
+
+	if o.Inventory.Contains(o.SubscriptionID, o.ResourceGroup, resourceTypes...) {
+		return false
+	}
+
+	// Note: resource graph is eventually consistent, the skip is logged so that a missed resource can be explained
+	o.Logger(strings.Join(resourceTypes, ",")).
+		WithField("component", "inventory").
+		Info("skipping listing, the resource graph inventory has no resources of this type")
+
+	return true
+}
+
+// ListByResourceGroup returns the resources of the resource group being listed, these are picked from the
+// subscription wide listing of the resource type by the list function, which is shared by every resource group of the
+// subscription. Without listings the subscription is listed for the resource group alone.
+func (o *ListerOpts) ListByResourceGroup(ctx context.Context, resourceType string, list ListFunc) ([]resource.Resource, error) {
+	listings := o.Listings
+	if listings == nil {
+		listings = NewListings(map[string][]string{o.SubscriptionID: {o.ResourceGroup}})
+	}
+
+	return listings.ResourceGroup(ctx, o.SubscriptionID, o.ResourceGroup, resourceType, list)
+}
+
+// ListsResourceGroup checks if the resources of the resource group are listed, the list functions of
+// ListByResourceGroup use it to skip the child resources of the resources in other resource groups.
+func (o *ListerOpts) ListsResourceGroup(resourceGroup string) bool {
+	if o.Listings == nil {
+		return strings.EqualFold(resourceGroup, o.ResourceGroup)
+	}
+
+	return o.Listings.Includes(o.SubscriptionID, resourceGroup)
+}
+
+// ResourceGroupFromID returns the name of the resource group of the resource with the ID, the name of the resource
+// group itself is preferred as the casing of the name in a resource ID is not reliable
+func (o *ListerOpts) ResourceGroupFromID(id *string) *string {
+	if id == nil {
+		return nil
+	}
+
+	name := GetResourceGroupFromID(*id)
+	if name == nil {
+		return nil
+	}
+
+	if details := o.ResourceGroupDetails.Get(o.SubscriptionID, *name); details != nil {
+		return ptr.String(details.Name)
+	}
+
+	return name
+}
+
+// SystemData returns the systemData of the resource as it was returned when the resource was listed, nil if it is
+// not known. It has to be called once, with the context returned by WithSystemData that the resources were listed
+// with. Listers whose sdk models the systemData use NewSystemData instead.
+func (o *ListerOpts) SystemData(ctx context.Context, id *string) *SystemData {
+	if id == nil {
+		return nil
+	}
+
+	return systemDataFrom(ctx).take(*id)
+}
+
+// Tags converts the tags of the go-azure-sdk models to the representation used by the other sdks
+func Tags(tags *map[string]string) map[string]*string {
+	if tags == nil {
+		return nil
+	}
+
+	converted := make(map[string]*string, len(*tags))
+	for k, v := range *tags {
+		converted[k] = ptr.String(v)
+	}
+
+	return converted
 }
 
 func GetResourceGroupFromID(id string) *string {
@@ -34,3 +194,37 @@ func GetResourceGroupFromID(id string) *string {
 
 	return nil
 }
+
+// ResourceID returns the ARM ID or Graph object ID of the resource, this is read from the `ID` field of the resource
+// as not every resource exposes it as a property. An empty string is returned if the resource has no ID.
+func ResourceID(r interface{}) string {
+	v := reflect.ValueOf(r)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+
+	field := v.FieldByName("ID")
+	if !field.IsValid() {
+		return ""
+	}
+
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return ""
+		}
+		field = field.Elem()
+	}
+
+	if field.Kind() != reflect.String {
+		return ""
+	}
+
+	return field.String()
+}
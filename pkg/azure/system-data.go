@@ -0,0 +1,264 @@
+package azure
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SystemData is the creation and last modification metadata of a resource. Resource manager returns it as the
+// `systemData` of every resource, for entra objects only the creation time is known.
+type SystemData struct {
+	CreatedAt      *time.Time `description:"The time the resource was created."`
+	CreatedBy      *string    `description:"The identity that created the resource."`
+	CreatedByType  *string    `description:"The type of identity that created the resource (User, Application, ManagedIdentity or Key)."` //nolint:lll
+	LastModifiedAt *time.Time `description:"The time the resource was last modified."`
+	LastModifiedBy *string    `description:"The identity that last modified the resource."`
+}
+
+// maxSystemData is the number of resources of a listing the systemData is kept for until it is asked for, once
+// exceeded the systemData that was recorded first is dropped and a warning is logged
+const maxSystemData = 10000
+
+// NewSystemData converts the systemData of a resource as it was decoded by one of the sdks, i.e. the SystemData field
+// of the model. The sdks model it differently (times as strings, date.Time or time.Time, enums as values or pointers),
+// it is read by the names of its fields. It returns nil if the model has no systemData.
+func NewSystemData(model interface{}) *SystemData {
+	v := reflect.Indirect(reflect.ValueOf(model))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	data := &SystemData{
+		CreatedAt:      systemDataTime(v.FieldByName("CreatedAt")),
+		CreatedBy:      nonEmpty(systemDataString(v.FieldByName("CreatedBy"))),
+		CreatedByType:  nonEmpty(systemDataString(v.FieldByName("CreatedByType"))),
+		LastModifiedAt: systemDataTime(v.FieldByName("LastModifiedAt")),
+		LastModifiedBy: nonEmpty(systemDataString(v.FieldByName("LastModifiedBy"))),
+	}
+
+	if *data == (SystemData{}) {
+		return nil
+	}
+
+	return data
+}
+
+// systemDataString reads a string or string based enum of a decoded systemData, empty if it is not set
+func systemDataString(v reflect.Value) string {
+	v = reflect.Indirect(v)
+	if v.Kind() != reflect.String {
+		return ""
+	}
+
+	return v.String()
+}
+
+// systemDataTime reads a timestamp of a decoded systemData, either a time.Time, a type embedding it (date.Time) or a
+// string, nil if it is not set
+func systemDataTime(v reflect.Value) *time.Time {
+	v = reflect.Indirect(v)
+
+	switch {
+	case v.Kind() == reflect.String:
+		return parseSystemDataTime(v.String())
+	case v.Kind() != reflect.Struct:
+		return nil
+	case v.Type() != reflect.TypeOf(time.Time{}):
+		return systemDataTime(v.FieldByName("Time"))
+	}
+
+	t := v.Interface().(time.Time)
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+type systemDataKey struct{}
+
+// WithSystemData marks the requests sent with the context, the systemData in the responses to those requests is
+// recorded until it is asked for with ListerOpts.SystemData. Listers whose sdk does not model the systemData list
+// with this context, every call returns a context that records the systemData of its own listing.
+func WithSystemData(ctx context.Context) context.Context {
+	return context.WithValue(ctx, systemDataKey{}, newSystemDataRecorder())
+}
+
+// systemDataFrom returns the recorder of a context returned by WithSystemData, nil if there is none
+func systemDataFrom(ctx context.Context) *systemDataRecorder {
+	if ctx == nil {
+		return nil
+	}
+
+	recorder, _ := ctx.Value(systemDataKey{}).(*systemDataRecorder)
+	return recorder
+}
+
+// systemDataRecorder records the systemData of the resources in the responses of resource manager. Not every version
+// of the sdk models the systemData, so it is read from the responses of the clients instead, keyed by resource ID.
+// The systemData is kept until it is asked for, at most maxSystemData resources of the listing are kept.
+type systemDataRecorder struct {
+	lock      sync.Mutex
+	max       int
+	order     *list.List
+	resources map[string]*list.Element
+	evicted   int
+}
+
+type systemDataEntry struct {
+	id   string
+	data *SystemData
+}
+
+func newSystemDataRecorder() *systemDataRecorder {
+	return &systemDataRecorder{
+		max:       maxSystemData,
+		order:     list.New(),
+		resources: make(map[string]*list.Element),
+	}
+}
+
+// take returns the systemData of the resource and forgets it, nil if it is not known. This is safe to call on a nil
+// recorder.
+func (s *systemDataRecorder) take(id string) *SystemData {
+	if s == nil {
+		return nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	element, ok := s.resources[strings.ToLower(id)]
+	if !ok {
+		return nil
+	}
+
+	s.remove(element)
+
+	return element.Value.(*systemDataEntry).data
+}
+
+// len returns the number of resources the systemData is kept for
+func (s *systemDataRecorder) len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return len(s.resources)
+}
+
+// add keeps the systemData of the resource, the caller has to hold the lock
+func (s *systemDataRecorder) add(id string, data *SystemData) {
+	if element, ok := s.resources[id]; ok {
+		s.remove(element)
+	}
+
+	for s.order.Len() >= s.max {
+		// Note: the warning is logged once per listing, the resources that lost their systemData have no creation time
+		if s.evicted == 0 {
+			logrus.WithField("component", "system-data").
+				Warnf("more than %d resources are waiting for their systemData, the systemData of the first ones is dropped",
+					s.max)
+		}
+
+		s.evicted++
+		s.remove(s.order.Front())
+	}
+
+	s.resources[id] = s.order.PushBack(&systemDataEntry{id: id, data: data})
+}
+
+// remove forgets the systemData of an element, the caller has to hold the lock
+func (s *systemDataRecorder) remove(element *list.Element) {
+	s.order.Remove(element)
+	delete(s.resources, element.Value.(*systemDataEntry).id)
+}
+
+// recordSystemData reads the systemData of a single resource or a page of resources from the response to a request sent with a
+// context returned by WithSystemData, the body of the response is replaced so that the client can still read it
+func recordSystemData(req *http.Request, resp *http.Response) {
+	if req == nil {
+		return
+	}
+
+	s := systemDataFrom(req.Context())
+	if s == nil || resp == nil || resp.Body == nil || resp.StatusCode != http.StatusOK ||
+		!strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		return
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil || !bytes.Contains(body, []byte(`"systemData"`)) {
+		return
+	}
+
+	var page struct {
+		systemDataResource
+		Value []systemDataResource `json:"value"`
+	}
+	if err := json.Unmarshal(body, &page); err != nil {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, r := range append(page.Value, page.systemDataResource) {
+		if r.ID == "" || r.SystemData == nil {
+			continue
+		}
+
+		s.add(strings.ToLower(r.ID), &SystemData{
+			CreatedAt:      parseSystemDataTime(r.SystemData.CreatedAt),
+			CreatedBy:      nonEmpty(r.SystemData.CreatedBy),
+			CreatedByType:  nonEmpty(r.SystemData.CreatedByType),
+			LastModifiedAt: parseSystemDataTime(r.SystemData.LastModifiedAt),
+			LastModifiedBy: nonEmpty(r.SystemData.LastModifiedBy),
+		})
+	}
+}
+
+type systemDataResource struct {
+	ID         string `json:"id"`
+	SystemData *struct {
+		CreatedAt      string `json:"createdAt"`
+		CreatedBy      string `json:"createdBy"`
+		CreatedByType  string `json:"createdByType"`
+		LastModifiedAt string `json:"lastModifiedAt"`
+		LastModifiedBy string `json:"lastModifiedBy"`
+	} `json:"systemData"`
+}
+
+// parseSystemDataTime parses the timestamps of the systemData, some services omit the timezone which is always UTC
+func parseSystemDataTime(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+
+	return nil
+}
+
+func nonEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
@@ -0,0 +1,136 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+)
+
+const systemDataPage = `{"value":[
+	{"id":"/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/disks/a",
+	 "systemData":{"createdAt":"2024-01-02T03:04:05Z","createdBy":"someone@example.com","createdByType":"User"}},
+	{"id":"/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/disks/b",
+	 "systemData":{"createdAt":"2024-02-03T04:05:06.123","lastModifiedBy":"app-id"}}
+]}`
+
+func newSystemDataServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = fmt.Fprint(w, systemDataPage)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func getWithContext(ctx context.Context, t *testing.T, p *Pipeline, url string) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	assert.NoError(t, err)
+
+	resp, err := p.HTTPClient().Do(req)
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+}
+
+func TestSystemDataIsRecordedForMarkedRequests(t *testing.T) {
+	server := newSystemDataServer(t)
+
+	p := NewPipeline(nil)
+	opts := &ListerOpts{}
+
+	unmarked := context.TODO()
+	getWithContext(unmarked, t, p, server.URL)
+	assert.Nil(t, opts.SystemData(unmarked, ptr.String("/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/disks/a")))
+
+	ctx := WithSystemData(context.TODO())
+	other := WithSystemData(context.TODO())
+	getWithContext(ctx, t, p, server.URL)
+	assert.Equal(t, 2, systemDataFrom(ctx).len())
+
+	// Note: every listing records its own systemData
+	assert.Equal(t, 0, systemDataFrom(other).len())
+
+	data := opts.SystemData(ctx, ptr.String("/SUBSCRIPTIONS/sub/resourceGroups/rg/providers/Microsoft.Compute/disks/a"))
+	if assert.NotNil(t, data) {
+		assert.Equal(t, "2024-01-02T03:04:05Z", data.CreatedAt.Format("2006-01-02T15:04:05Z07:00"))
+		assert.Equal(t, "someone@example.com", *data.CreatedBy)
+		assert.Equal(t, "User", *data.CreatedByType)
+		assert.Nil(t, data.LastModifiedAt)
+	}
+
+	// Note: the systemData is released once it has been asked for
+	assert.Nil(t, opts.SystemData(ctx, ptr.String("/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/disks/a")))
+	assert.Equal(t, 1, systemDataFrom(ctx).len())
+	assert.Nil(t, opts.SystemData(ctx, nil))
+}
+
+func TestSystemDataIsReadFromTheModel(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	type createdByType string
+
+	cases := map[string]interface{}{
+		"strings": &struct {
+			CreatedAt     string
+			CreatedBy     string
+			CreatedByType string
+		}{CreatedAt: "2024-01-02T03:04:05Z", CreatedBy: "someone@example.com", CreatedByType: "User"},
+		"embedded time": &struct {
+			CreatedAt     *struct{ time.Time }
+			CreatedBy     *string
+			CreatedByType createdByType
+		}{CreatedAt: &struct{ time.Time }{created}, CreatedBy: ptr.String("someone@example.com"), CreatedByType: "User"},
+		"time": struct {
+			CreatedAt     *time.Time
+			CreatedBy     *string
+			CreatedByType *createdByType
+		}{CreatedAt: &created, CreatedBy: ptr.String("someone@example.com"), CreatedByType: ptr.Of(createdByType("User"))},
+	}
+
+	for name, model := range cases {
+		data := NewSystemData(model)
+		if assert.NotNil(t, data, name) {
+			assert.True(t, created.Equal(*data.CreatedAt), name)
+			assert.Equal(t, "someone@example.com", ptr.ToString(data.CreatedBy), name)
+			assert.Equal(t, "User", ptr.ToString(data.CreatedByType), name)
+			assert.Nil(t, data.LastModifiedAt, name)
+			assert.Nil(t, data.LastModifiedBy, name)
+		}
+	}
+
+	var missing *struct{ CreatedBy *string }
+	assert.Nil(t, NewSystemData(missing))
+	assert.Nil(t, NewSystemData(&struct{ CreatedBy *string }{}))
+	assert.Nil(t, NewSystemData(nil))
+}
+
+func TestSystemDataRecorderIsBounded(t *testing.T) {
+	s := newSystemDataRecorder()
+	s.max = 2
+
+	s.add("a", &SystemData{})
+	s.add("b", &SystemData{})
+	s.add("a", &SystemData{})
+	s.add("c", &SystemData{})
+
+	// Note: recording a resource again makes it the most recent one, so b is dropped instead of a
+	assert.Equal(t, 2, s.len())
+	assert.NotNil(t, s.take("a"))
+	assert.Nil(t, s.take("b"))
+	assert.NotNil(t, s.take("c"))
+	assert.Equal(t, 0, s.len())
+	assert.Equal(t, 1, s.evicted)
+
+	var missing *systemDataRecorder
+	assert.Nil(t, missing.take("a"))
+}
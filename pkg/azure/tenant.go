@@ -10,6 +10,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"       //nolint:staticcheck
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2021-01-01/subscriptions"   //nolint:staticcheck
 	"github.com/Azure/azure-sdk-for-go/services/subscription/mgmt/2020-09-01/subscription" //nolint:staticcheck
 )
 
@@ -22,11 +23,38 @@ type Tenant struct {
 
 	Regions        map[string][]string
 	ResourceGroups map[string][]string
+
+	// ManagementGroups is the management group hierarchy of the tenant, if management groups were selected via
+	// configuration, this only contains the selected management groups and their descendants.
+	ManagementGroups ManagementGroups
+
+	// Inventory is the resource graph inventory of the subscriptions, it is nil unless the resource graph discovery
+	// was requested and the inventory could be built.
+	Inventory *Inventory
+
+	// Locks are the management locks of the subscriptions, they are discovered before scanning.
+	Locks *Locks
+
+	// ResourceGroupDetails are the location and tags of every resource group of the subscriptions, regardless of the
+	// configured regions, they are inherited by the resources in the resource groups.
+	ResourceGroupDetails *ResourceGroupIndex
+
+	// Listings are the subscription wide listings of the resource group scoped resource types, they are shared by the
+	// scanners of the resource groups.
+	Listings *Listings
+}
+
+// SubscriptionFilter decides which subscriptions of a tenant are allowed to be nuked, it is implemented by the
+// configuration via the subscription blocklist and allowlist.
+type SubscriptionFilter interface {
+	InSubscriptionBlocklist(id, name string, tags map[string]string) (bool, error)
+	InSubscriptionAllowlist(id, name string, tags map[string]string) (bool, error)
 }
 
-func NewTenant( //nolint:gocyclo
+func NewTenant( //nolint:gocyclo,funlen
 	pctx context.Context, authorizers *Authorizers,
-	tenantID string, subscriptionIDs, regions []string,
+	tenantID string, subscriptionIDs, managementGroups, regions []string, subscriptionFilter SubscriptionFilter,
+	discovery string,
 ) (*Tenant, error) {
 	ctx, cancel := context.WithTimeout(pctx, time.Second*15)
 	defer cancel()
@@ -34,6 +62,10 @@ func NewTenant( //nolint:gocyclo
 	log := logrus.WithField("handler", "NewTenant")
 	log.Trace("start: NewTenant")
 
+	if authorizers.Identity != nil {
+		log.Infof("authenticated as %s, its resources are protected from removal", authorizers.Identity)
+	}
+
 	tenant := &Tenant{
 		Authorizers:     authorizers,
 		ID:              tenantID,
@@ -41,10 +73,19 @@ func NewTenant( //nolint:gocyclo
 		SubscriptionIds: make([]string, 0),
 		Regions:         make(map[string][]string),
 		ResourceGroups:  make(map[string][]string),
+		Locks:           NewLocks(),
+
+		ResourceGroupDetails: NewResourceGroupIndex(),
 	}
 
-	tenantClient := subscription.NewTenantsClient()
+	endpoint, ok := authorizers.Environment.ResourceManager.Endpoint()
+	if !ok {
+		return nil, fmt.Errorf("environment %s has no resource manager endpoint", authorizers.Environment.Name)
+	}
+
+	tenantClient := subscription.NewTenantsClientWithBaseURI(*endpoint)
 	tenantClient.Authorizer = authorizers.Management
+	authorizers.Pipeline.ConfigureAutorest(&tenantClient.Client)
 
 	log.Trace("attempting to list tenants")
 	for list, err := tenantClient.List(ctx); list.NotDone(); err = list.NextWithContext(ctx) {
@@ -57,8 +98,33 @@ func NewTenant( //nolint:gocyclo
 		}
 	}
 
-	client := subscription.NewSubscriptionsClient()
+	log.Trace("listing management groups")
+	var err error
+	tenant.ManagementGroups, err = ListManagementGroups(ctx, authorizers, tenantID)
+	if err != nil {
+		// Note: reading the management group hierarchy requires permissions that might not have been granted, this
+		// is only fatal if the configuration is relying on management groups to select subscriptions.
+		if len(managementGroups) > 0 {
+			return nil, fmt.Errorf("unable to list management groups: %w", err)
+		}
+
+		log.WithError(err).Warn("unable to list management groups, skipping management group scope")
+		tenant.ManagementGroups = ManagementGroups{}
+	}
+
+	var managementGroupSubscriptionIDs []string
+	if len(managementGroups) > 0 {
+		tenant.ManagementGroups, err = tenant.ManagementGroups.Select(managementGroups)
+		if err != nil {
+			return nil, err
+		}
+
+		managementGroupSubscriptionIDs = tenant.ManagementGroups.SubscriptionIDs()
+	}
+
+	client := subscriptions.NewClientWithBaseURI(*endpoint)
 	client.Authorizer = authorizers.Management
+	authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	log.Trace("listing subscriptions")
 	for list, err := client.List(ctx); list.NotDone(); err = list.NextWithContext(ctx) {
@@ -67,34 +133,92 @@ func NewTenant( //nolint:gocyclo
 		}
 		for _, s := range list.Values() {
 			slog := log.WithField("subscription_id", *s.SubscriptionID)
-			if len(subscriptionIDs) > 0 && !slices.Contains(subscriptionIDs, *s.SubscriptionID) {
+			requested := slices.Contains(subscriptionIDs, *s.SubscriptionID)
+			if len(subscriptionIDs) > 0 && !requested {
 				slog.Warnf("skipping subscription id: %s (reason: not requested)", *s.SubscriptionID)
 				continue
 			}
+			if subscriptionFilter != nil {
+				name := ptr.ToString(s.DisplayName)
+				tags := subscriptionTags(s.Tags)
 
-			slog.Trace("adding subscription")
-			tenant.SubscriptionIds = append(tenant.SubscriptionIds, *s.SubscriptionID)
+				blocklisted, err := subscriptionFilter.InSubscriptionBlocklist(*s.SubscriptionID, name, tags)
+				if err != nil {
+					return nil, err
+				}
+				if blocklisted {
+					if requested {
+						return nil, fmt.Errorf("subscription %s (%s) is blocklisted", *s.SubscriptionID, name)
+					}
 
-			slog.Trace("listing resource groups")
-			groupsClient := resources.NewGroupsClient(*s.SubscriptionID)
-			groupsClient.Authorizer = authorizers.Management
+					slog.Warnf("skipping subscription id: %s (reason: blocklisted)", *s.SubscriptionID)
+					continue
+				}
 
-			slog.Debugf("configured regions: %v", regions)
-			for list, err := groupsClient.List(ctx, "", nil); list.NotDone(); err = list.NextWithContext(ctx) {
+				allowed, err := subscriptionFilter.InSubscriptionAllowlist(*s.SubscriptionID, name, tags)
 				if err != nil {
 					return nil, err
 				}
-
-				for _, g := range list.Values() {
-					// If the region isn't in the list of regions we want to include, skip it
-					if !slices.Contains(regions, ptr.ToString(g.Location)) && !slices.Contains(regions, "all") {
-						continue
+				if !allowed {
+					if requested {
+						return nil, fmt.Errorf("subscription %s (%s) is not in the subscription allowlist", *s.SubscriptionID, name)
 					}
 
-					slog.Debugf("resource group name: %s", *g.Name)
-					tenant.ResourceGroups[*s.SubscriptionID] = append(tenant.ResourceGroups[*s.SubscriptionID], *g.Name)
+					slog.Warnf("skipping subscription id: %s (reason: not in allowlist)", *s.SubscriptionID)
+					continue
 				}
 			}
+			if len(managementGroups) > 0 && !slices.Contains(managementGroupSubscriptionIDs, *s.SubscriptionID) {
+				slog.Warnf("skipping subscription id: %s (reason: not in selected management groups)", *s.SubscriptionID)
+				continue
+			}
+
+			slog.Trace("adding subscription")
+			tenant.SubscriptionIds = append(tenant.SubscriptionIds, *s.SubscriptionID)
+		}
+	}
+
+	if discovery == DiscoveryResourceGraph {
+		// Note: the inventory is built with the parent context as it has a much longer timeout of its own
+		log.Trace("building resource graph inventory")
+		tenant.Inventory, err = NewResourceGraphInventory(pctx, authorizers, tenant.SubscriptionIds)
+		if err != nil {
+			log.WithError(err).Warn("unable to build resource graph inventory, falling back to listing per resource group")
+			tenant.Inventory = nil
+		}
+	}
+
+	for _, subscriptionID := range tenant.SubscriptionIds {
+		slog := log.WithField("subscription_id", subscriptionID)
+		slog.Debugf("configured regions: %v", regions)
+
+		// Note: the resource groups are listed even with an inventory, resource graph is eventually consistent and
+		// the resources of a resource group that is missing from it would never be scanned
+		slog.Trace("listing resource groups")
+		groupsClient := resources.NewGroupsClientWithBaseURI(*endpoint, subscriptionID)
+		groupsClient.Authorizer = authorizers.Management
+		authorizers.Pipeline.ConfigureAutorest(&groupsClient.Client)
+
+		for list, err := groupsClient.List(ctx, "", nil); list.NotDone(); err = list.NextWithContext(ctx) {
+			if err != nil {
+				return nil, err
+			}
+
+			for _, g := range list.Values() {
+				tenant.ResourceGroupDetails.Add(subscriptionID, &ResourceGroupDetails{
+					Name:     ptr.ToString(g.Name),
+					Location: ptr.ToString(g.Location),
+					Tags:     g.Tags,
+				})
+
+				// If the region isn't in the list of regions we want to include, skip it
+				if !slices.Contains(regions, ptr.ToString(g.Location)) && !slices.Contains(regions, "all") {
+					continue
+				}
+
+				slog.Debugf("resource group name: %s", *g.Name)
+				tenant.ResourceGroups[subscriptionID] = append(tenant.ResourceGroups[subscriptionID], *g.Name)
+			}
 		}
 	}
 
@@ -106,5 +230,16 @@ func NewTenant( //nolint:gocyclo
 		return nil, fmt.Errorf("tenant ids do not match")
 	}
 
+	tenant.Listings = NewListings(tenant.ResourceGroups)
+
 	return tenant, nil
 }
+
+func subscriptionTags(tags map[string]*string) map[string]string {
+	out := make(map[string]string, len(tags))
+	for k, v := range tags {
+		out[k] = ptr.ToString(v)
+	}
+
+	return out
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/hashicorp/go-azure-sdk/sdk/auth"
 	"github.com/hashicorp/go-azure-sdk/sdk/auth/autorest"
+	"github.com/hashicorp/go-azure-sdk/sdk/environments"
 )
 
 type Authorizers struct {
@@ -14,4 +15,19 @@ type Authorizers struct {
 	ResourceManager auth.Authorizer
 
 	IdentityCreds azcore.TokenCredential
+
+	// Environment is the resolved azure environment (public, usgovernment, china, etc.), all clients must be built
+	// using the endpoints from this environment instead of the public cloud defaults.
+	Environment *environments.Environment
+
+	// ClientOptions are the options used by the azcore based clients, the Cloud configuration is derived from the
+	// Environment so that the azcore clients talk to the same cloud as the autorest and go-azure-sdk clients.
+	ClientOptions azcore.ClientOptions
+
+	// Pipeline is the http layer that handles rate limiting and throttling for all clients, the azcore based clients
+	// use it via the ClientOptions, every other client has to be configured with it explicitly.
+	Pipeline *Pipeline
+
+	// Identity is the identity the authorizers authenticate as, resources that belong to it are never removed
+	Identity *Identity
 }
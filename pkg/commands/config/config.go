@@ -0,0 +1,108 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	libconfig "github.com/ekristen/libnuke/pkg/config"
+	"github.com/ekristen/libnuke/pkg/registry"
+
+	"github.com/ekristen/azure-nuke/pkg/commands/global"
+	"github.com/ekristen/azure-nuke/pkg/commands/run"
+	"github.com/ekristen/azure-nuke/pkg/common"
+	nukeconfig "github.com/ekristen/azure-nuke/pkg/config"
+	_ "github.com/ekristen/azure-nuke/resources"
+)
+
+func executeValidate(c *cli.Context) error {
+	parsedConfig, err := nukeconfig.New(libconfig.Options{
+		Path:         c.Path("config"),
+		Deprecations: registry.GetDeprecatedResourceTypeMapping(),
+	})
+	if err != nil {
+		return err
+	}
+
+	problems := parsedConfig.Validate()
+
+	for _, problem := range problems {
+		severity := color.New(color.FgYellow)
+		if problem.Severity == nukeconfig.SeverityError {
+			severity = color.New(color.FgRed)
+		}
+
+		_, _ = severity.Printf("%-8s", problem.Severity)
+		_, _ = color.New(color.Bold).Printf("%s", problem.Path)
+		fmt.Printf(": %s\n", problem.Message)
+	}
+
+	if problems.HasErrors() || (c.Bool("strict") && len(problems) > 0) {
+		return fmt.Errorf("config %s is invalid, %d problem(s) found", c.Path("config"), len(problems))
+	}
+
+	fmt.Printf("Config %s is valid.\n", c.Path("config"))
+
+	return nil
+}
+
+func executeSchema(c *cli.Context) error {
+	data, err := json.MarshalIndent(nukeconfig.Schema(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	data = append(data, '\n')
+
+	if c.Path("output") == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	return os.WriteFile(c.Path("output"), data, 0600)
+}
+
+func init() {
+	cmd := &cli.Command{
+		Name:  "config",
+		Usage: "validate the config, generate its json schema or snapshot the resources that exist now",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "validate",
+				Usage: "check the config for unknown resource types, properties, presets, regions and settings",
+				Flags: append([]cli.Flag{
+					&cli.PathFlag{
+						Name:  "config",
+						Usage: "path to config file",
+						Value: "config.yaml",
+					},
+					&cli.BoolFlag{
+						Name:  "strict",
+						Usage: "treat warnings as errors",
+					},
+				}, global.Flags()...),
+				Before: global.Before,
+				Action: executeValidate,
+			},
+			{
+				Name:  "schema",
+				Usage: "generate a json schema of the config for editor autocompletion",
+				Flags: append([]cli.Flag{
+					&cli.PathFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "write the schema to a file instead of stdout",
+					},
+				}, global.Flags()...),
+				Before: global.Before,
+				Action: executeSchema,
+			},
+			run.NewSnapshotCommand(),
+		},
+	}
+
+	common.RegisterCommand(cmd)
+}
@@ -2,13 +2,29 @@ package global
 
 import (
 	"fmt"
+	"os"
 	"path"
 	"runtime"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 )
 
+const (
+	// LogFormatText is the human-readable format, it is colored when the output is a terminal
+	LogFormatText = "text"
+	// LogFormatJSON writes every entry as a single line JSON object
+	LogFormatJSON = "json"
+	// LogFormatLogfmt writes every entry as a single line of key=value pairs
+	LogFormatLogfmt = "logfmt"
+)
+
+// LogFormats are the supported log formats
+var LogFormats = []string{LogFormatText, LogFormatJSON, LogFormatLogfmt}
+
 func Flags() []cli.Flag {
 	globalFlags := []cli.Flag{
 		&cli.StringFlag{
@@ -18,6 +34,17 @@ func Flags() []cli.Flag {
 			EnvVars: []string{"LOGLEVEL"},
 			Value:   "info",
 		},
+		&cli.StringFlag{
+			Name:    "log-format",
+			Usage:   "the format of the log (" + strings.Join(LogFormats, ", ") + ")",
+			EnvVars: []string{"LOGFORMAT"},
+			Value:   LogFormatText,
+		},
+		&cli.PathFlag{
+			Name:    "log-file",
+			Usage:   "append the log to this file instead of writing it to the console",
+			EnvVars: []string{"LOGFILE"},
+		},
 		&cli.BoolFlag{
 			Name:  "log-caller",
 			Usage: "log the caller (aka line number and file)",
@@ -36,19 +63,47 @@ func Flags() []cli.Flag {
 }
 
 func Before(c *cli.Context) error {
-	formatter := &logrus.TextFormatter{
-		DisableColors: c.Bool("log-disable-color"),
-		FullTimestamp: c.Bool("log-full-timestamp"),
+	if !slices.Contains(LogFormats, c.String("log-format")) {
+		return fmt.Errorf("unsupported log format: %s", c.String("log-format"))
 	}
+
+	var callerPrettyfier func(f *runtime.Frame) (string, string)
 	if c.Bool("log-caller") {
 		logrus.SetReportCaller(true)
 
-		formatter.CallerPrettyfier = func(f *runtime.Frame) (string, string) {
+		callerPrettyfier = func(f *runtime.Frame) (string, string) {
 			return "", fmt.Sprintf("%s:%d", path.Base(f.File), f.Line)
 		}
 	}
 
-	logrus.SetFormatter(formatter)
+	switch c.String("log-format") {
+	case LogFormatJSON:
+		logrus.SetFormatter(&logrus.JSONFormatter{
+			CallerPrettyfier: callerPrettyfier,
+		})
+	case LogFormatLogfmt:
+		logrus.SetFormatter(&logrus.TextFormatter{
+			DisableColors:    true,
+			FullTimestamp:    true,
+			QuoteEmptyFields: true,
+			CallerPrettyfier: callerPrettyfier,
+		})
+	default:
+		logrus.SetFormatter(&logrus.TextFormatter{
+			DisableColors:    c.Bool("log-disable-color"),
+			FullTimestamp:    c.Bool("log-full-timestamp"),
+			CallerPrettyfier: callerPrettyfier,
+		})
+	}
+
+	if c.Path("log-file") != "" {
+		f, err := OpenLogFile(c.Path("log-file"))
+		if err != nil {
+			return err
+		}
+
+		logrus.SetOutput(f)
+	}
 
 	switch c.String("log-level") {
 	case "trace":
@@ -65,3 +120,77 @@ func Before(c *cli.Context) error {
 
 	return nil
 }
+
+// StructuredLogging returns true if the log is written in a machine-readable format
+func StructuredLogging(c *cli.Context) bool {
+	return c.String("log-format") == LogFormatJSON || c.String("log-format") == LogFormatLogfmt
+}
+
+// LogFile appends to the file at its path. The file is opened again when it has been moved or removed since it was
+// opened, i.e. by logrotate, so that nothing is written to a file that has been rotated.
+type LogFile struct {
+	path string
+
+	lock sync.Mutex
+	file *os.File
+}
+
+// OpenLogFile opens the file at the path for appending, it is created if it does not exist
+func OpenLogFile(path string) (*LogFile, error) {
+	f := &LogFile{path: path}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Write implements io.Writer
+func (f *LogFile) Write(p []byte) (int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.rotated() {
+		_ = f.file.Close()
+
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	return f.file.Write(p)
+}
+
+// Close closes the file
+func (f *LogFile) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.file.Close()
+}
+
+func (f *LogFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	f.file = file
+
+	return nil
+}
+
+// rotated returns true if the path no longer refers to the open file
+func (f *LogFile) rotated() bool {
+	current, err := os.Stat(f.path)
+	if err != nil {
+		return true
+	}
+
+	opened, err := f.file.Stat()
+	if err != nil {
+		return true
+	}
+
+	return !os.SameFile(current, opened)
+}
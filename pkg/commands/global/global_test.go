@@ -0,0 +1,59 @@
+package global
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogFileAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "azure-nuke.log")
+	assert.NoError(t, os.WriteFile(path, []byte("existing\n"), 0600))
+
+	f, err := OpenLogFile(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	_, err = f.Write([]byte("first\n"))
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "existing\nfirst\n", string(data))
+}
+
+func TestLogFileReopensRotatedFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "azure-nuke.log")
+
+	f, err := OpenLogFile(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	_, err = f.Write([]byte("before\n"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.Rename(path, path+".1"))
+
+	_, err = f.Write([]byte("after\n"))
+	assert.NoError(t, err)
+
+	rotated, err := os.ReadFile(path + ".1")
+	assert.NoError(t, err)
+	assert.Equal(t, "before\n", string(rotated))
+
+	current, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "after\n", string(current))
+
+	assert.NoError(t, os.Remove(path))
+
+	_, err = f.Write([]byte("removed\n"))
+	assert.NoError(t, err)
+
+	current, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "removed\n", string(current))
+}
@@ -31,6 +31,8 @@ func execute(c *cli.Context) error {
 			c := color.FgGreen
 			if reg.Scope == azure.TenantScope {
 				c = color.FgHiGreen
+			} else if reg.Scope == azure.ManagementGroupScope {
+				c = color.FgHiCyan
 			} else if reg.Scope == azure.SubscriptionScope {
 				c = color.FgHiBlue
 			} else if reg.Scope == azure.ResourceGroupScope {
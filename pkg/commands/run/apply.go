@@ -0,0 +1,314 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	libconfig "github.com/ekristen/libnuke/pkg/config"
+	"github.com/ekristen/libnuke/pkg/filter"
+	libnuke "github.com/ekristen/libnuke/pkg/nuke"
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+	"github.com/ekristen/azure-nuke/pkg/commands/global"
+	"github.com/ekristen/azure-nuke/pkg/common"
+	"github.com/ekristen/azure-nuke/pkg/config"
+	"github.com/ekristen/azure-nuke/pkg/metrics"
+	"github.com/ekristen/azure-nuke/pkg/plan"
+	"github.com/ekristen/azure-nuke/pkg/report"
+	"github.com/ekristen/azure-nuke/pkg/terraform"
+	"github.com/ekristen/azure-nuke/resources"
+)
+
+// Note: the apply command lives alongside the run command as it shares the authentication and the processing of the
+// queue, the difference is that the queue is populated from a plan instead of from the scanners.
+
+func executeApply(c *cli.Context) (err error) {
+	ctx, cancel := context.WithCancel(c.Context)
+	defer cancel()
+
+	setupLogging(c, os.Stdout)
+
+	params := &libnuke.Parameters{
+		Force:      c.Bool("force"),
+		ForceSleep: c.Int("force-sleep"),
+		Quiet:      c.Bool("quiet"),
+		NoDryRun:   true,
+
+		MaxWaitRetries: c.Int("max-wait-retries"),
+	}
+
+	met, err := newMetrics(c, false)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = finishMetrics(c, met, err)
+	}()
+
+	parsedConfig, err := config.New(libconfig.Options{
+		Path:         c.Path("config"),
+		Deprecations: registry.GetDeprecatedResourceTypeMapping(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.Path("report") != "" && !slices.Contains(report.Formats, c.String("report-format")) {
+		return fmt.Errorf("unsupported report format: %s", c.String("report-format"))
+	}
+
+	p, err := plan.Load(c.Path("plan"))
+	if err != nil {
+		return err
+	}
+
+	tfState, err := loadTerraformState(c, parsedConfig)
+	if err != nil {
+		return err
+	}
+
+	if len(p.Items) == 0 {
+		fmt.Println("No resource to delete.")
+		return nil
+	}
+
+	rpt := report.New(false)
+
+	m := &multiTenantNuke{
+		params:  params,
+		version: fmt.Sprintf("> %s", common.AppVersion.String()),
+		states:  newQueueObserver(global.StructuredLogging(c), met),
+	}
+
+	for _, tenantID := range p.TenantIDs() {
+		if parsedConfig.InBlocklist(tenantID) {
+			return fmt.Errorf("tenant %s is blocklisted", tenantID)
+		}
+
+		tn, err := newPlanTenantNuke(ctx, c, params, parsedConfig, tenantID, p, tfState, met)
+		if err != nil {
+			return fmt.Errorf("tenant %s: %w", tenantID, err)
+		}
+
+		m.tenants = append(m.tenants, tn)
+	}
+
+	runErr := m.Apply(ctx)
+	logThrottlingMetrics(m.tenants...)
+
+	return writeReport(c, rpt, runErr, m.tenants...)
+}
+
+// newPlanTenantNuke configures a nuke instance for a tenant whose queue is populated from the items of the plan.
+// Every item is fetched again, any item that no longer exists is skipped and any item whose properties changed since
+// the plan was written is refused.
+func newPlanTenantNuke( //nolint:funlen
+	ctx context.Context, c *cli.Context, params *libnuke.Parameters,
+	parsedConfig *config.Config, tenantID string, p *plan.Plan, tfState *terraform.State, met *metrics.Metrics,
+) (*tenantNuke, error) {
+	logger := logrus.StandardLogger()
+
+	authorizers, err := authenticate(ctx, c, parsedConfig, tenantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	observeRequests(authorizers, met)
+
+	var items []*plan.Item
+	var subscriptionIDs []string
+	for _, item := range p.Items {
+		if item.TenantID != tenantID {
+			continue
+		}
+
+		items = append(items, item)
+
+		if item.SubscriptionID != "" && !slices.Contains(subscriptionIDs, item.SubscriptionID) {
+			subscriptionIDs = append(subscriptionIDs, item.SubscriptionID)
+		}
+	}
+
+	// Note: this enforces the subscription blocklist and allowlist, it fails if a subscription of the plan has
+	// been blocklisted since the plan was written
+	tenant, err := azure.NewTenant(ctx,
+		authorizers, tenantID, subscriptionIDs,
+		parsedConfig.GetManagementGroups(tenantID), parsedConfig.Regions, parsedConfig, azure.DiscoveryARM)
+	if err != nil {
+		return nil, err
+	}
+
+	// Note: only the locks that are part of the plan are removed, the resources under any other lock are kept
+	discoverLocks(ctx, tenant, func(lockItem *queue.Item) bool {
+		return !slices.ContainsFunc(items, func(item *plan.Item) bool {
+			return item.Type == resources.ManagementLockResource && item.Matches(lockItem.Resource)
+		})
+	})
+
+	n := libnuke.New(params, filter.Filters{}, parsedConfig.Settings)
+
+	n.SetRunSleep(5 * time.Second)
+	n.SetLogger(logger.WithField("component", "nuke"))
+
+	listed := make(map[string][]resource.Resource)
+
+	for _, item := range items {
+		log := logger.
+			WithField("component", "apply").
+			WithField("resource_type", item.Type).
+			WithField("name", item.Name)
+
+		if item.SubscriptionID != "" && !slices.Contains(tenant.SubscriptionIds, item.SubscriptionID) {
+			return nil, fmt.Errorf("subscription %s of %s %s is not available", item.SubscriptionID, item.Type, item.Name)
+		}
+
+		lister := registry.GetLister(item.Type)
+		if lister == nil {
+			return nil, fmt.Errorf("unknown resource type in plan: %s", item.Type)
+		}
+
+		opts := item.ListerOpts(authorizers)
+		opts.Locks = tenant.Locks
+		opts.ResourceGroupDetails = tenant.ResourceGroupDetails
+		opts.Listings = tenant.Listings
+		opts.Terraform = tfState
+
+		// Note: resources are listed once per type and scope, not once per item
+		key := strings.Join([]string{item.Type, item.ManagementGroupID, item.SubscriptionID, item.ResourceGroup}, "/")
+		if _, ok := listed[key]; !ok {
+			found, err := lister.List(ctx, opts)
+			if err != nil {
+				return nil, fmt.Errorf("unable to list %s: %w", item.Type, err)
+			}
+
+			listed[key] = found
+		}
+
+		var current resource.Resource
+		for _, r := range listed[key] {
+			if item.Matches(r) {
+				current = r
+				break
+			}
+		}
+
+		if current == nil {
+			log.Warn("skipping resource (reason: no longer exists)")
+			continue
+		}
+
+		queueItem, err := newPlanQueueItem(n, item, current, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		n.Queue.Items = append(n.Queue.Items, queueItem)
+	}
+
+	return &tenantNuke{
+		tenant: tenant,
+		nuke:   n,
+	}, nil
+}
+
+// newPlanQueueItem creates the queue item of the current state of a resource of the plan. The resource goes through
+// the same hooks and resource filters as during a run, so that the resources the nuke depends on are never removed,
+// even if they are part of the plan, i.e. when the plan was written by another identity. The filters of the
+// configuration are not applied, the plan is what was reviewed.
+func newPlanQueueItem(
+	n *libnuke.Nuke, item *plan.Item, current resource.Resource, opts *azure.ListerOpts,
+) (*queue.Item, error) {
+	queueItem := &queue.Item{
+		Resource: current,
+		State:    queue.ItemStateNew,
+		Type:     item.Type,
+		Owner:    item.Region,
+		Opts:     opts,
+	}
+
+	if hook, ok := current.(resource.QueueItemHook); ok {
+		hook.BeforeEnqueue(queueItem)
+	}
+
+	if sGetter, ok := current.(resource.SettingsGetter); ok {
+		sGetter.Settings(n.Settings.Get(item.Type))
+	}
+
+	if queueItem.State != queue.ItemStateNew {
+		return queueItem, nil
+	}
+
+	if err := n.Filter(queueItem); err != nil {
+		return nil, err
+	}
+
+	if queueItem.State != queue.ItemStateNew {
+		return queueItem, nil
+	}
+
+	if changes := item.Changes(current); len(changes) > 0 {
+		queueItem.State = queue.ItemStateFiltered
+		queueItem.Reason = fmt.Sprintf("properties changed since the plan was written: %s", strings.Join(changes, ", "))
+	}
+
+	return queueItem, nil
+}
+
+func init() {
+	flags := []cli.Flag{
+		&cli.PathFlag{
+			Name:  "config",
+			Usage: "path to config file",
+			Value: "config.yaml",
+		},
+		&cli.PathFlag{
+			Name:     "plan",
+			Usage:    "path to the plan file written by run --plan-out",
+			Required: true,
+		},
+		&cli.BoolFlag{
+			Name:    "quiet",
+			Aliases: []string{"q"},
+			Usage:   "hide filtered messages",
+		},
+		&cli.BoolFlag{
+			Name:    "no-prompt",
+			Usage:   "disable prompting for verification to run",
+			Aliases: []string{"force"},
+		},
+		&cli.IntFlag{
+			Name:    "prompt-delay",
+			Usage:   "seconds to delay after prompt before running (minimum: 3 seconds)",
+			Value:   10,
+			Aliases: []string{"force-sleep"},
+		},
+		&cli.IntFlag{
+			Name:  "max-wait-retries",
+			Usage: "abort the removal once every remaining resource has been waiting for this many passes over the queue (0 waits indefinitely)",
+		},
+	}
+
+	flags = append(flags, reportFlags()...)
+	flags = append(flags, metricsFlags()...)
+	flags = append(flags, authFlags()...)
+
+	cmd := &cli.Command{
+		Name:   "apply",
+		Usage:  "remove exactly the resources of a plan written by a dry run",
+		Flags:  append(flags, global.Flags()...),
+		Before: global.Before,
+		Action: executeApply,
+	}
+
+	common.RegisterCommand(cmd)
+}
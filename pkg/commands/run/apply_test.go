@@ -0,0 +1,108 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/filter"
+	libnuke "github.com/ekristen/libnuke/pkg/nuke"
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/types"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+	"github.com/ekristen/azure-nuke/pkg/plan"
+	"github.com/ekristen/azure-nuke/resources"
+)
+
+type testPlanResource struct {
+	*resources.BaseResource `property:",inline"`
+
+	Name      *string
+	protected bool
+}
+
+func (r *testPlanResource) Filter() error {
+	if r.protected {
+		return fmt.Errorf("cannot delete the identity running the nuke")
+	}
+
+	return nil
+}
+
+func (r *testPlanResource) Remove(_ context.Context) error {
+	return nil
+}
+
+func (r *testPlanResource) Properties() types.Properties {
+	return types.NewPropertiesFromStruct(r)
+}
+
+func (r *testPlanResource) String() string {
+	return ptr.ToString(r.Name)
+}
+
+func newTestPlanResource(name string, protected bool) *testPlanResource {
+	return &testPlanResource{
+		BaseResource: &resources.BaseResource{
+			Region:         ptr.String("eastus"),
+			SubscriptionID: ptr.String("sub"),
+			ResourceGroup:  ptr.String("rg"),
+		},
+		Name:      ptr.String(name),
+		protected: protected,
+	}
+}
+
+func TestNewPlanQueueItem(t *testing.T) {
+	opts := &azure.ListerOpts{TenantID: "tenant", SubscriptionID: "sub", ResourceGroup: "rg"}
+
+	cases := map[string]struct {
+		planned *testPlanResource
+		current *testPlanResource
+		state   queue.ItemState
+		reason  string
+	}{
+		"unchanged": {
+			planned: newTestPlanResource("a", false),
+			current: newTestPlanResource("a", false),
+			state:   queue.ItemStateNew,
+		},
+		"changed": {
+			planned: newTestPlanResource("a", false),
+			current: func() *testPlanResource {
+				r := newTestPlanResource("a", false)
+				r.Region = ptr.String("westus")
+				return r
+			}(),
+			state:  queue.ItemStateFiltered,
+			reason: "properties changed since the plan was written: Region",
+		},
+		"filtered by the resource": {
+			planned: newTestPlanResource("a", false),
+			current: newTestPlanResource("a", true),
+			state:   queue.ItemStateFiltered,
+			reason:  "cannot delete the identity running the nuke",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			n := libnuke.New(&libnuke.Parameters{}, filter.Filters{}, nil)
+
+			planned := plan.NewItem(&queue.Item{
+				Resource: tc.planned,
+				Type:     "TestPlanResource",
+				Opts:     opts,
+			})
+
+			item, err := newPlanQueueItem(n, planned, tc.current, opts)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.state, item.State)
+			assert.Equal(t, tc.reason, item.Reason)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package run
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"slices"
@@ -15,6 +16,7 @@ import (
 	libconfig "github.com/ekristen/libnuke/pkg/config"
 	"github.com/ekristen/libnuke/pkg/filter"
 	libnuke "github.com/ekristen/libnuke/pkg/nuke"
+	"github.com/ekristen/libnuke/pkg/queue"
 	"github.com/ekristen/libnuke/pkg/registry"
 	libscanner "github.com/ekristen/libnuke/pkg/scanner"
 	"github.com/ekristen/libnuke/pkg/types"
@@ -23,6 +25,11 @@ import (
 	"github.com/ekristen/azure-nuke/pkg/commands/global"
 	"github.com/ekristen/azure-nuke/pkg/common"
 	"github.com/ekristen/azure-nuke/pkg/config"
+	"github.com/ekristen/azure-nuke/pkg/metrics"
+	"github.com/ekristen/azure-nuke/pkg/plan"
+	"github.com/ekristen/azure-nuke/pkg/report"
+	"github.com/ekristen/azure-nuke/pkg/terraform"
+	"github.com/ekristen/azure-nuke/resources"
 )
 
 type log2LogrusWriter struct {
@@ -38,30 +45,25 @@ func (w *log2LogrusWriter) Write(b []byte) (int, error) {
 	return n, nil
 }
 
-func execute(c *cli.Context) error { //nolint:funlen,gocyclo
-	ctx, cancel := context.WithCancel(c.Context)
-	defer cancel()
-
-	// This is to purposefully capture the output from the standard logger that is written to by several
-	// of the azure sdk golang libraries by hashicorp
+// setupLogging captures the output from the standard logger, which is written to by several of the azure sdk golang
+// libraries by hashicorp, and sends all logging to the output unless it is written to a log file
+func setupLogging(c *cli.Context, output io.Writer) {
 	log.SetOutput(&log2LogrusWriter{
 		entry: logrus.WithField("source", "standard-logger"),
 	})
 
-	logger := logrus.StandardLogger()
-	logger.SetOutput(os.Stdout)
+	if c.Path("log-file") == "" {
+		logrus.StandardLogger().SetOutput(output)
+	}
+}
 
-	logger.Tracef("tenant id: %s", c.String("tenant-id"))
+func execute(c *cli.Context) (err error) { //nolint:funlen,gocyclo
+	ctx, cancel := context.WithCancel(c.Context)
+	defer cancel()
 
-	authorizers, err := azure.ConfigureAuth(ctx,
-		c.String("environment"), c.String("tenant-id"), c.String("client-id"),
-		c.String("client-secret"), c.String("client-certificate-file"),
-		c.String("client-federated-token-file"))
-	if err != nil {
-		return err
-	}
+	setupLogging(c, os.Stdout)
 
-	logger.Trace("preparing to run nuke")
+	logrus.Trace("preparing to run nuke")
 
 	params := &libnuke.Parameters{
 		Force:      c.Bool("force"),
@@ -70,8 +72,18 @@ func execute(c *cli.Context) error { //nolint:funlen,gocyclo
 		NoDryRun:   c.Bool("no-dry-run"),
 		Includes:   c.StringSlice("include"),
 		Excludes:   c.StringSlice("exclude"),
+
+		MaxWaitRetries: c.Int("max-wait-retries"),
 	}
 
+	met, err := newMetrics(c, !params.NoDryRun)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = finishMetrics(c, met, err)
+	}()
+
 	if len(c.StringSlice("feature-flag")) > 0 {
 		if slices.Contains(c.StringSlice("feature-flag"), "wait-on-dependencies") {
 			params.WaitOnDependencies = true
@@ -90,17 +102,303 @@ func execute(c *cli.Context) error { //nolint:funlen,gocyclo
 		return err
 	}
 
-	tenant, err := azure.NewTenant(ctx,
-		authorizers, c.String("tenant-id"), c.StringSlice("subscription-id"), parsedConfig.Regions)
+	tenantIDs, err := resolveTenantIDs(c, parsedConfig)
 	if err != nil {
 		return err
 	}
 
-	filters, err := parsedConfig.Filters(c.String("tenant-id"))
+	if c.Path("report") != "" && !slices.Contains(report.Formats, c.String("report-format")) {
+		return fmt.Errorf("unsupported report format: %s", c.String("report-format"))
+	}
+
+	if !slices.Contains(azure.Discoveries, c.String("discovery")) {
+		return fmt.Errorf("unsupported discovery: %s", c.String("discovery"))
+	}
+
+	if c.Path("plan-out") != "" && params.NoDryRun {
+		return fmt.Errorf("--plan-out can only be used with a dry run")
+	}
+
+	if c.Bool("stop") && c.Bool("quarantine") {
+		return fmt.Errorf("--stop cannot be used with --quarantine")
+	}
+
+	if c.Bool("stop") && c.Path("plan-out") != "" {
+		return fmt.Errorf("--stop cannot be used with --plan-out")
+	}
+
+	quarantine, err := newQuarantine(c)
+	if err != nil {
+		return err
+	}
+
+	tfState, err := loadTerraformState(c, parsedConfig)
 	if err != nil {
 		return err
 	}
 
+	t, err := newTraffic(c)
+	if err != nil {
+		return err
+	}
+	defer t.Close()
+
+	rpt := report.New(!params.NoDryRun)
+
+	if len(tenantIDs) == 1 && !c.Bool("all-tenants") {
+		tn, err := newTenantNuke(ctx, c, params, parsedConfig, tenantIDs[0], false, quarantine, tfState, t, met)
+		if err != nil {
+			return err
+		}
+
+		if c.Bool("preflight") {
+			if err := runPreflight(ctx, tn); err != nil {
+				return err
+			}
+		}
+
+		p := &azure.Prompt{Parameters: params, Tenant: tn.tenant, Stop: c.Bool("stop")}
+		if c.Bool("stop") {
+			tn.nuke.RegisterPrompt(stopPrompt(p.Prompt, tn))
+		} else {
+			tn.nuke.RegisterPrompt(p.Prompt)
+		}
+
+		logrus.Debug("running ...")
+
+		runErr := runTenant(c.Context, tn, newQueueObserver(global.StructuredLogging(c), met), c.Bool("stop"))
+		if runErr == nil && c.Bool("stop") && !params.NoDryRun {
+			printStopActions(tn)
+		}
+		logThrottlingMetrics(tn)
+		runErr = saveQuarantine(ctx, c, quarantine, runErr, tn)
+		if runErr == nil {
+			runErr = writePlan(c, tn)
+		}
+
+		return writeReport(c, rpt, runErr, tn)
+	}
+
+	m := &multiTenantNuke{
+		params:  params,
+		version: fmt.Sprintf("> %s", common.AppVersion.String()),
+		stop:    c.Bool("stop"),
+		states:  newQueueObserver(global.StructuredLogging(c), met),
+	}
+
+	for _, tenantID := range tenantIDs {
+		tn, err := newTenantNuke(ctx, c, params, parsedConfig, tenantID, true, quarantine, tfState, t, met)
+		if err != nil {
+			return fmt.Errorf("tenant %s: %w", tenantID, err)
+		}
+
+		m.tenants = append(m.tenants, tn)
+	}
+
+	if c.Bool("preflight") {
+		if err := runPreflight(ctx, m.tenants...); err != nil {
+			return err
+		}
+	}
+
+	logrus.Debug("running ...")
+
+	runErr := m.Run(c.Context)
+	logThrottlingMetrics(m.tenants...)
+	runErr = saveQuarantine(ctx, c, quarantine, runErr, m.tenants...)
+	if runErr == nil {
+		runErr = writePlan(c, m.tenants...)
+	}
+
+	return writeReport(c, rpt, runErr, m.tenants...)
+}
+
+// writePlan writes every resource that would have been removed by the dry run to the plan file if one was
+// requested, the plan can then be reviewed and applied with the apply command.
+func writePlan(c *cli.Context, tenants ...*tenantNuke) error {
+	if c.Path("plan-out") == "" {
+		return nil
+	}
+
+	p := plan.New(common.AppVersion.String())
+	for _, tn := range tenants {
+		p.AddItems(tn.nuke.Queue.GetItems())
+	}
+
+	if err := p.Save(c.Path("plan-out")); err != nil {
+		return err
+	}
+
+	logrus.Infof("plan with %d resources written to %s", len(p.Items), c.Path("plan-out"))
+
+	return nil
+}
+
+// logThrottlingMetrics logs how much each tenant was throttled during the run
+func logThrottlingMetrics(tenants ...*tenantNuke) {
+	for _, tn := range tenants {
+		tn.tenant.Authorizers.Pipeline.LogMetrics(logrus.
+			WithField("component", "pipeline").
+			WithField("tenant_id", tn.tenant.ID))
+	}
+}
+
+// writeReport writes the report of the run if one was requested, the report is written even if the run failed as
+// that is when it is needed the most. The error of the run always takes precedence.
+func writeReport(c *cli.Context, rpt *report.Report, runErr error, tenants ...*tenantNuke) error {
+	if c.Path("report") == "" {
+		return runErr
+	}
+
+	for _, tn := range tenants {
+		rpt.AddItems(tn.nuke.Queue.GetItems())
+	}
+
+	if err := rpt.Write(c.Path("report"), c.String("report-format")); err != nil {
+		if runErr != nil {
+			logrus.WithError(err).Error("unable to write report")
+			return runErr
+		}
+
+		return err
+	}
+
+	logrus.Infof("report written to %s", c.Path("report"))
+
+	return runErr
+}
+
+// resolveTenantIDs determines which tenants to run against, either all non-blocklisted tenants configured in the
+// accounts section of the configuration or the tenants explicitly requested via --tenant-id.
+func resolveTenantIDs(c *cli.Context, parsedConfig *config.Config) ([]string, error) {
+	requested := c.StringSlice("tenant-id")
+
+	if c.Bool("all-tenants") {
+		if len(requested) > 0 {
+			return nil, fmt.Errorf("--all-tenants and --tenant-id cannot be used together")
+		}
+
+		var tenantIDs []string
+		for tenantID := range parsedConfig.Accounts {
+			if parsedConfig.InBlocklist(tenantID) {
+				logrus.WithField("tenant_id", tenantID).Warn("skipping tenant (reason: blocklisted)")
+				continue
+			}
+
+			tenantIDs = append(tenantIDs, tenantID)
+		}
+
+		if len(tenantIDs) == 0 {
+			return nil, fmt.Errorf("no tenants configured in accounts that are not blocklisted")
+		}
+
+		slices.Sort(tenantIDs)
+
+		return tenantIDs, nil
+	}
+
+	if len(requested) == 0 {
+		return nil, fmt.Errorf("either --tenant-id or --all-tenants must be provided")
+	}
+
+	var tenantIDs []string
+	for _, tenantID := range requested {
+		if parsedConfig.InBlocklist(tenantID) {
+			return nil, fmt.Errorf("tenant %s is blocklisted", tenantID)
+		}
+
+		if !slices.Contains(tenantIDs, tenantID) {
+			tenantIDs = append(tenantIDs, tenantID)
+		}
+	}
+
+	return tenantIDs, nil
+}
+
+// authenticate configures the authorizers for a tenant, credentials configured for the tenant in the configuration
+// take precedence over the credentials provided via flags or environment variables.
+func authenticate(
+	ctx context.Context, c *cli.Context, parsedConfig *config.Config, tenantID string, t *traffic,
+) (*azure.Authorizers, error) {
+	if t != nil && t.replayer != nil {
+		return azure.ConfigureReplay(c.String("environment"), tenantID, t.replayer)
+	}
+
+	creds := parsedConfig.GetCredentials(tenantID)
+	if creds == nil {
+		creds = &config.Credentials{
+			AuthMethod:               c.String("auth-method"),
+			ClientID:                 c.String("client-id"),
+			ClientSecret:             c.String("client-secret"),
+			ClientCertificateFile:    c.String("client-certificate-file"),
+			ClientFederatedTokenFile: c.String("client-federated-token-file"),
+		}
+	}
+
+	if creds.AuthMethod == "" {
+		creds.AuthMethod = azure.AuthMethodClientCredentials
+	}
+
+	if !slices.Contains(azure.AuthMethods, creds.AuthMethod) {
+		return nil, fmt.Errorf("unsupported auth method %q configured for tenant %s, must be one of: %s",
+			creds.AuthMethod, tenantID, strings.Join(azure.AuthMethods, ", "))
+	}
+
+	// Note: only the client credentials require a client-id, it selects a user-assigned managed identity or the
+	// app registration to use for the device code flow but is optional for the other methods, azure.ConfigureAuth
+	// checks that the token was issued to it
+	if creds.AuthMethod == azure.AuthMethodClientCredentials && creds.ClientID == "" {
+		return nil, fmt.Errorf("no client-id configured for tenant %s", tenantID)
+	}
+
+	authorizers, err := azure.ConfigureAuth(ctx,
+		c.String("environment"), creds.AuthMethod, tenantID, creds.ClientID,
+		creds.ClientSecret, creds.ClientCertificateFile, creds.ClientFederatedTokenFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if t != nil && t.recorder != nil {
+		authorizers.Pipeline.SetRecorder(t.recorder)
+
+		if err := t.recorder.RecordIdentity(tenantID, authorizers.Identity); err != nil {
+			return nil, err
+		}
+	}
+
+	return authorizers, nil
+}
+
+// newTenantNuke authenticates against a tenant and configures a nuke instance with the filters and scanners
+// for that tenant.
+func newTenantNuke( //nolint:funlen,gocyclo
+	ctx context.Context, c *cli.Context, params *libnuke.Parameters,
+	parsedConfig *config.Config, tenantID string, multiTenant bool, quarantine *azure.Quarantine,
+	tfState *terraform.State, t *traffic, met *metrics.Metrics,
+) (*tenantNuke, error) {
+	logger := logrus.StandardLogger()
+
+	logger.Tracef("tenant id: %s", tenantID)
+
+	authorizers, err := authenticate(ctx, c, parsedConfig, tenantID, t)
+	if err != nil {
+		return nil, err
+	}
+
+	observeRequests(authorizers, met)
+
+	tenant, err := azure.NewTenant(ctx,
+		authorizers, tenantID, c.StringSlice("subscription-id"),
+		parsedConfig.GetManagementGroups(tenantID), parsedConfig.Regions, parsedConfig, c.String("discovery"))
+	if err != nil {
+		return nil, err
+	}
+
+	filters, err := parsedConfig.Filters(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Setup Region Filters as Global Filters
 	if len(filters[filter.Global]) == 0 {
 		filters[filter.Global] = []filter.Filter{}
@@ -121,10 +419,7 @@ func execute(c *cli.Context) error { //nolint:funlen,gocyclo
 
 	n.RegisterVersion(fmt.Sprintf("> %s", common.AppVersion.String()))
 
-	p := &azure.Prompt{Parameters: params, Tenant: tenant}
-	n.RegisterPrompt(p.Prompt)
-
-	tenantConfig := parsedConfig.Accounts[c.String("tenant-id")]
+	tenantConfig := parsedConfig.Accounts[tenantID]
 	tenantResourceTypes := types.ResolveResourceTypes(
 		registry.GetNamesForScope(azure.TenantScope),
 		[]types.Collection{
@@ -141,6 +436,22 @@ func execute(c *cli.Context) error { //nolint:funlen,gocyclo
 		nil,
 	)
 
+	mgResourceTypes := types.ResolveResourceTypes(
+		registry.GetNamesForScope(azure.ManagementGroupScope),
+		[]types.Collection{
+			n.Parameters.Includes,
+			parsedConfig.ResourceTypes.GetIncludes(),
+			tenantConfig.ResourceTypes.GetIncludes(),
+		},
+		[]types.Collection{
+			n.Parameters.Excludes,
+			parsedConfig.ResourceTypes.Excludes,
+			tenantConfig.ResourceTypes.Excludes,
+		},
+		nil,
+		nil,
+	)
+
 	subResourceTypes := types.ResolveResourceTypes(
 		registry.GetNamesForScope(azure.SubscriptionScope),
 		[]types.Collection{
@@ -173,35 +484,88 @@ func execute(c *cli.Context) error { //nolint:funlen,gocyclo
 		nil,
 	)
 
+	// Note: when running against multiple tenants the scanner owners need to be unique across all tenants
+	tenantPrefix := ""
+	if multiTenant {
+		tenantPrefix = fmt.Sprintf("tenant/%s/", strings.Split(tenant.ID, "-")[0])
+	}
+
+	resourceTypes := map[registry.Scope][]string{
+		azure.ResourceGroupScope: rgResourceTypes,
+	}
+
+	var scanners []*libscanner.Scanner
+	register := func(scope registry.Scope, s *libscanner.Scanner) error {
+		scanners = append(scanners, s)
+		return n.RegisterScanner(scope, s)
+	}
+
 	if slices.Contains(parsedConfig.Regions, "global") || slices.Contains(parsedConfig.Regions, "all") {
-		if err := n.RegisterScanner(azure.TenantScope,
-			libscanner.New("tenant", tenantResourceTypes, &azure.ListerOpts{
+		resourceTypes[azure.TenantScope] = tenantResourceTypes
+		resourceTypes[azure.ManagementGroupScope] = mgResourceTypes
+		resourceTypes[azure.SubscriptionScope] = subResourceTypes
+
+		if err := register(azure.TenantScope,
+			libscanner.New(fmt.Sprintf("%stenant", tenantPrefix), tenantResourceTypes, &azure.ListerOpts{
 				Authorizers: authorizers,
 				TenantID:    tenant.ID,
+				Quarantine:  quarantine,
+				Stop:        c.Bool("stop"),
+				Terraform:   tfState,
 			})); err != nil {
-			return err
+			return nil, err
 		}
 
 		logger.
 			WithField("component", "run").
-			WithField("scope", "tenant").
+			WithField("scope", azure.ScopeTenant).
+			WithField("tenant_id", tenant.ID).
 			Debug("registering scanner")
+
+		for _, mgName := range tenant.ManagementGroups.Names() {
+			logger.
+				WithField("component", "run").
+				WithField("scope", azure.ScopeManagementGroup).
+				WithField("management_group", mgName).
+				Debug("registering scanner")
+
+			if err := register(azure.ManagementGroupScope,
+				libscanner.New(fmt.Sprintf("%smg/%s", tenantPrefix, mgName), mgResourceTypes, &azure.ListerOpts{
+					Authorizers:       tenant.Authorizers,
+					TenantID:          tenant.ID,
+					ManagementGroupID: mgName,
+					Regions:           parsedConfig.Regions,
+					Quarantine:        quarantine,
+					Stop:              c.Bool("stop"),
+					Terraform:         tfState,
+				})); err != nil {
+				return nil, err
+			}
+		}
+
 		for _, subscriptionID := range tenant.SubscriptionIds {
 			logger.
 				WithField("component", "run").
-				WithField("scope", "subscription").
+				WithField("scope", azure.ScopeSubscription).
 				WithField("subscription_id", subscriptionID).
 				Debug("registering scanner")
 
 			parts := strings.Split(subscriptionID, "-")
-			if err := n.RegisterScanner(azure.SubscriptionScope,
-				libscanner.New(fmt.Sprintf("sub/%s", parts[:1][0]), subResourceTypes, &azure.ListerOpts{
+			if err := register(azure.SubscriptionScope,
+				libscanner.New(fmt.Sprintf("%ssub/%s", tenantPrefix, parts[:1][0]), subResourceTypes, &azure.ListerOpts{
 					Authorizers:    tenant.Authorizers,
 					TenantID:       tenant.ID,
 					SubscriptionID: subscriptionID,
 					Regions:        parsedConfig.Regions,
+					Inventory:      tenant.Inventory,
+					Locks:          tenant.Locks,
+
+					ResourceGroupDetails: tenant.ResourceGroupDetails,
+					Quarantine:           quarantine,
+					Stop:                 c.Bool("stop"),
+					Terraform:            tfState,
 				})); err != nil {
-				return err
+				return nil, err
 			}
 		}
 	}
@@ -210,27 +574,118 @@ func execute(c *cli.Context) error { //nolint:funlen,gocyclo
 		for _, rg := range resourceGroups {
 			logger.
 				WithField("component", "run").
-				WithField("scope", "resource-group").
+				WithField("scope", azure.ScopeResourceGroup).
 				WithField("subscription_id", subscriptionID).
 				WithField("resource_group", rg).
 				Debug("registering scanner")
 
-			if err := n.RegisterScanner(azure.ResourceGroupScope,
-				libscanner.New(fmt.Sprintf("sub/%s/rg/%s", subscriptionID, rg), rgResourceTypes, &azure.ListerOpts{
+			if err := register(azure.ResourceGroupScope,
+				libscanner.New(fmt.Sprintf("%ssub/%s/rg/%s", tenantPrefix, subscriptionID, rg), rgResourceTypes, &azure.ListerOpts{
 					Authorizers:    tenant.Authorizers,
 					TenantID:       tenant.ID,
 					SubscriptionID: subscriptionID,
 					ResourceGroup:  rg,
 					Regions:        parsedConfig.Regions,
+					Inventory:      tenant.Inventory,
+					Locks:          tenant.Locks,
+					Listings:       tenant.Listings,
+
+					ResourceGroupDetails: tenant.ResourceGroupDetails,
+					Quarantine:           quarantine,
+					Stop:                 c.Bool("stop"),
+					Terraform:            tfState,
 				})); err != nil {
-				return err
+				return nil, err
 			}
 		}
 	}
 
-	logrus.Debug("running ...")
+	// Note: a lock is kept when the ManagementLock resource type does not run or when the lock is filtered, the
+	// resources under a kept lock are then filtered as well instead of failing to be removed
+	discoverLocks(ctx, tenant, func(item *queue.Item) bool {
+		if !slices.Contains(resourceTypes[azure.SubscriptionScope], resources.ManagementLockResource) {
+			return true
+		}
+
+		if err := n.Filter(item); err != nil {
+			return true
+		}
+
+		return item.State == queue.ItemStateFiltered
+	})
+
+	// Note: the resources of a filtered resource group are only filtered with it when the setting is enabled
+	if n.Settings.Get(resources.ResourceGroupResource).GetBool(resources.ResourceGroupFilterResourcesSetting) &&
+		slices.Contains(resourceTypes[azure.SubscriptionScope], resources.ResourceGroupResource) {
+		lister := registry.GetLister(resources.ResourceGroupResource)
+		filterResourceGroups(ctx, tenant, lister, tfState, func(item *queue.Item) bool {
+			if err := n.Filter(item); err != nil {
+				return false
+			}
+
+			return item.State == queue.ItemStateFiltered
+		})
+	}
+
+	return &tenantNuke{
+		tenant:        tenant,
+		nuke:          n,
+		resourceTypes: resourceTypes,
+		scanners:      scanners,
+	}, nil
+}
+
+// authFlags are the flags used to authenticate against azure, shared by the run and apply commands
+func authFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "environment",
+			Usage:   "Azure Environment",
+			EnvVars: []string{"AZURE_ENVIRONMENT"},
+			Value:   "global",
+		},
+		&cli.StringFlag{
+			Name:    "auth-method",
+			Usage:   "the method to authenticate with (" + strings.Join(azure.AuthMethods, ", ") + ")",
+			EnvVars: []string{"AZURE_AUTH_METHOD"},
+			Value:   azure.AuthMethodClientCredentials,
+		},
+		&cli.StringFlag{
+			Name:    "client-id",
+			Usage:   "the client-id to use for authentication (unless configured per tenant in the config)",
+			EnvVars: []string{"AZURE_CLIENT_ID"},
+		},
+		&cli.StringFlag{
+			Name:    "client-secret",
+			Usage:   "the client-secret to use for authentication",
+			EnvVars: []string{"AZURE_CLIENT_SECRET"},
+		},
+		&cli.StringFlag{
+			Name:    "client-certificate-file",
+			Usage:   "the client-certificate-file to use for authentication",
+			EnvVars: []string{"AZURE_CLIENT_CERTIFICATE_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    "client-federated-token-file",
+			Usage:   "the client-federated-token-file to use for authentication",
+			EnvVars: []string{"AZURE_FEDERATED_TOKEN_FILE"},
+		},
+	}
+}
 
-	return n.Run(c.Context)
+// reportFlags are the flags used to configure the report, shared by the run and apply commands
+func reportFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.PathFlag{
+			Name:  "report",
+			Usage: "write a report of every discovered, filtered, removed and failed resource to this path when the run ends",
+		},
+		&cli.StringFlag{
+			Name:  "report-format",
+			Usage: "the format of the report (json or csv)",
+			Value: report.FormatJSON,
+		},
+	}
 }
 
 func init() {
@@ -268,21 +723,61 @@ func init() {
 			Value:   10,
 			Aliases: []string{"force-sleep"},
 		},
+		&cli.IntFlag{
+			Name:  "max-wait-retries",
+			Usage: "abort the removal once every remaining resource has been waiting for this many passes over the queue (0 waits indefinitely)",
+		},
 		&cli.StringSliceFlag{
 			Name:  "feature-flag",
 			Usage: "enable experimental behaviors that may not be fully tested or supported",
 		},
 		&cli.StringFlag{
-			Name:    "environment",
-			Usage:   "Azure Environment",
-			EnvVars: []string{"AZURE_ENVIRONMENT"},
-			Value:   "global",
+			Name:  "discovery",
+			Usage: "how resources are discovered, either by every lister (arm) or from a resource graph inventory (resource-graph)",
+			Value: azure.DiscoveryARM,
 		},
-		&cli.StringFlag{
-			Name:     "tenant-id",
-			Usage:    "the tenant-id to nuke",
-			EnvVars:  []string{"AZURE_TENANT_ID"},
-			Required: true,
+		&cli.BoolFlag{
+			Name:  "preflight",
+			Usage: "check the permissions needed by every resource type before running and abort if any are missing",
+		},
+		&cli.BoolFlag{
+			Name:  "quarantine",
+			Usage: "mark the resources that would be removed and only remove them after the grace period has expired",
+		},
+		&cli.DurationFlag{
+			Name:  "quarantine-grace-period",
+			Usage: "how long a quarantined resource is kept before it is removed",
+			Value: 7 * 24 * time.Hour,
+		},
+		&cli.PathFlag{
+			Name:  "quarantine-state",
+			Usage: "path to the file that records the quarantined resources that cannot be tagged (i.e. Entra objects)",
+			Value: "azure-nuke-quarantine.json",
+		},
+		&cli.BoolFlag{
+			Name:  "stop",
+			Usage: "stop the compute resources (i.e. deallocate virtual machines) instead of removing them, every other resource is skipped",
+		},
+		&cli.PathFlag{
+			Name:  "record",
+			Usage: "record every resource manager and microsoft graph request and response to this directory, credentials are redacted",
+		},
+		&cli.PathFlag{
+			Name:  "replay",
+			Usage: "replay a recording made with --record instead of talking to azure, only a dry run can be replayed",
+		},
+		&cli.PathFlag{
+			Name:  "plan-out",
+			Usage: "write every resource that would be removed by the dry run to this plan file, see the apply command",
+		},
+		&cli.StringSliceFlag{
+			Name:    "tenant-id",
+			Usage:   "the tenant-id to nuke (can be provided multiple times to nuke multiple tenants)",
+			EnvVars: []string{"AZURE_TENANT_ID"},
+		},
+		&cli.BoolFlag{
+			Name:  "all-tenants",
+			Usage: "nuke every tenant configured in the accounts section of the config that is not blocklisted",
 		},
 		&cli.StringSliceFlag{
 			Name:     "subscription-id",
@@ -290,29 +785,12 @@ func init() {
 			EnvVars:  []string{"AZURE_SUBSCRIPTION_ID"},
 			Required: false,
 		},
-		&cli.StringFlag{
-			Name:     "client-id",
-			Usage:    "the client-id to use for authentication",
-			EnvVars:  []string{"AZURE_CLIENT_ID"},
-			Required: true,
-		},
-		&cli.StringFlag{
-			Name:    "client-secret",
-			Usage:   "the client-secret to use for authentication",
-			EnvVars: []string{"AZURE_CLIENT_SECRET"},
-		},
-		&cli.StringFlag{
-			Name:    "client-certificate-file",
-			Usage:   "the client-certificate-file to use for authentication",
-			EnvVars: []string{"AZURE_CLIENT_CERTIFICATE_FILE"},
-		},
-		&cli.StringFlag{
-			Name:    "client-federated-token-file",
-			Usage:   "the client-federated-token-file to use for authentication",
-			EnvVars: []string{"AZURE_FEDERATED_TOKEN_FILE"},
-		},
 	}
 
+	flags = append(flags, reportFlags()...)
+	flags = append(flags, metricsFlags()...)
+	flags = append(flags, authFlags()...)
+
 	cmd := &cli.Command{
 		Name:    "run",
 		Aliases: []string{"nuke"},
@@ -0,0 +1,64 @@
+package run
+
+import (
+	"context"
+
+	"github.com/gotidy/ptr"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/registry"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+	"github.com/ekristen/azure-nuke/resources"
+)
+
+// discoverLocks lists the management locks of every subscription of the tenant before scanning, so that the
+// resources under a lock wait for it to be removed first. The keep function decides if a lock is going to be kept, in
+// which case the resources under it are filtered instead.
+func discoverLocks(ctx context.Context, tenant *azure.Tenant, keep func(item *queue.Item) bool) {
+	logger := logrus.WithField("component", "locks").WithField("tenant_id", tenant.ID)
+
+	lister := registry.GetLister(resources.ManagementLockResource)
+
+	for _, subscriptionID := range tenant.SubscriptionIds {
+		opts := &azure.ListerOpts{
+			Authorizers:    tenant.Authorizers,
+			TenantID:       tenant.ID,
+			SubscriptionID: subscriptionID,
+			Locks:          tenant.Locks,
+		}
+
+		found, err := lister.List(ctx, opts)
+		if err != nil {
+			// Note: without the locks the resources are still removed, the removal of a locked resource fails
+			logger.WithError(err).WithField("subscription_id", subscriptionID).Warn("unable to list management locks")
+			continue
+		}
+
+		for _, r := range found {
+			lock, ok := r.(*resources.ManagementLock)
+			if !ok {
+				continue
+			}
+
+			item := &queue.Item{
+				Resource: r,
+				State:    queue.ItemStateNew,
+				Type:     resources.ManagementLockResource,
+				Owner:    lock.GetRegion(),
+				Opts:     opts,
+			}
+
+			tenant.Locks.Add(&azure.Lock{
+				ID:    ptr.ToString(lock.ID),
+				Name:  ptr.ToString(lock.Name),
+				Level: lock.LockLevel,
+				Scope: lock.Scope,
+				Kept:  keep(item),
+			})
+		}
+	}
+
+	logger.Debugf("discovered %d management locks", tenant.Locks.Len())
+}
@@ -0,0 +1,88 @@
+package run
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+	"github.com/ekristen/azure-nuke/pkg/metrics"
+)
+
+// newMetrics creates the metrics of the run and starts serving them, it returns nil if neither serving nor writing the
+// metrics was requested
+func newMetrics(c *cli.Context, dryRun bool) (*metrics.Metrics, error) {
+	if c.String("metrics-listen") == "" && c.Path("metrics-textfile") == "" {
+		return nil, nil
+	}
+
+	m := metrics.New(dryRun)
+
+	if c.String("metrics-listen") != "" {
+		if err := m.Listen(c.String("metrics-listen")); err != nil {
+			return nil, err
+		}
+	}
+
+	logrus.AddHook(m)
+
+	return m, nil
+}
+
+// observeRequests collects the metrics of every request sent by the clients of the authorizers, this is a no-op if
+// there are no metrics
+func observeRequests(authorizers *azure.Authorizers, m *metrics.Metrics) {
+	if m == nil {
+		return
+	}
+
+	authorizers.Pipeline.SetObserver(m)
+}
+
+// finishMetrics records the outcome of the run, writes the metrics to the textfile if requested and stops serving
+// them. The error of the run is returned, or the error writing the textfile if the run succeeded.
+func finishMetrics(c *cli.Context, m *metrics.Metrics, runErr error) error {
+	if m == nil {
+		return runErr
+	}
+
+	m.Finish(runErr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := m.Close(ctx); err != nil {
+		logrus.WithField("component", "metrics").WithError(err).Warn("unable to stop serving metrics")
+	}
+
+	if c.Path("metrics-textfile") == "" {
+		return runErr
+	}
+
+	if err := m.WriteTextfile(c.Path("metrics-textfile")); err != nil {
+		if runErr != nil {
+			logrus.WithField("component", "metrics").WithError(err).Error("unable to write metrics")
+			return runErr
+		}
+
+		return err
+	}
+
+	return runErr
+}
+
+// metricsFlags are the flags of the metrics, shared by the run and apply commands
+func metricsFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "metrics-listen",
+			Usage: "serve prometheus metrics on /metrics of this address (i.e. :9090) while the run is in progress",
+		},
+		&cli.PathFlag{
+			Name:  "metrics-textfile",
+			Usage: "write prometheus metrics to this file when the run ends, for the textfile collector of node_exporter",
+		},
+	}
+}
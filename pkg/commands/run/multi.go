@@ -0,0 +1,364 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	libnuke "github.com/ekristen/libnuke/pkg/nuke"
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/registry"
+	libscanner "github.com/ekristen/libnuke/pkg/scanner"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+	"github.com/ekristen/azure-nuke/pkg/report"
+)
+
+// tenantNuke couples a libnuke instance with the tenant it was configured for. Filters, presets and credentials
+// are all tenant specific, so when running against multiple tenants each tenant gets its own instance.
+type tenantNuke struct {
+	tenant *azure.Tenant
+	nuke   *libnuke.Nuke
+
+	// resourceTypes are the resource types the scanners were registered with, keyed by scope
+	resourceTypes map[registry.Scope][]string
+
+	// scanners are the scanners registered with the nuke instance, the snapshot command runs them itself
+	scanners []*libscanner.Scanner
+
+	// removing is set once the queue of the tenant is processed
+	removing bool
+}
+
+// multiTenantNuke drives multiple tenantNuke instances as if they were one, this results in a single prompt before
+// scanning, a single prompt before removal and a single consolidated summary at the end of the run.
+type multiTenantNuke struct {
+	params   *libnuke.Parameters
+	version  string
+	tenants  []*tenantNuke
+	runSleep time.Duration
+
+	// stop is set when the resources are stopped instead of removed
+	stop bool
+
+	// states observes the state transitions of the items of every tenant
+	states *queueObserver
+
+	// failedCount is how many times the failed items were retried while nothing else was processed
+	failedCount int
+	// waitingCount is how many times the queues were processed while every unfinished item was waiting
+	waitingCount int
+}
+
+// runTenant is the libnuke Run function for a single tenant, the queue is processed by the loop of multiTenantNuke so
+// that the state transitions of the items are logged.
+func runTenant(ctx context.Context, t *tenantNuke, states *queueObserver, stop bool) error {
+	t.nuke.Version()
+
+	if err := t.nuke.Validate(); err != nil {
+		return err
+	}
+
+	if err := t.nuke.Prompt(); err != nil {
+		return err
+	}
+
+	if err := t.nuke.Scan(ctx); err != nil {
+		return err
+	}
+
+	states.observe(t.tenant.ID, t.nuke.Queue.GetItems())
+
+	if t.nuke.Queue.Count(queue.ItemStateNew) == 0 {
+		fmt.Println("No resource to delete.")
+		return nil
+	}
+
+	if !t.nuke.Parameters.NoDryRun {
+		fmt.Println("The above resources would be deleted with the supplied configuration. Provide --no-dry-run to actually destroy resources.")
+		return nil
+	}
+
+	if err := t.nuke.Prompt(); err != nil {
+		return err
+	}
+
+	m := &multiTenantNuke{
+		params:  t.nuke.Parameters,
+		tenants: []*tenantNuke{t},
+		stop:    stop,
+		states:  states,
+	}
+
+	if err := m.run(ctx); err != nil {
+		return err
+	}
+
+	fmt.Printf("Nuke complete: %d failed, %d skipped, %d %s.\n\n",
+		t.nuke.Queue.Count(queue.ItemStateFailed), t.nuke.Queue.Count(queue.ItemStateFiltered),
+		t.nuke.Queue.Count(queue.ItemStateFinished), m.finishedName())
+
+	return nil
+}
+
+// Run is modeled after the libnuke Run function, but validates, scans and processes every tenant in lock-step.
+func (m *multiTenantNuke) Run(ctx context.Context) error {
+	fmt.Println(m.version)
+
+	for _, t := range m.tenants {
+		if err := t.nuke.Validate(); err != nil {
+			return fmt.Errorf("tenant %s: %w", t.tenant.ID, err)
+		}
+	}
+
+	tenants := make([]*azure.Tenant, 0, len(m.tenants))
+	for _, t := range m.tenants {
+		tenants = append(tenants, t.tenant)
+	}
+
+	p := &azure.Prompt{Parameters: m.params, Tenants: tenants, Stop: m.stop}
+
+	if err := p.Prompt(); err != nil {
+		return err
+	}
+
+	for _, t := range m.tenants {
+		fmt.Printf("Scanning tenant %s\n\n", t.tenant.ID)
+
+		if err := t.nuke.Scan(ctx); err != nil {
+			return fmt.Errorf("tenant %s: %w", t.tenant.ID, err)
+		}
+
+		m.states.observe(t.tenant.ID, t.nuke.Queue.GetItems())
+	}
+
+	if m.count(queue.ItemStateNew, queue.ItemStateNewDependency) == 0 {
+		m.printScanSummary()
+		fmt.Println("No resource to delete.")
+		return nil
+	}
+
+	if m.stop {
+		printStopActions(m.tenants...)
+	}
+
+	if !m.params.NoDryRun {
+		m.printScanSummary()
+		fmt.Println("The above resources would be deleted with the supplied configuration. Provide --no-dry-run to actually destroy resources.")
+		return nil
+	}
+
+	if err := p.Prompt(); err != nil {
+		return err
+	}
+
+	err := m.run(ctx)
+
+	m.printSummary()
+
+	return err
+}
+
+// Apply is the equivalent of Run for queues that were populated from a plan, there is nothing to scan, so the items
+// are printed for review followed by a single prompt before removal.
+func (m *multiTenantNuke) Apply(ctx context.Context) error {
+	fmt.Println(m.version)
+
+	for _, t := range m.tenants {
+		if err := t.nuke.Validate(); err != nil {
+			return fmt.Errorf("tenant %s: %w", t.tenant.ID, err)
+		}
+	}
+
+	for _, t := range m.tenants {
+		for _, item := range t.nuke.Queue.GetItems() {
+			if m.params.Quiet && item.GetState() == queue.ItemStateFiltered {
+				continue
+			}
+
+			item.Print()
+		}
+
+		m.states.observe(t.tenant.ID, t.nuke.Queue.GetItems())
+	}
+
+	m.printScanSummary()
+
+	if m.count(queue.ItemStateNew, queue.ItemStateNewDependency) == 0 {
+		fmt.Println("No resource to delete.")
+		return nil
+	}
+
+	tenants := make([]*azure.Tenant, 0, len(m.tenants))
+	for _, t := range m.tenants {
+		tenants = append(tenants, t.tenant)
+	}
+
+	p := &azure.Prompt{Parameters: m.params, Tenants: tenants}
+
+	if err := p.Prompt(); err != nil {
+		return err
+	}
+
+	err := m.run(ctx)
+
+	m.printSummary()
+
+	return err
+}
+
+// run handles the processing of the queues of every tenant until all items reach a final state, it mirrors the run
+// loop of libnuke, where failed items are retried twice once nothing else is being processed and the run is aborted
+// once the items have been waiting for more than the maximum number of wait retries.
+func (m *multiTenantNuke) run(ctx context.Context) error {
+	if m.runSleep == 0 {
+		m.runSleep = 5 * time.Second
+	}
+
+	for _, t := range m.tenants {
+		t.removing = true
+	}
+
+	m.failedCount = 0
+	m.waitingCount = 0
+
+	for {
+		for _, t := range m.tenants {
+			handleQueue(ctx, t.nuke, m.finishedName())
+			m.states.observe(t.tenant.ID, t.nuke.Queue.GetItems())
+		}
+
+		if err := m.handleFailure(); err != nil {
+			return err
+		}
+
+		if err := m.handleWaiting(); err != nil {
+			return err
+		}
+
+		unfinishedCount := m.count(queue.ItemStateNew, queue.ItemStateNewDependency,
+			queue.ItemStatePending, queue.ItemStatePendingDependency, queue.ItemStateFailed,
+			queue.ItemStateWaiting, queue.ItemStateHold)
+
+		if unfinishedCount == 0 {
+			return nil
+		}
+
+		time.Sleep(m.runSleep)
+	}
+}
+
+// handleFailure is the libnuke handleFailure function for every tenant, an error is returned once the failed items
+// have been retried twice while nothing else was being processed
+func (m *multiTenantNuke) handleFailure() error {
+	processingCount := m.count(queue.ItemStatePending, queue.ItemStatePendingDependency, queue.ItemStateHold,
+		queue.ItemStateWaiting, queue.ItemStateNew, queue.ItemStateNewDependency)
+
+	if processingCount > 0 || m.count(queue.ItemStateFailed) == 0 {
+		m.failedCount = 0
+		return nil
+	}
+
+	if m.failedCount < 2 {
+		m.failedCount++
+		return nil
+	}
+
+	logrus.Errorf("There are resources in failed state, but none are ready for deletion, anymore.")
+	fmt.Println()
+
+	for _, t := range m.tenants {
+		for _, item := range t.nuke.Queue.GetItems() {
+			if item.GetState() != queue.ItemStateFailed {
+				continue
+			}
+
+			item.Print()
+			logrus.WithField("tenant_id", t.tenant.ID).Error(item.GetReason())
+		}
+	}
+
+	return fmt.Errorf("failed")
+}
+
+// handleWaiting is the libnuke handleWaiting function for every tenant, an error is returned once every unfinished
+// item has been waiting for more than the maximum number of wait retries. The items wait indefinitely when the
+// maximum is not set.
+func (m *multiTenantNuke) handleWaiting() error {
+	if m.params == nil || m.params.MaxWaitRetries == 0 {
+		return nil
+	}
+
+	pendingCount := m.count(queue.ItemStateWaiting, queue.ItemStatePending, queue.ItemStatePendingDependency,
+		queue.ItemStateHold)
+	newCount := m.count(queue.ItemStateNew, queue.ItemStateNewDependency)
+
+	if pendingCount == 0 || newCount > 0 {
+		m.waitingCount = 0
+		return nil
+	}
+
+	if m.waitingCount >= m.params.MaxWaitRetries {
+		return fmt.Errorf("max wait retries of %d exceeded", m.params.MaxWaitRetries)
+	}
+
+	m.waitingCount++
+
+	return nil
+}
+
+// count returns the number of items in the given states across all tenants
+func (m *multiTenantNuke) count(states ...queue.ItemState) int {
+	total := 0
+	for _, t := range m.tenants {
+		total += t.nuke.Queue.Count(states...)
+	}
+	return total
+}
+
+// total returns the number of items across all tenants
+func (m *multiTenantNuke) total() int {
+	total := 0
+	for _, t := range m.tenants {
+		total += t.nuke.Queue.Total()
+	}
+	return total
+}
+
+func (m *multiTenantNuke) printScanSummary() {
+	fmt.Println("Scan summary:")
+	for _, t := range m.tenants {
+		fmt.Printf("  tenant %s: %d total, %d nukeable, %d filtered\n", t.tenant.ID,
+			t.nuke.Queue.Total(),
+			t.nuke.Queue.Count(queue.ItemStateNew, queue.ItemStateNewDependency),
+			t.nuke.Queue.Count(queue.ItemStateFiltered))
+	}
+
+	fmt.Printf("  all %d tenants: %d total, %d nukeable, %d filtered\n\n", len(m.tenants), m.total(),
+		m.count(queue.ItemStateNew, queue.ItemStateNewDependency), m.count(queue.ItemStateFiltered))
+}
+
+func (m *multiTenantNuke) printSummary() {
+	fmt.Println("Nuke summary:")
+	for _, t := range m.tenants {
+		fmt.Printf("  tenant %s: %d failed, %d skipped, %d %s\n", t.tenant.ID,
+			t.nuke.Queue.Count(queue.ItemStateFailed),
+			t.nuke.Queue.Count(queue.ItemStateFiltered),
+			t.nuke.Queue.Count(queue.ItemStateFinished), m.finishedName())
+	}
+
+	fmt.Printf("Nuke complete: %d failed, %d skipped, %d %s.\n\n",
+		m.count(queue.ItemStateFailed), m.count(queue.ItemStateFiltered), m.count(queue.ItemStateFinished),
+		m.finishedName())
+}
+
+// finishedName is how the finished items are counted in the summaries, in stop mode they were stopped
+func (m *multiTenantNuke) finishedName() string {
+	if m.stop {
+		return report.StateStopped
+	}
+
+	return "finished"
+}
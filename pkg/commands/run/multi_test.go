@@ -0,0 +1,70 @@
+package run
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/filter"
+	libnuke "github.com/ekristen/libnuke/pkg/nuke"
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+func newTestMultiTenantNuke(params *libnuke.Parameters, items map[string][]*queue.Item) *multiTenantNuke {
+	m := &multiTenantNuke{
+		params:   params,
+		runSleep: time.Millisecond,
+	}
+
+	for tenantID, tenantItems := range items {
+		n := libnuke.New(params, filter.Filters{}, nil)
+		n.Queue = queue.New()
+		n.Queue.Items = append(n.Queue.Items, tenantItems...)
+
+		m.tenants = append(m.tenants, &tenantNuke{tenant: &azure.Tenant{ID: tenantID}, nuke: n})
+	}
+
+	return m
+}
+
+func TestRunAbortsAfterMaxWaitRetries(t *testing.T) {
+	listed := newTestPlanResource("a", false)
+	listed.SubscriptionID = ptr.String("sub-a")
+
+	// Note: the resource of sub-a is still listed, so its item keeps waiting while the item of sub-b finishes
+	testWaitListed = map[string][]resource.Resource{"sub-a": {listed}}
+	defer func() { testWaitListed = map[string][]resource.Resource{} }()
+
+	stuck := newTestWaitItem("sub-a")
+	removed := newTestWaitItem("sub-b")
+
+	m := newTestMultiTenantNuke(&libnuke.Parameters{MaxWaitRetries: 2}, map[string][]*queue.Item{
+		"tenant-a": {stuck},
+		"tenant-b": {removed},
+	})
+
+	assert.EqualError(t, m.run(context.Background()), "max wait retries of 2 exceeded")
+	assert.Equal(t, queue.ItemStateWaiting, stuck.GetState())
+	assert.Equal(t, queue.ItemStateFinished, removed.GetState())
+	assert.Equal(t, 2, m.waitingCount)
+}
+
+func TestRunWaitsForRemovedResources(t *testing.T) {
+	testWaitListed = map[string][]resource.Resource{}
+
+	item := newTestWaitItem("sub-a")
+
+	m := newTestMultiTenantNuke(&libnuke.Parameters{MaxWaitRetries: 2}, map[string][]*queue.Item{
+		"tenant-a": {item},
+	})
+
+	assert.NoError(t, m.run(context.Background()))
+	assert.Equal(t, queue.ItemStateFinished, item.GetState())
+	assert.Equal(t, 0, m.waitingCount)
+}
@@ -0,0 +1,190 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	libconfig "github.com/ekristen/libnuke/pkg/config"
+	libnuke "github.com/ekristen/libnuke/pkg/nuke"
+	"github.com/ekristen/libnuke/pkg/registry"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+	"github.com/ekristen/azure-nuke/pkg/commands/global"
+	"github.com/ekristen/azure-nuke/pkg/common"
+	"github.com/ekristen/azure-nuke/pkg/config"
+)
+
+// Note: the preflight command lives alongside the run command as it configures the tenants exactly like a run does,
+// it only checks the permissions for the resource types that would run instead of scanning for resources.
+
+func executePreflight(c *cli.Context) error {
+	ctx, cancel := context.WithCancel(c.Context)
+	defer cancel()
+
+	setupLogging(c, os.Stdout)
+
+	params := &libnuke.Parameters{
+		Includes: c.StringSlice("include"),
+		Excludes: c.StringSlice("exclude"),
+	}
+
+	parsedConfig, err := config.New(libconfig.Options{
+		Path:         c.Path("config"),
+		Deprecations: registry.GetDeprecatedResourceTypeMapping(),
+	})
+	if err != nil {
+		return err
+	}
+
+	tenantIDs, err := resolveTenantIDs(c, parsedConfig)
+	if err != nil {
+		return err
+	}
+
+	tenants := make([]*tenantNuke, 0, len(tenantIDs))
+	for _, tenantID := range tenantIDs {
+		tn, err := newTenantNuke(ctx, c, params, parsedConfig, tenantID, len(tenantIDs) > 1, nil, nil, nil, nil)
+		if err != nil {
+			return fmt.Errorf("tenant %s: %w", tenantID, err)
+		}
+
+		tenants = append(tenants, tn)
+	}
+
+	return runPreflight(ctx, tenants...)
+}
+
+// runPreflight checks the permissions of every tenant and prints the results, an error is returned if any permission
+// is missing so that nothing is started that is bound to fail halfway through
+func runPreflight(ctx context.Context, tenants ...*tenantNuke) error {
+	var failed []string
+
+	for _, tn := range tenants {
+		logrus.WithField("tenant_id", tn.tenant.ID).Debug("running preflight check")
+
+		result, err := azure.Preflight(ctx, tn.tenant, tn.resourceTypes)
+		if err != nil {
+			return fmt.Errorf("tenant %s: %w", tn.tenant.ID, err)
+		}
+
+		printPreflight(result)
+
+		if !result.OK() {
+			failed = append(failed, tn.tenant.ID)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("preflight check failed, permissions are missing for tenant(s): %s", strings.Join(failed, ", "))
+	}
+
+	fmt.Println("Preflight check passed.")
+
+	return nil
+}
+
+// printPreflight prints the permissions of the caller followed by the result of every resource type per scope
+func printPreflight(result *azure.PreflightResult) {
+	_, _ = color.New(color.Bold).Printf("Preflight check of tenant %s\n", result.TenantID)
+	fmt.Printf("  identity: %s\n", result.Identity)
+
+	if result.GraphPermissions != nil {
+		fmt.Printf("  microsoft graph permissions: %s\n", joinOrNone(result.GraphPermissions))
+	}
+
+	scope := ""
+	for _, check := range result.Checks {
+		if check.Scope != scope {
+			scope = check.Scope
+
+			fmt.Println()
+			_, _ = color.New(color.Bold).Printf("%s\n", scope)
+			if roles, ok := result.RoleAssignments[scope]; ok {
+				fmt.Printf("  roles: %s\n", joinOrNone(roles))
+			}
+		}
+
+		fmt.Printf("  %-45s list: %s  delete: %s", check.ResourceType,
+			preflightStatus(check.List), preflightStatus(check.Delete))
+
+		if len(check.Missing) > 0 {
+			_, _ = color.New(color.FgYellow).Printf("  (%s)", strings.Join(check.Missing, ", "))
+		}
+
+		fmt.Println()
+	}
+
+	fmt.Println()
+}
+
+// preflightStatus colors a preflight status, the status is padded so that the columns line up
+func preflightStatus(status string) string {
+	padded := fmt.Sprintf("%-7s", status)
+
+	switch status {
+	case azure.PreflightOK:
+		return color.GreenString(padded)
+	case azure.PreflightMissing:
+		return color.RedString(padded)
+	default:
+		return color.YellowString(padded)
+	}
+}
+
+func joinOrNone(values []string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+
+	return strings.Join(values, ", ")
+}
+
+func init() {
+	flags := []cli.Flag{
+		&cli.PathFlag{
+			Name:  "config",
+			Usage: "path to config file",
+			Value: "config.yaml",
+		},
+		&cli.StringSliceFlag{
+			Name:  "include",
+			Usage: "only include this specific resource",
+		},
+		&cli.StringSliceFlag{
+			Name:  "exclude",
+			Usage: "exclude this specific resource (this overrides everything)",
+		},
+		&cli.StringSliceFlag{
+			Name:    "tenant-id",
+			Usage:   "the tenant-id to check (can be provided multiple times to check multiple tenants)",
+			EnvVars: []string{"AZURE_TENANT_ID"},
+		},
+		&cli.BoolFlag{
+			Name:  "all-tenants",
+			Usage: "check every tenant configured in the accounts section of the config that is not blocklisted",
+		},
+		&cli.StringSliceFlag{
+			Name:    "subscription-id",
+			Usage:   "the subscription-id to check (this filters to 1 or more subscription ids)",
+			EnvVars: []string{"AZURE_SUBSCRIPTION_ID"},
+		},
+	}
+
+	flags = append(flags, authFlags()...)
+
+	cmd := &cli.Command{
+		Name:   "preflight",
+		Usage:  "check the permissions needed to list and remove every resource type that would run",
+		Flags:  append(flags, global.Flags()...),
+		Before: global.Before,
+		Action: executePreflight,
+	}
+
+	common.RegisterCommand(cmd)
+}
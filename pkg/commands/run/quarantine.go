@@ -0,0 +1,102 @@
+package run
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+type quarantiner interface {
+	Quarantine(ctx context.Context, item *queue.Item) (bool, error)
+}
+
+// newQuarantine creates the quarantine if it was requested, nil otherwise
+func newQuarantine(c *cli.Context) (*azure.Quarantine, error) {
+	if !c.Bool("quarantine") {
+		return nil, nil
+	}
+
+	if c.Duration("quarantine-grace-period") <= 0 {
+		return nil, fmt.Errorf("--quarantine-grace-period must be positive")
+	}
+
+	return azure.NewQuarantine(c.Path("quarantine-state"), c.Duration("quarantine-grace-period"))
+}
+
+// saveQuarantine marks the resources after the run. The resources of the tenants whose queue was processed are
+// marked even if the run failed, i.e. when a single resource could not be removed, otherwise the marks of the run are
+// lost and the grace period of every resource starts over with the next run. The error of the run takes precedence.
+func saveQuarantine(
+	ctx context.Context, c *cli.Context, q *azure.Quarantine, runErr error, tenants ...*tenantNuke,
+) error {
+	if runErr == nil {
+		return quarantineResources(ctx, c, q, tenants...)
+	}
+
+	var processed []*tenantNuke
+	for _, tn := range tenants {
+		if tn.removing {
+			processed = append(processed, tn)
+		}
+	}
+
+	if len(processed) == 0 {
+		return runErr
+	}
+
+	if err := quarantineResources(ctx, c, q, processed...); err != nil {
+		logrus.WithError(err).Error("unable to quarantine resources")
+	}
+
+	return runErr
+}
+
+// quarantineResources marks every resource that would have been removed by the run so that it is removed by a later
+// run once its grace period has expired. Nothing is marked by a dry run.
+func quarantineResources(ctx context.Context, c *cli.Context, q *azure.Quarantine, tenants ...*tenantNuke) error {
+	if q == nil || !c.Bool("no-dry-run") {
+		return nil
+	}
+
+	marked := 0
+	for _, tn := range tenants {
+		logger := logrus.WithField("component", "quarantine").WithField("tenant_id", tn.tenant.ID)
+
+		for _, item := range tn.nuke.Queue.GetItems() {
+			r, ok := item.Resource.(quarantiner)
+			if !ok {
+				continue
+			}
+
+			ok, err := r.Quarantine(ctx, item)
+			if err != nil {
+				// Note: a resource that could not be marked is not removed, it is marked again by the next run
+				log := logger.WithError(err).WithField("resource_type", item.Type)
+				if stringer, ok := item.Resource.(resource.LegacyStringer); ok {
+					log = log.WithField("name", stringer.String())
+				}
+				log.Warn("unable to quarantine resource")
+				continue
+			}
+
+			if ok {
+				marked++
+			}
+		}
+	}
+
+	if err := q.Save(); err != nil {
+		return fmt.Errorf("unable to write quarantine state: %w", err)
+	}
+
+	logrus.Infof("quarantined %d resources until %s", marked, q.NewMark().DeleteAfter.Format("2006-01-02 15:04:05 MST"))
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package run
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ekristen/libnuke/pkg/filter"
+	libnuke "github.com/ekristen/libnuke/pkg/nuke"
+	"github.com/ekristen/libnuke/pkg/queue"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+	"github.com/ekristen/azure-nuke/resources"
+)
+
+func TestSaveQuarantineAfterFailedRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quarantine.json")
+
+	set := flag.NewFlagSet("run", flag.ContinueOnError)
+	set.Bool("no-dry-run", true, "")
+	c := cli.NewContext(nil, set, nil)
+
+	cases := map[string]struct {
+		removing bool
+		marked   bool
+	}{
+		"failed removal": {
+			removing: true,
+			marked:   true,
+		},
+		"failed before removal": {
+			removing: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			q, err := azure.NewQuarantine(path, time.Hour)
+			assert.NoError(t, err)
+
+			r := &testDependent{
+				BaseResource: &resources.BaseResource{SubscriptionID: ptr.String("sub-a"), ResourceGroup: ptr.String("rg")},
+				Name:         ptr.String(name),
+			}
+
+			item := &queue.Item{
+				Resource: r,
+				State:    queue.ItemStateNew,
+				Type:     testDependentResource,
+				Opts:     &azure.ListerOpts{SubscriptionID: "sub-a", ResourceGroup: "rg", Quarantine: q},
+			}
+
+			n := libnuke.New(&libnuke.Parameters{}, filter.Filters{}, nil)
+			r.BeforeEnqueue(item)
+			assert.NoError(t, n.Filter(item))
+			n.Queue.Items = append(n.Queue.Items, item)
+
+			tn := &tenantNuke{tenant: &azure.Tenant{ID: "tenant"}, nuke: n, removing: tc.removing}
+
+			runErr := fmt.Errorf("failed")
+			assert.Equal(t, runErr, saveQuarantine(context.Background(), c, q, runErr, tn))
+
+			saved, err := azure.NewQuarantine(path, time.Hour)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.marked, saved.StateMark(testDependentResource+"/sub-a/rg/"+name) != nil)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package run
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+	"github.com/ekristen/azure-nuke/pkg/metrics"
+	"github.com/ekristen/azure-nuke/pkg/report"
+)
+
+// queueObserver logs the state transitions of the items of the queues with the identity of their resource as fields,
+// and updates the metrics of the resources. libnuke only prints the items as text, the entries make them available to
+// a log pipeline. In the text format the entries are logged at debug level, as the printed items already contain the
+// same information.
+type queueObserver struct {
+	level   logrus.Level
+	states  map[*queue.Item]queue.ItemState
+	metrics *metrics.Metrics
+}
+
+func newQueueObserver(structured bool, m *metrics.Metrics) *queueObserver {
+	level := logrus.DebugLevel
+	if structured {
+		level = logrus.InfoLevel
+	}
+
+	return &queueObserver{
+		level:   level,
+		states:  make(map[*queue.Item]queue.ItemState),
+		metrics: m,
+	}
+}
+
+// observe logs every item whose state changed since it was last observed, items that were not observed before are
+// logged with their current state
+func (o *queueObserver) observe(tenantID string, items []*queue.Item) {
+	if o == nil {
+		return
+	}
+
+	o.metrics.ObserveQueue(tenantID, items)
+
+	for _, item := range items {
+		state := item.GetState()
+
+		previous, seen := o.states[item]
+		if seen && previous == state {
+			continue
+		}
+
+		o.states[item] = state
+
+		log := logrus.
+			WithField("component", "queue").
+			WithField("tenant_id", tenantID).
+			WithFields(itemFields(item)).
+			WithField("state", report.ItemStateName(item))
+
+		if seen {
+			log = log.WithField("previous_state", report.StateName(previous))
+		}
+
+		if reason := item.GetReason(); reason != "" {
+			log = log.WithField("reason", reason)
+		}
+
+		log.Log(o.level, "resource state changed")
+	}
+}
+
+// itemFields returns the fields that identify the resource of the item
+func itemFields(item *queue.Item) logrus.Fields {
+	fields := logrus.Fields{
+		"resource_type": item.Type,
+	}
+
+	if opts, ok := item.Opts.(*azure.ListerOpts); ok {
+		fields["scope"] = opts.Scope()
+
+		if opts.ManagementGroupID != "" {
+			fields["management_group"] = opts.ManagementGroupID
+		}
+
+		if opts.SubscriptionID != "" {
+			fields["subscription_id"] = opts.SubscriptionID
+		}
+
+		if opts.ResourceGroup != "" {
+			fields["resource_group"] = opts.ResourceGroup
+		}
+	}
+
+	if regional, ok := item.Resource.(interface{ GetRegion() string }); ok && regional.GetRegion() != "" {
+		fields["region"] = regional.GetRegion()
+	}
+
+	if stringer, ok := item.Resource.(resource.LegacyStringer); ok {
+		fields["resource_name"] = stringer.String()
+	}
+
+	if getter, ok := item.Resource.(resource.PropertyGetter); ok {
+		if id := getter.Properties().Get("ResourceID"); id != "" {
+			fields["resource_id"] = id
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,169 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	liberrors "github.com/ekristen/libnuke/pkg/errors"
+	liblog "github.com/ekristen/libnuke/pkg/log"
+	libnuke "github.com/ekristen/libnuke/pkg/nuke"
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+	"github.com/ekristen/azure-nuke/pkg/report"
+	"github.com/ekristen/azure-nuke/resources"
+)
+
+// listCache holds the resources that were listed again during a pass over the queue, keyed by listCacheKey
+type listCache map[string][]resource.Resource
+
+// handleQueue is the libnuke HandleQueue function with handleWait in place of the libnuke HandleWait function, the
+// finished items are counted under the finished name
+func handleQueue(ctx context.Context, n *libnuke.Nuke, finishedName string) {
+	cache := make(listCache)
+
+	for _, item := range n.Queue.GetItems() {
+		if refuseRemoval(item) {
+			printItem(item)
+			continue
+		}
+
+		switch item.GetState() {
+		case queue.ItemStateNew, queue.ItemStateHold:
+			n.HandleRemove(ctx, item)
+			printItem(item)
+		case queue.ItemStateNewDependency, queue.ItemStatePendingDependency:
+			n.HandleWaitDependency(ctx, item)
+			printItem(item)
+		case queue.ItemStateFailed:
+			n.HandleRemove(ctx, item)
+			handleWait(ctx, n, item, cache)
+			printItem(item)
+		case queue.ItemStatePending:
+			handleWait(ctx, n, item, cache)
+			item.State = queue.ItemStateWaiting
+			printItem(item)
+		case queue.ItemStateWaiting:
+			handleWait(ctx, n, item, cache)
+			printItem(item)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Removal requested: %d waiting, %d failed, %d skipped, %d %s\n\n",
+		n.Queue.Count(queue.ItemStateWaiting, queue.ItemStatePending, queue.ItemStatePendingDependency,
+			queue.ItemStateNewDependency, queue.ItemStateHold),
+		n.Queue.Count(queue.ItemStateFailed),
+		n.Queue.Count(queue.ItemStateFiltered),
+		n.Queue.Count(queue.ItemStateFinished), finishedName)
+}
+
+// handleWait checks if the resource of the item has been removed by listing its resource type again. This differs
+// from the libnuke HandleWait function in three ways: the listed resources are compared with resources.SameResource,
+// so the queued resource keeps the properties that were set before it was enqueued, the listings are cached per scope
+// instead of per region, the same region is listed with different options for every subscription, and in stop mode
+// the item is finished once the listed resource is stopped.
+func handleWait(ctx context.Context, n *libnuke.Nuke, item *queue.Item, cache listCache) {
+	if hook, ok := item.Resource.(resource.HandleWaitHook); ok {
+		if err := hook.HandleWait(ctx); err != nil {
+			var waitErr liberrors.ErrWaitResource
+			if errors.As(err, &waitErr) {
+				item.State = queue.ItemStateWaiting
+				return
+			}
+
+			item.State = queue.ItemStateFailed
+			item.Reason = err.Error()
+			return
+		}
+	}
+
+	key := listCacheKey(item)
+
+	listed, ok := cache[key]
+	if !ok {
+		var err error
+		listed, err = item.List(ctx, item.Opts)
+		if err != nil {
+			item.State = queue.ItemStateFailed
+			item.Reason = err.Error()
+			return
+		}
+
+		cache[key] = listed
+	}
+
+	for _, r := range listed {
+		if !resources.SameResource(item.Resource, r) {
+			continue
+		}
+
+		if sGetter, ok := r.(resource.SettingsGetter); ok {
+			sGetter.Settings(n.Settings.Get(item.Type))
+		}
+
+		// Note: a resource that is filtered now is considered to be removed, as libnuke does
+		if checker, ok := r.(resource.Filter); ok {
+			if err := checker.Filter(); err != nil {
+				break
+			}
+		}
+
+		if stopper, ok := r.(resources.Stopper); ok && stopMode(item) && stopper.Stopped() {
+			break
+		}
+
+		return
+	}
+
+	item.State = queue.ItemStateFinished
+	item.Reason = ""
+}
+
+// refuseRemoval filters the items that would be removed in stop mode although their resources cannot be stopped, the
+// resources are filtered before they are enqueued as well, this guards against an item whose state was reset
+func refuseRemoval(item *queue.Item) bool {
+	if !stopMode(item) || resources.CanStop(item.Resource) {
+		return false
+	}
+
+	switch item.GetState() {
+	case queue.ItemStateNew, queue.ItemStateHold, queue.ItemStateNewDependency, queue.ItemStatePendingDependency,
+		queue.ItemStateFailed:
+		item.State = queue.ItemStateFiltered
+		item.Reason = resources.NoStopAction
+		return true
+	default:
+		return false
+	}
+}
+
+// printItem prints the item like libnuke does, except for the items that were stopped instead of removed
+func printItem(item *queue.Item) {
+	if item.GetState() == queue.ItemStateFinished && stopMode(item) {
+		liblog.Log(item.Owner, item.Type, item.Resource, liblog.ReasonSuccess, report.StateStopped)
+		return
+	}
+
+	item.Print()
+}
+
+// stopMode checks if the resource of the item is stopped instead of removed
+func stopMode(item *queue.Item) bool {
+	opts, ok := item.Opts.(*azure.ListerOpts)
+	return ok && opts.Stop
+}
+
+// listCacheKey returns the key of the listing of the resource type of the item with its lister options
+func listCacheKey(item *queue.Item) string {
+	parts := []string{item.Type, item.Owner}
+
+	if opts, ok := item.Opts.(*azure.ListerOpts); ok {
+		parts = append(parts, opts.TenantID, opts.ManagementGroupID, opts.SubscriptionID, opts.ResourceGroup)
+	}
+
+	return strings.Join(parts, "/")
+}
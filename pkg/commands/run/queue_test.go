@@ -0,0 +1,319 @@
+package run
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/filter"
+	libnuke "github.com/ekristen/libnuke/pkg/nuke"
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+	libscanner "github.com/ekristen/libnuke/pkg/scanner"
+	"github.com/ekristen/libnuke/pkg/types"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+	"github.com/ekristen/azure-nuke/resources"
+)
+
+const testWaitResource = "TestWaitResource"
+
+// testWaitListed are the resources the lister of testWaitResource returns per subscription
+var testWaitListed = map[string][]resource.Resource{}
+
+type testWaitLister struct{}
+
+func (l testWaitLister) List(_ context.Context, o interface{}) ([]resource.Resource, error) {
+	return testWaitListed[o.(*azure.ListerOpts).SubscriptionID], nil
+}
+
+func init() {
+	registry.Register(&registry.Registration{
+		Name:     testWaitResource,
+		Scope:    azure.ResourceGroupScope,
+		Resource: &testPlanResource{},
+		Lister:   &testWaitLister{},
+	})
+}
+
+func newTestWaitItem(subscriptionID string) *queue.Item {
+	r := newTestPlanResource("a", false)
+	r.SubscriptionID = ptr.String(subscriptionID)
+
+	// Note: these are set by BeforeEnqueue, the listed resources do not have them
+	r.ResourceGroupLocation = ptr.String("westeurope")
+	r.ResourceGroupTags = map[string]*string{"keep": ptr.String("true")}
+
+	return &queue.Item{
+		Resource: r,
+		State:    queue.ItemStateWaiting,
+		Type:     testWaitResource,
+		Owner:    "eastus",
+		Opts:     &azure.ListerOpts{TenantID: "tenant", SubscriptionID: subscriptionID, ResourceGroup: "rg"},
+	}
+}
+
+func TestHandleWait(t *testing.T) {
+	n := libnuke.New(&libnuke.Parameters{}, filter.Filters{}, nil)
+
+	listedA := newTestPlanResource("a", false)
+	listedA.SubscriptionID = ptr.String("sub-a")
+
+	testWaitListed = map[string][]resource.Resource{
+		"sub-a": {listedA},
+	}
+
+	removed := newTestWaitItem("sub-b")
+	remaining := newTestWaitItem("sub-a")
+
+	// Note: the items share the owner, the listing of sub-b must not be used for sub-a or vice versa
+	cache := make(listCache)
+	handleWait(context.Background(), n, removed, cache)
+	handleWait(context.Background(), n, remaining, cache)
+
+	assert.Equal(t, queue.ItemStateFinished, removed.State)
+	assert.Equal(t, queue.ItemStateWaiting, remaining.State)
+
+	props := remaining.Resource.(resource.PropertyGetter).Properties()
+	assert.Equal(t, "westeurope", props.Get("ResourceGroupLocation"))
+	assert.Equal(t, "true", props.Get("rg-tag:keep"))
+
+	testWaitListed = map[string][]resource.Resource{}
+
+	handleWait(context.Background(), n, remaining, make(listCache))
+	assert.Equal(t, queue.ItemStateFinished, remaining.State)
+	assert.Equal(t, "westeurope", remaining.Resource.(resource.PropertyGetter).Properties().Get("ResourceGroupLocation"))
+}
+
+const testStopResource = "TestStopResource"
+
+type testStoppable struct {
+	*resources.BaseResource `property:",inline"`
+
+	Name       *string
+	PowerState *string
+}
+
+func (r *testStoppable) Remove(_ context.Context) error {
+	return nil
+}
+
+func (r *testStoppable) StopAction() string {
+	return "deallocate"
+}
+
+func (r *testStoppable) Stopped() bool {
+	return ptr.ToString(r.PowerState) == "deallocated"
+}
+
+func (r *testStoppable) Stop(_ context.Context) error {
+	return nil
+}
+
+func (r *testStoppable) VolatileProperties() []string {
+	return []string{"PowerState"}
+}
+
+func (r *testStoppable) Properties() types.Properties {
+	return types.NewPropertiesFromStruct(r)
+}
+
+func (r *testStoppable) String() string {
+	return ptr.ToString(r.Name)
+}
+
+func init() {
+	registry.Register(&registry.Registration{
+		Name:     testStopResource,
+		Scope:    azure.ResourceGroupScope,
+		Resource: &testStoppable{},
+		Lister:   &testWaitLister{},
+	})
+}
+
+func newTestStoppable(powerState string) *testStoppable {
+	return &testStoppable{
+		BaseResource: &resources.BaseResource{SubscriptionID: ptr.String("sub-a"), ResourceGroup: ptr.String("rg")},
+		Name:         ptr.String("vm"),
+		PowerState:   ptr.String(powerState),
+	}
+}
+
+func TestHandleWaitStopMode(t *testing.T) {
+	n := libnuke.New(&libnuke.Parameters{}, filter.Filters{}, nil)
+
+	cases := map[string]struct {
+		stop   bool
+		listed []resource.Resource
+		state  queue.ItemState
+	}{
+		"removing": {
+			listed: []resource.Resource{newTestStoppable("deallocating")},
+			state:  queue.ItemStateWaiting,
+		},
+		"removed": {
+			state: queue.ItemStateFinished,
+		},
+		"stopping": {
+			stop:   true,
+			listed: []resource.Resource{newTestStoppable("deallocating")},
+			state:  queue.ItemStateWaiting,
+		},
+		"stopped": {
+			stop:   true,
+			listed: []resource.Resource{newTestStoppable("deallocated")},
+			state:  queue.ItemStateFinished,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			testWaitListed = map[string][]resource.Resource{"sub-a": tc.listed}
+
+			item := &queue.Item{
+				Resource: newTestStoppable("running"),
+				State:    queue.ItemStateWaiting,
+				Type:     testStopResource,
+				Opts:     &azure.ListerOpts{SubscriptionID: "sub-a", ResourceGroup: "rg", Stop: tc.stop},
+			}
+
+			handleWait(context.Background(), n, item, make(listCache))
+			assert.Equal(t, tc.state, item.State)
+		})
+	}
+}
+
+const testDependentResource = "TestDependentResource"
+
+// testDependentListed are the resources the lister of testDependentResource returns
+var testDependentListed []resource.Resource
+
+type testDependentLister struct{}
+
+func (l testDependentLister) List(_ context.Context, _ interface{}) ([]resource.Resource, error) {
+	return testDependentListed, nil
+}
+
+// testDependent is a resource that depends on another resource type and cannot be stopped
+type testDependent struct {
+	*resources.BaseResource `property:",inline"`
+
+	Name    *string
+	removed bool
+}
+
+func (r *testDependent) Remove(_ context.Context) error {
+	r.removed = true
+	return nil
+}
+
+func (r *testDependent) Properties() types.Properties {
+	return types.NewPropertiesFromStruct(r)
+}
+
+func (r *testDependent) String() string {
+	return ptr.ToString(r.Name)
+}
+
+func init() {
+	registry.Register(&registry.Registration{
+		Name:      testDependentResource,
+		Scope:     azure.ResourceGroupScope,
+		Resource:  &testDependent{},
+		Lister:    &testDependentLister{},
+		DependsOn: []string{testStopResource},
+	})
+}
+
+func TestHandleQueueWaitingOnDependencies(t *testing.T) {
+	quarantine, err := azure.NewQuarantine(filepath.Join(t.TempDir(), "quarantine.json"), time.Hour)
+	assert.NoError(t, err)
+
+	groups := azure.NewResourceGroupIndex()
+	groups.Add("sub-a", &azure.ResourceGroupDetails{Name: "rg", Location: "eastus", Filtered: true})
+
+	locks := azure.NewLocks()
+	locks.Add(&azure.Lock{
+		ID:    "/subscriptions/sub-a/resourceGroups/rg/providers/Microsoft.Authorization/locks/keep",
+		Name:  "keep",
+		Level: "CanNotDelete",
+		Scope: "/subscriptions/sub-a/resourceGroups/rg",
+		Kept:  true,
+	})
+
+	cases := map[string]struct {
+		opts   *azure.ListerOpts
+		reason string
+	}{
+		"filtered resource group": {
+			opts:   &azure.ListerOpts{SubscriptionID: "sub-a", ResourceGroup: "rg", ResourceGroupDetails: groups},
+			reason: "resource group rg is filtered",
+		},
+		"stop mode": {
+			opts:   &azure.ListerOpts{SubscriptionID: "sub-a", ResourceGroup: "rg", Stop: true},
+			reason: resources.NoStopAction,
+		},
+		"quarantine": {
+			opts:   &azure.ListerOpts{SubscriptionID: "sub-a", ResourceGroup: "rg", Quarantine: quarantine},
+			reason: "marking for removal after",
+		},
+		"kept lock": {
+			opts:   &azure.ListerOpts{SubscriptionID: "sub-a", ResourceGroup: "rg", Locks: locks},
+			reason: "protected by lock keep",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &testDependent{
+				BaseResource: &resources.BaseResource{
+					Region: ptr.String("eastus"), SubscriptionID: ptr.String("sub-a"), ResourceGroup: ptr.String("rg"),
+				},
+				Name: ptr.String("a"),
+			}
+			testDependentListed = []resource.Resource{r}
+
+			n := libnuke.New(&libnuke.Parameters{WaitOnDependencies: true}, filter.Filters{}, nil)
+			assert.NoError(t, n.RegisterScanner(azure.ResourceGroupScope,
+				libscanner.New("sub/sub-a/rg/rg", []string{testDependentResource}, tc.opts)))
+
+			// Note: libnuke resets the state of the items with dependencies after BeforeEnqueue
+			assert.NoError(t, n.Scan(context.Background()))
+
+			items := n.Queue.GetItems()
+			if !assert.Len(t, items, 1) {
+				return
+			}
+
+			assert.Equal(t, queue.ItemStateFiltered, items[0].GetState())
+			assert.Contains(t, items[0].GetReason(), tc.reason)
+
+			handleQueue(context.Background(), n, "finished")
+			assert.False(t, r.removed)
+		})
+	}
+}
+
+func TestHandleQueueRefusesRemovalInStopMode(t *testing.T) {
+	r := &testDependent{BaseResource: &resources.BaseResource{}, Name: ptr.String("a")}
+
+	n := libnuke.New(&libnuke.Parameters{}, filter.Filters{}, nil)
+	n.Queue = queue.New()
+	n.Queue.Items = append(n.Queue.Items, &queue.Item{
+		Resource: r,
+		State:    queue.ItemStateNewDependency,
+		Type:     testDependentResource,
+		Opts:     &azure.ListerOpts{Stop: true},
+	})
+
+	handleQueue(context.Background(), n, "stopped")
+
+	assert.False(t, r.removed)
+	assert.Equal(t, queue.ItemStateFiltered, n.Queue.GetItems()[0].GetState())
+	assert.Equal(t, resources.NoStopAction, n.Queue.GetItems()[0].GetReason())
+}
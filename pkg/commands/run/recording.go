@@ -0,0 +1,73 @@
+package run
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/hashicorp/go-azure-sdk/sdk/environments"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+// traffic records the azure api traffic of a run to a directory, or replays a recording instead of talking to azure.
+// At most one of the recorder and replayer is set.
+type traffic struct {
+	recorder *azure.Recorder
+	replayer *azure.Replayer
+}
+
+// newTraffic creates the recorder or replayer requested by the --record and --replay flags. It returns nil if neither
+// is requested.
+func newTraffic(c *cli.Context) (*traffic, error) {
+	switch {
+	case c.Path("record") != "" && c.Path("replay") != "":
+		return nil, fmt.Errorf("--record cannot be used with --replay")
+	case c.Path("record") != "":
+		env, err := environments.FromName(c.String("environment"))
+		if err != nil {
+			return nil, err
+		}
+
+		recorder, err := azure.NewRecorder(c.Path("record"), env)
+		if err != nil {
+			return nil, err
+		}
+
+		return &traffic{recorder: recorder}, nil
+	case c.Path("replay") != "":
+		// Note: a replayed run never talks to azure, so there is nothing that could be removed or checked
+		if c.Bool("no-dry-run") {
+			return nil, fmt.Errorf("--replay can only be used with a dry run")
+		}
+
+		if c.Bool("preflight") {
+			return nil, fmt.Errorf("--replay cannot be used with --preflight")
+		}
+
+		replayer, err := azure.NewReplayer(c.Path("replay"))
+		if err != nil {
+			return nil, err
+		}
+
+		return &traffic{replayer: replayer}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// Close stops the replayer and warns about the requests that were not in the recording. This is a no-op on nil.
+func (t *traffic) Close() {
+	if t == nil || t.replayer == nil {
+		return
+	}
+
+	if unmatched := t.replayer.Unmatched(); unmatched > 0 {
+		logrus.
+			WithField("component", "replayer").
+			Warnf("%d requests were not in the recording, the run may differ from the recorded run", unmatched)
+	}
+
+	_ = t.replayer.Close()
+}
@@ -0,0 +1,94 @@
+package run
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotidy/ptr"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+	"github.com/ekristen/azure-nuke/pkg/terraform"
+	"github.com/ekristen/azure-nuke/resources"
+)
+
+// filterResourceGroups marks the resource groups of the tenant that are filtered, the resources in a filtered
+// resource group are then filtered as well instead of being removed from a resource group that is kept. The filtered
+// function decides if a resource group is filtered, the resource groups are listed by the lister and enqueued like
+// during the scan so that the same properties can be filtered on.
+func filterResourceGroups(
+	ctx context.Context, tenant *azure.Tenant, lister registry.Lister, tfState *terraform.State,
+	filtered func(item *queue.Item) bool,
+) {
+	logger := logrus.WithField("component", "resource-groups").WithField("tenant_id", tenant.ID)
+
+	count := 0
+	for _, subscriptionID := range tenant.SubscriptionIds {
+		opts := &azure.ListerOpts{
+			Authorizers:    tenant.Authorizers,
+			TenantID:       tenant.ID,
+			SubscriptionID: subscriptionID,
+			Terraform:      tfState,
+		}
+
+		listed, err := lister.List(ctx, opts)
+		if err != nil {
+			// Note: the resource groups are still filtered by the properties that are known from the discovery
+			logger.WithError(err).WithField("subscription_id", subscriptionID).
+				Warn("unable to list resource groups, filtering them by their location and tags only")
+			listed = discoveredResourceGroups(tenant, subscriptionID)
+		}
+
+		for _, r := range listed {
+			group, ok := r.(*resources.ResourceGroup)
+			if !ok {
+				continue
+			}
+
+			details := tenant.ResourceGroupDetails.Get(subscriptionID, ptr.ToString(group.Name))
+			if details == nil {
+				continue
+			}
+
+			item := &queue.Item{
+				Resource: group,
+				State:    queue.ItemStateNew,
+				Type:     resources.ResourceGroupResource,
+				Owner:    group.GetRegion(),
+				Opts:     opts,
+			}
+
+			var hook resource.QueueItemHook = group
+			hook.BeforeEnqueue(item)
+
+			details.Filtered = filtered(item)
+			if details.Filtered {
+				count++
+			}
+		}
+	}
+
+	logger.Debugf("filtering the resources of %d resource groups", count)
+}
+
+// discoveredResourceGroups returns the resource groups of the subscription as they are known from the discovery
+func discoveredResourceGroups(tenant *azure.Tenant, subscriptionID string) []resource.Resource {
+	var groups []resource.Resource
+	for _, group := range tenant.ResourceGroupDetails.All(subscriptionID) {
+		groups = append(groups, &resources.ResourceGroup{
+			BaseResource: &resources.BaseResource{
+				Region:         ptr.String(group.Location),
+				SubscriptionID: ptr.String(subscriptionID),
+				Tags:           group.Tags,
+			},
+			ID:   ptr.String(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", subscriptionID, group.Name)),
+			Name: ptr.String(group.Name),
+		})
+	}
+
+	return groups
+}
@@ -0,0 +1,111 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/filter"
+	libnuke "github.com/ekristen/libnuke/pkg/nuke"
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+	"github.com/ekristen/azure-nuke/resources"
+)
+
+// testResourceGroupLister returns the listed resource groups, or the error
+type testResourceGroupLister struct {
+	listed []resource.Resource
+	err    error
+}
+
+func (l *testResourceGroupLister) List(_ context.Context, _ interface{}) ([]resource.Resource, error) {
+	return l.listed, l.err
+}
+
+func TestFilterResourceGroups(t *testing.T) {
+	const subscriptionID = "sub-a"
+
+	group := func(name string) *resources.ResourceGroup {
+		return &resources.ResourceGroup{
+			BaseResource: &resources.BaseResource{
+				Region:         ptr.String("westeurope"),
+				SubscriptionID: ptr.String(subscriptionID),
+				SystemData:     &azure.SystemData{CreatedBy: ptr.String("pipeline@example.com")},
+			},
+			ID:   ptr.String(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", subscriptionID, name)),
+			Name: ptr.String(name),
+		}
+	}
+
+	filters := filter.Filters{
+		resources.ResourceGroupResource: []filter.Filter{
+			{Property: "ResourceID", Type: filter.Exact, Value: "/subscriptions/sub-a/resourceGroups/by-id"},
+			{Property: "CreatedBy", Type: filter.Exact, Value: "someone@example.com"},
+			{Property: "tag:keep", Type: filter.Exact, Value: "true"},
+		},
+	}
+
+	cases := map[string]struct {
+		lister   *testResourceGroupLister
+		filtered []string
+	}{
+		"listed": {
+			lister: &testResourceGroupLister{listed: []resource.Resource{
+				group("by-id"),
+				func() resource.Resource {
+					g := group("by-creator")
+					g.CreatedBy = ptr.String("someone@example.com")
+					return g
+				}(),
+				group("kept-by-tag"),
+				group("removed"),
+			}},
+			filtered: []string{"by-creator", "by-id"},
+		},
+		"listing failed": {
+			lister:   &testResourceGroupLister{err: fmt.Errorf("failed")},
+			filtered: []string{"by-id", "kept-by-tag"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tenant := &azure.Tenant{
+				ID:                   "tenant",
+				SubscriptionIds:      []string{subscriptionID},
+				ResourceGroupDetails: azure.NewResourceGroupIndex(),
+			}
+
+			for _, name := range []string{"by-creator", "by-id", "kept-by-tag", "removed"} {
+				details := &azure.ResourceGroupDetails{Name: name, Location: "westeurope"}
+				if name == "kept-by-tag" {
+					// Note: the tags of the discovery are only used when the resource groups could not be listed
+					details.Tags = map[string]*string{"keep": ptr.String("true")}
+				}
+
+				tenant.ResourceGroupDetails.Add(subscriptionID, details)
+			}
+
+			n := libnuke.New(&libnuke.Parameters{}, filters, nil)
+
+			filterResourceGroups(context.Background(), tenant, tc.lister, nil, func(item *queue.Item) bool {
+				assert.NoError(t, n.Filter(item))
+				return item.State == queue.ItemStateFiltered
+			})
+
+			var filtered []string
+			for _, details := range tenant.ResourceGroupDetails.All(subscriptionID) {
+				if details.Filtered {
+					filtered = append(filtered, details.Name)
+				}
+			}
+
+			assert.Equal(t, tc.filtered, filtered)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	libconfig "github.com/ekristen/libnuke/pkg/config"
+	libnuke "github.com/ekristen/libnuke/pkg/nuke"
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/azure-nuke/pkg/commands/global"
+	"github.com/ekristen/azure-nuke/pkg/config"
+)
+
+// Note: the snapshot command lives alongside the run command as it configures the tenants exactly like a run does,
+// it is registered as a subcommand of the config command as its output is configuration.
+
+func executeSnapshot(c *cli.Context) error {
+	ctx, cancel := context.WithCancel(c.Context)
+	defer cancel()
+
+	// Note: the snapshot is written to stdout by default, the logs are written to stderr so that they are not mixed in
+	setupLogging(c, os.Stderr)
+
+	if !slices.Contains(config.SnapshotFormats, c.String("format")) {
+		return fmt.Errorf("unsupported snapshot format: %s", c.String("format"))
+	}
+
+	params := &libnuke.Parameters{
+		Includes: c.StringSlice("include"),
+		Excludes: c.StringSlice("exclude"),
+	}
+
+	parsedConfig, err := config.New(libconfig.Options{
+		Path:         c.Path("config"),
+		Deprecations: registry.GetDeprecatedResourceTypeMapping(),
+	})
+	if err != nil {
+		return err
+	}
+
+	tenantIDs, err := resolveTenantIDs(c, parsedConfig)
+	if err != nil {
+		return err
+	}
+
+	tfState, err := loadTerraformState(c, parsedConfig)
+	if err != nil {
+		return err
+	}
+
+	// Note: a resource whose listing failed would be missing from the snapshot and removed by the next run, so the
+	// snapshot fails instead of being incomplete
+	failures := &listingFailures{}
+	logrus.AddHook(failures)
+
+	snapshot := config.NewSnapshot()
+	for _, tenantID := range tenantIDs {
+		tn, err := newTenantNuke(ctx, c, params, parsedConfig, tenantID, len(tenantIDs) > 1, nil, tfState, nil, nil)
+		if err != nil {
+			return fmt.Errorf("tenant %s: %w", tenantID, err)
+		}
+
+		if err := scanSnapshot(ctx, tn, snapshot); err != nil {
+			return fmt.Errorf("tenant %s: %w", tenantID, err)
+		}
+	}
+
+	if count := failures.count.Load(); count > 0 {
+		return fmt.Errorf("snapshot is incomplete, %d resource type(s) could not be listed", count)
+	}
+
+	var w io.Writer = os.Stdout
+	if c.Path("output") != "" {
+		f, err := os.OpenFile(c.Path("output"), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	if err := snapshot.Write(w, c.String("format"), c.String("preset-name")); err != nil {
+		return err
+	}
+
+	logrus.Infof("snapshot of %d resources written", snapshot.Count())
+
+	return nil
+}
+
+// scanSnapshot runs the scanners of the tenant and adds every resource that a run would remove to the snapshot, the
+// resources are filtered like they are by a run, but they are neither printed nor removed
+func scanSnapshot(ctx context.Context, tn *tenantNuke, snapshot *config.Snapshot) error {
+	for _, s := range tn.scanners {
+		if err := s.Run(ctx); err != nil {
+			return err
+		}
+
+		for item := range s.Items {
+			if sGetter, ok := item.Resource.(resource.SettingsGetter); ok {
+				sGetter.Settings(tn.nuke.Settings.Get(item.Type))
+			}
+
+			if err := tn.nuke.Filter(item); err != nil {
+				return err
+			}
+
+			if item.State != queue.ItemStateNew {
+				continue
+			}
+
+			if !snapshot.Add(tn.tenant.ID, item.Type, item.Resource) {
+				logrus.
+					WithField("component", "snapshot").
+					WithField("resource_type", item.Type).
+					Warnf("resource has no property that identifies it, it is not in the snapshot")
+			}
+		}
+	}
+
+	return nil
+}
+
+// listingFailures counts the listings that failed, the scanners of libnuke only log these
+type listingFailures struct {
+	count atomic.Int64
+}
+
+func (h *listingFailures) Levels() []logrus.Level {
+	return []logrus.Level{logrus.ErrorLevel}
+}
+
+func (h *listingFailures) Fire(entry *logrus.Entry) error {
+	if strings.HasPrefix(entry.Message, "Listing ") {
+		h.count.Add(1)
+	}
+
+	return nil
+}
+
+// NewSnapshotCommand creates the snapshot command, it is a subcommand of the config command
+func NewSnapshotCommand() *cli.Command {
+	flags := []cli.Flag{
+		&cli.PathFlag{
+			Name:  "config",
+			Usage: "path to config file",
+			Value: "config.yaml",
+		},
+		&cli.PathFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "write the snapshot to a file instead of stdout",
+		},
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "the format of the snapshot (" + strings.Join(config.SnapshotFormats, ", ") + ")",
+			Value: config.SnapshotFormatFilters,
+		},
+		&cli.StringFlag{
+			Name:  "preset-name",
+			Usage: "the name of the preset when the format is preset",
+			Value: "snapshot",
+		},
+		&cli.StringSliceFlag{
+			Name:  "include",
+			Usage: "only include this specific resource",
+		},
+		&cli.StringSliceFlag{
+			Name:  "exclude",
+			Usage: "exclude this specific resource (this overrides everything)",
+		},
+		&cli.StringSliceFlag{
+			Name:    "tenant-id",
+			Usage:   "the tenant-id to snapshot (can be provided multiple times to snapshot multiple tenants)",
+			EnvVars: []string{"AZURE_TENANT_ID"},
+		},
+		&cli.BoolFlag{
+			Name:  "all-tenants",
+			Usage: "snapshot every tenant configured in the accounts section of the config that is not blocklisted",
+		},
+		&cli.StringSliceFlag{
+			Name:    "subscription-id",
+			Usage:   "the subscription-id to snapshot (this filters to 1 or more subscription ids)",
+			EnvVars: []string{"AZURE_SUBSCRIPTION_ID"},
+		},
+	}
+
+	flags = append(flags, authFlags()...)
+
+	return &cli.Command{
+		Name:   "snapshot",
+		Usage:  "generate filters that keep every resource that exists now, without removing anything",
+		Flags:  append(flags, global.Flags()...),
+		Before: global.Before,
+		Action: executeSnapshot,
+	}
+}
@@ -0,0 +1,50 @@
+package run
+
+import (
+	"fmt"
+
+	liblog "github.com/ekristen/libnuke/pkg/log"
+	"github.com/ekristen/libnuke/pkg/queue"
+
+	"github.com/ekristen/azure-nuke/resources"
+)
+
+// printStopActions prints what stopping does to every resource that would be stopped, libnuke prints these resources
+// as if they would be removed
+func printStopActions(tenants ...*tenantNuke) {
+	count := 0
+	for _, tn := range tenants {
+		for _, item := range tn.nuke.Queue.GetItems() {
+			if item.GetState() != queue.ItemStateNew && item.GetState() != queue.ItemStateNewDependency {
+				continue
+			}
+
+			stopper, ok := item.Resource.(resources.Stopper)
+			if !ok {
+				continue
+			}
+
+			liblog.Log(item.Owner, item.Type, item.Resource, liblog.ReasonWaitPending,
+				fmt.Sprintf("would %s", stopper.StopAction()))
+			count++
+		}
+	}
+
+	if count > 0 {
+		fmt.Printf("Stop mode: the above %d resources would be stopped instead of removed.\n\n", count)
+	}
+}
+
+// stopPrompt prints the stop actions before the prompt that confirms the removal, the first call of the prompt
+// happens before scanning
+func stopPrompt(prompt func() error, tn *tenantNuke) func() error {
+	calls := 0
+	return func() error {
+		calls++
+		if calls > 1 {
+			printStopActions(tn)
+		}
+
+		return prompt()
+	}
+}
@@ -0,0 +1,28 @@
+package run
+
+import (
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ekristen/azure-nuke/pkg/config"
+	"github.com/ekristen/azure-nuke/pkg/terraform"
+)
+
+// loadTerraformState loads the terraform states of the configuration, relative to the configuration file. It returns
+// nil if no terraform state is configured.
+func loadTerraformState(c *cli.Context, parsedConfig *config.Config) (*terraform.State, error) {
+	state, err := terraform.Load(filepath.Dir(c.Path("config")), parsedConfig.TerraformStates...)
+	if err != nil {
+		return nil, err
+	}
+
+	if state != nil {
+		logrus.
+			WithField("component", "terraform").
+			Debugf("loaded %d resource ids from %d terraform states", state.Len(), len(parsedConfig.TerraformStates))
+	}
+
+	return state, nil
+}
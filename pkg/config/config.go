@@ -2,8 +2,10 @@ package config
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
 
 	"github.com/ekristen/libnuke/pkg/config"
 )
@@ -48,6 +50,14 @@ func New(opts config.Options) (*Config, error) {
 		c.Blocklist = c.TenantBlocklist
 	}
 
+	for _, matchers := range [][]SubscriptionMatcher{c.SubscriptionBlocklist, c.SubscriptionAllowlist} {
+		for i := range matchers {
+			if err := matchers[i].Validate(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	return c, nil
 }
 
@@ -65,4 +75,42 @@ type Config struct {
 	// nuking your production account.
 	// Deprecated: Use Blocklist instead. Will be removed in 2.x
 	TenantBlocklist []string `yaml:"tenant-blocklist"`
+
+	// Credentials is a map of tenant IDs to the credentials that should be used when authenticating against that
+	// tenant. This is primarily used when running against multiple tenants.
+	Credentials map[string]*Credentials `yaml:"credentials"`
+
+	// ManagementGroups is a map of tenant IDs to the management groups (by name or display name) that subscriptions
+	// must belong to, either directly or through a descendant management group, in order to be nuked. This also
+	// limits the management group scanners to those management groups and their descendants.
+	ManagementGroups map[string][]string `yaml:"management-groups"`
+
+	// SubscriptionBlocklist is a list of subscriptions that must never be nuked, even if they share a tenant with
+	// subscriptions that are. Subscriptions can be matched by ID, display name glob or tag.
+	SubscriptionBlocklist []SubscriptionMatcher `yaml:"subscription-blocklist"`
+
+	// SubscriptionAllowlist is a list of subscriptions that are allowed to be nuked, if it is configured, any
+	// subscription that does not match an entry is skipped. Subscriptions can be matched by ID, display name glob
+	// or tag.
+	SubscriptionAllowlist []SubscriptionMatcher `yaml:"subscription-allowlist"`
+
+	// TerraformStates are terraform state files (or the output of `terraform show -json`) of the resources that are
+	// managed by terraform, relative paths are resolved against the directory of the configuration file.
+	TerraformStates []string `yaml:"terraform-states"`
+}
+
+// GetManagementGroups returns the management groups configured for a tenant.
+func (c *Config) GetManagementGroups(tenantID string) []string {
+	return c.ManagementGroups[tenantID]
+}
+
+// Load loads the configuration file against the extended configuration. This has to be defined, otherwise the Load
+// from the embedded libnuke configuration is used, which only knows about the libnuke attributes.
+func (c *Config) Load(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return yaml.Unmarshal(raw, c)
 }
@@ -65,3 +65,129 @@ func TestLoadExampleConfig(t *testing.T) {
 
 	assert.Equal(t, expect, *config)
 }
+
+func TestLoadDeprecatedKeysConfig(t *testing.T) {
+	config, err := New(libconfig.Options{
+		Path: "testdata/deprecated-keys-config.yaml",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{"1234567890"}, config.Blocklist)
+}
+
+func TestGetCredentials(t *testing.T) {
+	t.Setenv("TEST_SANDBOX_CLIENT_SECRET", "super-secret")
+
+	config, err := New(libconfig.Options{
+		Path: "testdata/credentials.yaml",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	creds := config.GetCredentials("efda01a1-e2e4-4024-89f0-eb29793c605b")
+	assert.NotNil(t, creds)
+	assert.Equal(t, "6e1a6e0c-4a0f-4c4b-9a2e-1b1b7f0b4d7e", creds.ClientID)
+	assert.Equal(t, "super-secret", creds.ClientSecret)
+	assert.Empty(t, creds.ClientCertificateFile)
+
+	assert.Nil(t, config.GetCredentials("2d5b0c55-8bd6-4cf4-9a55-3a4b2a3f1f6e"))
+}
+
+func TestGetManagementGroups(t *testing.T) {
+	config, err := New(libconfig.Options{
+		Path: "testdata/management-groups.yaml",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{"Sandbox"}, config.GetManagementGroups("efda01a1-e2e4-4024-89f0-eb29793c605b"))
+	assert.Empty(t, config.GetManagementGroups("2d5b0c55-8bd6-4cf4-9a55-3a4b2a3f1f6e"))
+}
+
+func TestTerraformStates(t *testing.T) {
+	config, err := New(libconfig.Options{
+		Path: "testdata/terraform-states.yaml",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{"network/terraform.tfstate", "/var/lib/terraform/identity.json"}, config.TerraformStates)
+}
+
+func TestSubscriptionLists(t *testing.T) {
+	config, err := New(libconfig.Options{
+		Path: "testdata/subscriptions.yaml",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "7b3c5a1e-8a8e-4c7d-9d0e-3f1b2c4d5e6f", config.SubscriptionBlocklist[0].ID)
+
+	cases := []struct {
+		name        string
+		id          string
+		displayName string
+		tags        map[string]string
+		blocklisted bool
+		allowed     bool
+	}{
+		{
+			name:        "blocklisted-by-id",
+			id:          "7B3C5A1E-8A8E-4C7D-9D0E-3F1B2C4D5E6F",
+			displayName: "sandbox-one",
+			blocklisted: true,
+			allowed:     true,
+		},
+		{
+			name:        "blocklisted-by-name",
+			id:          "00000000-0000-0000-0000-000000000001",
+			displayName: "prod-core",
+			blocklisted: true,
+		},
+		{
+			name:        "blocklisted-by-tag",
+			id:          "00000000-0000-0000-0000-000000000002",
+			displayName: "sandbox-two",
+			tags:        map[string]string{"environment": "production"},
+			blocklisted: true,
+			allowed:     true,
+		},
+		{
+			name:        "allowed-by-name",
+			id:          "00000000-0000-0000-0000-000000000003",
+			displayName: "sandbox-three",
+			tags:        map[string]string{"environment": "sandbox"},
+			allowed:     true,
+		},
+		{
+			name:        "allowed-by-tag",
+			id:          "00000000-0000-0000-0000-000000000004",
+			displayName: "testing",
+			tags:        map[string]string{"nuke": ""},
+			allowed:     true,
+		},
+		{
+			name:        "not-allowed",
+			id:          "00000000-0000-0000-0000-000000000005",
+			displayName: "shared-services",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			blocklisted, err := config.InSubscriptionBlocklist(tc.id, tc.displayName, tc.tags)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.blocklisted, blocklisted)
+
+			allowed, err := config.InSubscriptionAllowlist(tc.id, tc.displayName, tc.tags)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.allowed, allowed)
+		})
+	}
+}
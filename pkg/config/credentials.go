@@ -0,0 +1,30 @@
+package config
+
+import "os"
+
+// Credentials are the credentials used to authenticate against a specific tenant. All values are expanded against
+// the environment, so secrets can be referenced (e.g. `${SANDBOX_CLIENT_SECRET}`) instead of stored in the file.
+type Credentials struct {
+	AuthMethod               string `yaml:"auth-method"`
+	ClientID                 string `yaml:"client-id"`
+	ClientSecret             string `yaml:"client-secret"`
+	ClientCertificateFile    string `yaml:"client-certificate-file"`
+	ClientFederatedTokenFile string `yaml:"client-federated-token-file"`
+}
+
+// GetCredentials returns the credentials configured for the tenant with all environment variables expanded, if
+// there are no credentials configured for the tenant, nil is returned.
+func (c *Config) GetCredentials(tenantID string) *Credentials {
+	creds, ok := c.Credentials[tenantID]
+	if !ok || creds == nil {
+		return nil
+	}
+
+	return &Credentials{
+		AuthMethod:               os.ExpandEnv(creds.AuthMethod),
+		ClientID:                 os.ExpandEnv(creds.ClientID),
+		ClientSecret:             os.ExpandEnv(creds.ClientSecret),
+		ClientCertificateFile:    os.ExpandEnv(creds.ClientCertificateFile),
+		ClientFederatedTokenFile: os.ExpandEnv(creds.ClientFederatedTokenFile),
+	}
+}
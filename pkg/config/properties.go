@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ResourceProperties are the property names a resource type can be filtered on, they are derived from the struct of
+// the resource the same way `types.NewPropertiesFromStruct` derives the properties of a listed resource.
+type ResourceProperties struct {
+	// Names are the property names of the resource, sorted
+	Names []string
+	// TagPrefixes are the prefixes of the tag properties of the resource (i.e. `tag:`), a tag property is a prefix
+	// followed by a free-form tag key, so only the prefix can be validated
+	TagPrefixes []string
+}
+
+// NewResourceProperties returns the property names of the resource, the resource is the value registered as the
+// `Resource` of the registration, its fields do not need to be set.
+func NewResourceProperties(resource interface{}) *ResourceProperties {
+	p := &ResourceProperties{}
+
+	if resource == nil {
+		return p
+	}
+
+	t := reflect.TypeOf(resource)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return p
+	}
+
+	p.addStruct(t, "tag")
+
+	slices.Sort(p.Names)
+	p.Names = slices.Compact(p.Names)
+	slices.Sort(p.TagPrefixes)
+	p.TagPrefixes = slices.Compact(p.TagPrefixes)
+
+	return p
+}
+
+// Has checks if the property exists on the resource, tag properties exist if the resource has tags with the prefix
+func (p *ResourceProperties) Has(property string) bool {
+	if slices.Contains(p.Names, property) {
+		return true
+	}
+
+	for _, prefix := range p.TagPrefixes {
+		if strings.HasPrefix(property, prefix) && len(property) > len(prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addStruct mirrors the field handling of `types.Properties.SetFromStruct`, including the `property` struct tag
+// options `-`, `inline`, `name=`, `prefix=` and `tagPrefix=`.
+func (p *ResourceProperties) addStruct(t reflect.Type, tagPrefix string) { //nolint:gocyclo
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		options := strings.Split(field.Tag.Get("property"), ",")
+		if options[0] == "-" {
+			continue
+		}
+
+		name := field.Name
+		prefix := ""
+		inline := len(options) == 2 && options[1] == "inline"
+
+		for _, option := range options {
+			parts := strings.Split(option, "=")
+			if len(parts) != 2 {
+				continue
+			}
+
+			switch parts[0] {
+			case "name":
+				name = parts[1]
+			case "prefix":
+				prefix = parts[1]
+			case "tagPrefix":
+				tagPrefix = parts[1]
+			}
+		}
+
+		ft := field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if inline {
+			if ft.Kind() == reflect.Struct {
+				p.addStruct(ft, tagPrefix)
+			}
+			continue
+		}
+
+		switch ft.Kind() {
+		case reflect.Struct:
+			if ft == reflect.TypeOf(time.Time{}) {
+				p.Names = append(p.Names, withPrefix(prefix, name))
+			}
+		case reflect.Map:
+			p.TagPrefixes = append(p.TagPrefixes, tagPropertyPrefix(tagPrefix, prefix))
+		case reflect.Slice:
+			// Note: only slices of key/value structs are turned into tags, any other slice is not a property
+			et := ft.Elem()
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct {
+				p.TagPrefixes = append(p.TagPrefixes, tagPropertyPrefix(tagPrefix, prefix))
+			}
+		default:
+			p.Names = append(p.Names, withPrefix(prefix, name))
+		}
+	}
+}
+
+func withPrefix(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%s:%s", prefix, name)
+}
+
+func tagPropertyPrefix(tagPrefix, prefix string) string {
+	if prefix == "" {
+		return fmt.Sprintf("%s:", tagPrefix)
+	}
+
+	return fmt.Sprintf("%s:%s:", tagPrefix, prefix)
+}
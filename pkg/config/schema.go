@@ -0,0 +1,254 @@
+package config
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+
+	"github.com/ekristen/libnuke/pkg/filter"
+	"github.com/ekristen/libnuke/pkg/registry"
+)
+
+// SchemaID is the identifier of the generated JSON Schema
+const SchemaID = "https://github.com/ekristen/azure-nuke/config.schema.json"
+
+// Schema generates a JSON Schema of the configuration for editor autocompletion and validation. The resource types and
+// their property names are taken from the registry, so the schema matches the version of azure-nuke generating it.
+func Schema() map[string]interface{} {
+	names := registry.GetNames()
+	sort.Strings(names)
+
+	filterProperties := map[string]interface{}{
+		filter.Global: filterList(globalProperties(names)),
+	}
+	settingsProperties := map[string]interface{}{}
+
+	for _, name := range names {
+		reg := registry.GetRegistration(name)
+
+		filterProperties[name] = filterList(NewResourceProperties(reg.Resource))
+
+		if len(reg.Settings) > 0 {
+			settingProperties := map[string]interface{}{}
+			for _, setting := range reg.Settings {
+				settingProperties[setting] = map[string]interface{}{}
+			}
+
+			settingsProperties[name] = map[string]interface{}{
+				"type":                 "object",
+				"properties":           settingProperties,
+				"additionalProperties": false,
+			}
+		}
+	}
+
+	stringList := map[string]interface{}{
+		"type":  "array",
+		"items": map[string]interface{}{"type": "string"},
+	}
+
+	subscriptionMatchers := map[string]interface{}{
+		"type":  "array",
+		"items": ref("subscriptionMatcher"),
+	}
+
+	return map[string]interface{}{
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"$id":                  SchemaID,
+		"title":                "azure-nuke configuration",
+		"type":                 "object",
+		"additionalProperties": false,
+		"properties": map[string]interface{}{
+			"blocklist": describe(stringList, "Tenant IDs that must never be nuked."),
+			"regions": describe(map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":     "string",
+					"examples": []string{"global", "all", "eastus"},
+					"pattern":  regionPattern.String(),
+				},
+			}, "Regions to nuke, `global` includes tenant, management group and subscription scoped resources."),
+			"accounts": describe(map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": ref("account"),
+			}, "Tenants to nuke, keyed by tenant ID."),
+			"resource-types": ref("resourceTypes"),
+			"presets": describe(map[string]interface{}{
+				"type": "object",
+				"additionalProperties": map[string]interface{}{
+					"type":                 "object",
+					"additionalProperties": false,
+					"properties": map[string]interface{}{
+						"filters": ref("filters"),
+					},
+				},
+			}, "Named sets of filters that can be used by any account."),
+			"settings": describe(map[string]interface{}{
+				"type":                 "object",
+				"properties":           settingsProperties,
+				"additionalProperties": false,
+			}, "Settings of resource types, keyed by resource type."),
+			"credentials": describe(map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": ref("credentials"),
+			}, "Credentials to authenticate against a tenant with, keyed by tenant ID."),
+			"management-groups": describe(map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": stringList,
+			}, "Management groups that subscriptions must belong to, keyed by tenant ID."),
+			"subscription-blocklist": describe(subscriptionMatchers, "Subscriptions that must never be nuked."),
+			"subscription-allowlist": describe(subscriptionMatchers, "Subscriptions that are allowed to be nuked."),
+			"terraform-states": describe(stringList,
+				"Terraform state files or `terraform show -json` output of the resources that are managed by terraform."),
+			"tenants": deprecated(map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": ref("account"),
+			}, "Use `accounts` instead."),
+			"tenant-blocklist":  deprecated(stringList, "Use `blocklist` instead."),
+			"account-blocklist": deprecated(stringList, "Use `blocklist` instead."),
+			"account-blacklist": deprecated(stringList, "Use `blocklist` instead."),
+		},
+		"definitions": map[string]interface{}{
+			"resourceType": map[string]interface{}{
+				"type": "string",
+				"enum": names,
+			},
+			"resourceTypes": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": false,
+				"properties": map[string]interface{}{
+					"includes":     resourceTypeList(),
+					"excludes":     resourceTypeList(),
+					"alternatives": resourceTypeList(),
+				},
+			},
+			"account": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": false,
+				"properties": map[string]interface{}{
+					"filters":        ref("filters"),
+					"resource-types": ref("resourceTypes"),
+					"presets":        stringList,
+				},
+			},
+			"filters": map[string]interface{}{
+				"type":                 "object",
+				"properties":           filterProperties,
+				"additionalProperties": false,
+			},
+			"credentials": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": false,
+				"properties": map[string]interface{}{
+					"auth-method":                 map[string]interface{}{"type": "string"},
+					"client-id":                   map[string]interface{}{"type": "string"},
+					"client-secret":               map[string]interface{}{"type": "string"},
+					"client-certificate-file":     map[string]interface{}{"type": "string"},
+					"client-federated-token-file": map[string]interface{}{"type": "string"},
+				},
+			},
+			"subscriptionMatcher": map[string]interface{}{
+				"oneOf": []interface{}{
+					map[string]interface{}{"type": "string"},
+					map[string]interface{}{
+						"type":                 "object",
+						"additionalProperties": false,
+						"minProperties":        1,
+						"properties": map[string]interface{}{
+							"id":   map[string]interface{}{"type": "string"},
+							"name": map[string]interface{}{"type": "string"},
+							"tag":  map[string]interface{}{"type": "string"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// globalProperties returns the properties of all resource types, as global filters apply to every resource type
+func globalProperties(names []string) *ResourceProperties {
+	properties := &ResourceProperties{}
+	for _, name := range names {
+		p := NewResourceProperties(registry.GetRegistration(name).Resource)
+		properties.Names = append(properties.Names, p.Names...)
+		properties.TagPrefixes = append(properties.TagPrefixes, p.TagPrefixes...)
+	}
+
+	slices.Sort(properties.Names)
+	properties.Names = slices.Compact(properties.Names)
+	slices.Sort(properties.TagPrefixes)
+	properties.TagPrefixes = slices.Compact(properties.TagPrefixes)
+
+	return properties
+}
+
+// filterList returns the schema of the filters of a resource type, a filter is either a string that is matched
+// against the string representation of the resource or an object that matches a property
+func filterList(properties *ResourceProperties) map[string]interface{} {
+	property := map[string]interface{}{"type": "string"}
+
+	var propertyOf []interface{}
+	if len(properties.Names) > 0 {
+		propertyOf = append(propertyOf, map[string]interface{}{"enum": properties.Names})
+	}
+	for _, prefix := range properties.TagPrefixes {
+		propertyOf = append(propertyOf, map[string]interface{}{"pattern": "^" + regexp.QuoteMeta(prefix) + ".+"})
+	}
+	if len(propertyOf) > 0 {
+		property["anyOf"] = propertyOf
+	}
+
+	types := make([]string, 0, len(filterTypes))
+	for _, t := range filterTypes[1:] {
+		types = append(types, string(t))
+	}
+
+	return map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"oneOf": []interface{}{
+				map[string]interface{}{"type": "string"},
+				map[string]interface{}{
+					"type":                 "object",
+					"additionalProperties": false,
+					"properties": map[string]interface{}{
+						"property": property,
+						"type":     map[string]interface{}{"type": "string", "enum": types},
+						"value":    map[string]interface{}{"type": "string"},
+						"values":   map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+						"invert":   map[string]interface{}{"type": []string{"boolean", "string"}},
+						"group":    map[string]interface{}{"type": "string"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceTypeList() map[string]interface{} {
+	return map[string]interface{}{
+		"type":  "array",
+		"items": ref("resourceType"),
+	}
+}
+
+func ref(definition string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/definitions/" + definition}
+}
+
+func describe(schema map[string]interface{}, description string) map[string]interface{} {
+	described := map[string]interface{}{"description": description}
+	for k, v := range schema {
+		described[k] = v
+	}
+
+	return described
+}
+
+func deprecated(schema map[string]interface{}, description string) map[string]interface{} {
+	described := describe(schema, "Deprecated: "+description)
+	described["deprecated"] = true
+
+	return described
+}
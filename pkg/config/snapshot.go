@@ -0,0 +1,165 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/ekristen/libnuke/pkg/filter"
+	"github.com/ekristen/libnuke/pkg/resource"
+)
+
+const (
+	SnapshotFormatFilters = "filters"
+	SnapshotFormatPreset  = "preset"
+)
+
+// SnapshotFormats are the supported output formats of a snapshot
+var SnapshotFormats = []string{SnapshotFormatFilters, SnapshotFormatPreset}
+
+// snapshotProperties are the properties that identify a resource, in order of preference. The ResourceID is the ARM
+// resource ID or the object ID of Entra ID objects, the others are for the resources that do not have an ID.
+var snapshotProperties = []string{"ResourceID", "KeyID", "Name"}
+
+// SnapshotFilter is an exact filter of a single resource, it is a subset of the libnuke filter so that only the
+// attributes that are set are written
+type SnapshotFilter struct {
+	Type     filter.Type `yaml:"type"`
+	Property string      `yaml:"property,omitempty"`
+	Value    string      `yaml:"value"`
+}
+
+// Snapshot is the set of filters that keep every resource that exists at the time of the snapshot, keyed by tenant
+// ID and resource type
+type Snapshot struct {
+	CreatedAt time.Time
+	Tenants   map[string]map[string][]SnapshotFilter
+}
+
+// NewSnapshot creates an empty snapshot
+func NewSnapshot() *Snapshot {
+	return &Snapshot{
+		CreatedAt: time.Now().UTC(),
+		Tenants:   make(map[string]map[string][]SnapshotFilter),
+	}
+}
+
+// Add adds a filter for the resource to the snapshot, false is returned if the resource has no property that
+// identifies it
+func (s *Snapshot) Add(tenantID, resourceType string, r resource.Resource) bool {
+	f, ok := NewSnapshotFilter(r)
+	if !ok {
+		return false
+	}
+
+	filters, ok := s.Tenants[tenantID]
+	if !ok {
+		filters = make(map[string][]SnapshotFilter)
+		s.Tenants[tenantID] = filters
+	}
+
+	for _, existing := range filters[resourceType] {
+		if existing == f {
+			return true
+		}
+	}
+
+	filters[resourceType] = append(filters[resourceType], f)
+
+	return true
+}
+
+// Count returns the number of filters in the snapshot
+func (s *Snapshot) Count() int {
+	count := 0
+	for _, filters := range s.Tenants {
+		for _, resourceFilters := range filters {
+			count += len(resourceFilters)
+		}
+	}
+
+	return count
+}
+
+// NewSnapshotFilter returns the exact filter that matches the resource, the first of the snapshotProperties that is
+// set is used, resources without any of them are matched by their legacy string
+func NewSnapshotFilter(r resource.Resource) (SnapshotFilter, bool) {
+	if getter, ok := r.(resource.PropertyGetter); ok {
+		properties := getter.Properties()
+		for _, property := range snapshotProperties {
+			if value := properties.Get(property); value != "" {
+				return SnapshotFilter{Type: filter.Exact, Property: property, Value: value}, true
+			}
+		}
+	}
+
+	if stringer, ok := r.(resource.LegacyStringer); ok && stringer.String() != "" {
+		return SnapshotFilter{Type: filter.Exact, Value: stringer.String()}, true
+	}
+
+	return SnapshotFilter{}, false
+}
+
+// Write writes the snapshot as YAML. The filters format writes the filters of every tenant as an accounts block, the
+// preset format writes them as a single preset with the name, which the accounts have to reference.
+func (s *Snapshot) Write(w io.Writer, format, name string) error {
+	tenantIDs := make([]string, 0, len(s.Tenants))
+	for tenantID, filters := range s.Tenants {
+		tenantIDs = append(tenantIDs, tenantID)
+
+		for _, resourceFilters := range filters {
+			sort.Slice(resourceFilters, func(i, j int) bool {
+				if resourceFilters[i].Property != resourceFilters[j].Property {
+					return resourceFilters[i].Property < resourceFilters[j].Property
+				}
+				return resourceFilters[i].Value < resourceFilters[j].Value
+			})
+		}
+	}
+	sort.Strings(tenantIDs)
+
+	var out interface{}
+	var header string
+
+	switch format {
+	case SnapshotFormatFilters:
+		accounts := make(map[string]interface{}, len(s.Tenants))
+		for tenantID, filters := range s.Tenants {
+			accounts[tenantID] = map[string]interface{}{"filters": filters}
+		}
+
+		out = map[string]interface{}{"accounts": accounts}
+	case SnapshotFormatPreset:
+		merged := make(map[string][]SnapshotFilter)
+		for _, tenantID := range tenantIDs {
+			for resourceType, filters := range s.Tenants[tenantID] {
+				merged[resourceType] = append(merged[resourceType], filters...)
+			}
+		}
+
+		out = map[string]interface{}{
+			"presets": map[string]interface{}{
+				name: map[string]interface{}{"filters": merged},
+			},
+		}
+		header = fmt.Sprintf("# add %q to the presets of the accounts to keep these resources\n", name)
+	default:
+		return fmt.Errorf("unsupported snapshot format: %s", format)
+	}
+
+	data, err := yaml.Marshal(out)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "# snapshot of %d resources taken at %s\n%s",
+		s.Count(), s.CreatedAt.Format(time.RFC3339), header); err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+
+	"github.com/ekristen/libnuke/pkg/filter"
+	"github.com/ekristen/libnuke/pkg/types"
+)
+
+type snapshotTestResource struct {
+	ResourceID *string
+	KeyID      *string
+	Name       *string
+}
+
+func (r *snapshotTestResource) Remove(_ context.Context) error {
+	return nil
+}
+
+func (r *snapshotTestResource) Properties() types.Properties {
+	return types.NewPropertiesFromStruct(r)
+}
+
+type snapshotTestLegacyResource struct {
+	name string
+}
+
+func (r *snapshotTestLegacyResource) Remove(_ context.Context) error {
+	return nil
+}
+
+func (r *snapshotTestLegacyResource) String() string {
+	return r.name
+}
+
+func TestNewSnapshotFilter(t *testing.T) {
+	cases := []struct {
+		name     string
+		resource interface{ Remove(context.Context) error }
+		want     SnapshotFilter
+		ok       bool
+	}{
+		{
+			name: "resource id is preferred",
+			resource: &snapshotTestResource{
+				ResourceID: ptr.String("/subscriptions/sub-a/resourceGroups/rg/providers/Microsoft.Compute/disks/disk"),
+				Name:       ptr.String("disk"),
+			},
+			want: SnapshotFilter{
+				Type:     filter.Exact,
+				Property: "ResourceID",
+				Value:    "/subscriptions/sub-a/resourceGroups/rg/providers/Microsoft.Compute/disks/disk",
+			},
+			ok: true,
+		},
+		{
+			name:     "key id of a credential",
+			resource: &snapshotTestResource{KeyID: ptr.String("key"), Name: ptr.String("secret")},
+			want:     SnapshotFilter{Type: filter.Exact, Property: "KeyID", Value: "key"},
+			ok:       true,
+		},
+		{
+			name:     "name without an id",
+			resource: &snapshotTestResource{Name: ptr.String("pricing")},
+			want:     SnapshotFilter{Type: filter.Exact, Property: "Name", Value: "pricing"},
+			ok:       true,
+		},
+		{
+			name:     "legacy string",
+			resource: &snapshotTestLegacyResource{name: "legacy"},
+			want:     SnapshotFilter{Type: filter.Exact, Value: "legacy"},
+			ok:       true,
+		},
+		{
+			name:     "nothing identifies the resource",
+			resource: &snapshotTestResource{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, ok := NewSnapshotFilter(tc.resource)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.want, f)
+		})
+	}
+}
+
+func TestSnapshotWrite(t *testing.T) {
+	snapshot := NewSnapshot()
+	assert.True(t, snapshot.Add("tenant-a", "Disk", &snapshotTestResource{ResourceID: ptr.String("/disks/b")}))
+	assert.True(t, snapshot.Add("tenant-a", "Disk", &snapshotTestResource{ResourceID: ptr.String("/disks/a")}))
+	assert.True(t, snapshot.Add("tenant-a", "Disk", &snapshotTestResource{ResourceID: ptr.String("/disks/a")}))
+	assert.True(t, snapshot.Add("tenant-b", "ApplicationSecret", &snapshotTestResource{KeyID: ptr.String("key")}))
+	assert.False(t, snapshot.Add("tenant-b", "Disk", &snapshotTestResource{}))
+	assert.Equal(t, 3, snapshot.Count())
+
+	var buf bytes.Buffer
+	assert.NoError(t, snapshot.Write(&buf, SnapshotFormatFilters, "snapshot"))
+
+	config := &Config{}
+	assert.NoError(t, yaml.Unmarshal(buf.Bytes(), config))
+	disks := config.Accounts["tenant-a"].Filters["Disk"]
+	assert.Len(t, disks, 2)
+	assert.Equal(t, filter.Exact, disks[0].Type)
+	assert.Equal(t, "ResourceID", disks[0].Property)
+	assert.Equal(t, []string{"/disks/a", "/disks/b"}, []string{disks[0].Value, disks[1].Value})
+	assert.Len(t, config.Accounts["tenant-b"].Filters["ApplicationSecret"], 1)
+
+	buf.Reset()
+	assert.NoError(t, snapshot.Write(&buf, SnapshotFormatPreset, "baseline"))
+	assert.Contains(t, buf.String(), `# add "baseline" to the presets of the accounts`)
+
+	config = &Config{}
+	assert.NoError(t, yaml.Unmarshal(buf.Bytes(), config))
+	assert.Len(t, config.Presets["baseline"].Filters["Disk"], 2)
+	assert.Len(t, config.Presets["baseline"].Filters["ApplicationSecret"], 1)
+	assert.Empty(t, config.Accounts)
+
+	assert.Error(t, snapshot.Write(&buf, "toml", "snapshot"))
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ekristen/libnuke/pkg/filter"
+)
+
+// SubscriptionMatcher matches a subscription by its ID, its display name or one of its tags. When more than one
+// attribute is set, all of them must match. A plain string in the configuration is treated as a subscription ID.
+type SubscriptionMatcher struct {
+	// ID is the exact subscription ID.
+	ID string `yaml:"id"`
+	// Name is a glob that is matched against the display name of the subscription (i.e. `prod-*`).
+	Name string `yaml:"name"`
+	// Tag is either `key` to match on the presence of a tag or `key=value` to match on its value, the value can
+	// be a glob.
+	Tag string `yaml:"tag"`
+}
+
+// UnmarshalYAML allows a matcher to be provided as just the subscription ID.
+func (m *SubscriptionMatcher) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var id string
+	if err := unmarshal(&id); err == nil {
+		m.ID = id
+		return nil
+	}
+
+	type raw SubscriptionMatcher
+	return unmarshal((*raw)(m))
+}
+
+// Validate ensures the matcher has at least one attribute to match on.
+func (m *SubscriptionMatcher) Validate() error {
+	if m.ID == "" && m.Name == "" && m.Tag == "" {
+		return fmt.Errorf("subscription matcher requires one of id, name or tag")
+	}
+
+	return nil
+}
+
+// Match checks if the subscription matches all the configured attributes of the matcher.
+func (m *SubscriptionMatcher) Match(id, name string, tags map[string]string) (bool, error) {
+	if m.ID == "" && m.Name == "" && m.Tag == "" {
+		return false, nil
+	}
+
+	if m.ID != "" && !strings.EqualFold(m.ID, id) {
+		return false, nil
+	}
+
+	if m.Name != "" {
+		f := &filter.Filter{Type: filter.Glob, Value: m.Name}
+		match, err := f.Match(name)
+		if err != nil || !match {
+			return false, err
+		}
+	}
+
+	if m.Tag != "" {
+		key, value, hasValue := strings.Cut(m.Tag, "=")
+
+		tagValue, ok := tags[key]
+		if !ok {
+			return false, nil
+		}
+
+		if hasValue {
+			f := &filter.Filter{Type: filter.Glob, Value: value}
+			match, err := f.Match(tagValue)
+			if err != nil || !match {
+				return false, err
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// InSubscriptionBlocklist checks if the subscription matches any entry of the subscription blocklist.
+func (c *Config) InSubscriptionBlocklist(id, name string, tags map[string]string) (bool, error) {
+	return matchSubscription(c.SubscriptionBlocklist, id, name, tags)
+}
+
+// InSubscriptionAllowlist checks if the subscription matches any entry of the subscription allowlist, if there is
+// no allowlist configured, then every subscription is allowed.
+func (c *Config) InSubscriptionAllowlist(id, name string, tags map[string]string) (bool, error) {
+	if len(c.SubscriptionAllowlist) == 0 {
+		return true, nil
+	}
+
+	return matchSubscription(c.SubscriptionAllowlist, id, name, tags)
+}
+
+func matchSubscription(matchers []SubscriptionMatcher, id, name string, tags map[string]string) (bool, error) {
+	for i := range matchers {
+		match, err := matchers[i].Match(id, name, tags)
+		if err != nil {
+			return false, err
+		}
+
+		if match {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,294 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ekristen/libnuke/pkg/filter"
+	"github.com/ekristen/libnuke/pkg/registry"
+)
+
+const (
+	// SeverityError is used for problems that make the configuration behave differently than intended
+	SeverityError = "error"
+	// SeverityWarning is used for problems that are likely mistakes but do not change the behavior
+	SeverityWarning = "warning"
+)
+
+// filterTypes are all the filter types supported by libnuke, an empty type is treated as exact
+var filterTypes = []filter.Type{
+	filter.Empty, filter.Exact, filter.Glob, filter.Regex, filter.Contains, filter.DateOlderThan,
+	filter.DateOlderThanNow, filter.Suffix, filter.Prefix, filter.NotIn, filter.In,
+}
+
+// regionPattern matches the names of azure regions as they are returned by the API (i.e. `eastus`)
+var regionPattern = regexp.MustCompile(`^[a-z0-9]+$`)
+
+// Problem is a single problem found while validating the configuration
+type Problem struct {
+	Severity string
+	// Path is the location of the problem in the configuration (i.e. `presets.common.filters.ResourceGroup[0]`)
+	Path    string
+	Message string
+}
+
+// String returns a human-readable description of the problem
+func (p *Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Severity, p.Path, p.Message)
+}
+
+// Problems is the list of problems found while validating the configuration
+type Problems []*Problem
+
+// HasErrors checks if any of the problems is an error
+func (p Problems) HasErrors() bool {
+	return slices.ContainsFunc(p, func(problem *Problem) bool {
+		return problem.Severity == SeverityError
+	})
+}
+
+// Validate checks the configuration against the resource types registered in the registry. A typo in a resource type
+// or property name does not cause an error at runtime, the filter simply never matches, so this catches the mistakes
+// that would otherwise only be noticed after the resources have been removed.
+func (c *Config) Validate() Problems {
+	v := &validator{
+		properties: make(map[string]*ResourceProperties),
+	}
+
+	for _, name := range registry.GetNames() {
+		v.resourceTypes = append(v.resourceTypes, name)
+		v.properties[name] = NewResourceProperties(registry.GetRegistration(name).Resource)
+	}
+
+	sort.Strings(v.resourceTypes)
+
+	v.validateRegions(c.Regions)
+	v.validateResourceTypes("resource-types", c.ResourceTypes.Includes, c.ResourceTypes.Excludes,
+		c.ResourceTypes.Alternatives)
+
+	for _, accountID := range sortedKeys(c.Accounts) {
+		account := c.Accounts[accountID]
+		if account == nil {
+			continue
+		}
+
+		path := fmt.Sprintf("accounts.%s", accountID)
+
+		v.validateResourceTypes(path+".resource-types", account.ResourceTypes.Includes,
+			account.ResourceTypes.Excludes, account.ResourceTypes.Alternatives)
+		v.validateFilters(path+".filters", account.Filters)
+
+		for i, preset := range account.Presets {
+			if _, ok := c.Presets[preset]; !ok {
+				v.add(SeverityError, fmt.Sprintf("%s.presets[%d]", path, i),
+					"preset %q is not defined%s", preset, suggest(preset, sortedKeys(c.Presets)))
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(c.Presets) {
+		v.validateFilters(fmt.Sprintf("presets.%s.filters", name), c.Presets[name].Filters)
+	}
+
+	if c.Settings != nil {
+		v.validateSettings(c)
+	}
+
+	for _, tenantID := range sortedKeys(c.Credentials) {
+		if _, ok := c.Accounts[tenantID]; !ok {
+			v.add(SeverityWarning, fmt.Sprintf("credentials.%s", tenantID), "tenant is not configured in accounts")
+		}
+	}
+
+	for _, tenantID := range sortedKeys(c.ManagementGroups) {
+		if _, ok := c.Accounts[tenantID]; !ok {
+			v.add(SeverityWarning, fmt.Sprintf("management-groups.%s", tenantID), "tenant is not configured in accounts")
+		}
+	}
+
+	return v.problems
+}
+
+type validator struct {
+	resourceTypes []string
+	properties    map[string]*ResourceProperties
+	problems      Problems
+}
+
+func (v *validator) add(severity, path, format string, args ...interface{}) {
+	v.problems = append(v.problems, &Problem{
+		Severity: severity,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) validateRegions(regions []string) {
+	for i, region := range regions {
+		if region == "global" || region == "all" || regionPattern.MatchString(region) {
+			continue
+		}
+
+		v.add(SeverityError, fmt.Sprintf("regions[%d]", i),
+			"region %q does not match the region of any resource, use the name of the region (i.e. %q)",
+			region, strings.ToLower(strings.ReplaceAll(region, " ", "")))
+	}
+}
+
+func (v *validator) validateResourceTypes(path string, collections ...[]string) {
+	keys := []string{"includes", "excludes", "alternatives"}
+
+	for i, collection := range collections {
+		for j, resourceType := range collection {
+			if !slices.Contains(v.resourceTypes, resourceType) {
+				v.add(SeverityError, fmt.Sprintf("%s.%s[%d]", path, keys[i], j),
+					"unknown resource type %q%s", resourceType, suggest(resourceType, v.resourceTypes))
+			}
+		}
+	}
+}
+
+func (v *validator) validateFilters(path string, filters filter.Filters) {
+	for _, resourceType := range sortedKeys(filters) {
+		var properties *ResourceProperties
+
+		if resourceType == filter.Global {
+			// Note: global filters apply to every resource type, so the property has to exist on at least one of them
+			properties = globalProperties(v.resourceTypes)
+		} else {
+			p, ok := v.properties[resourceType]
+			if !ok {
+				v.add(SeverityError, fmt.Sprintf("%s.%s", path, resourceType),
+					"unknown resource type %q%s", resourceType, suggest(resourceType, v.resourceTypes))
+				continue
+			}
+			properties = p
+		}
+
+		for i := range filters[resourceType] {
+			v.validateFilter(fmt.Sprintf("%s.%s[%d]", path, resourceType, i), &filters[resourceType][i], properties)
+		}
+	}
+}
+
+func (v *validator) validateFilter(path string, f *filter.Filter, properties *ResourceProperties) {
+	if err := f.Validate(); err != nil {
+		v.add(SeverityError, path, "%s", err)
+	}
+
+	if !slices.Contains(filterTypes, f.Type) {
+		types := make([]string, 0, len(filterTypes))
+		for _, t := range filterTypes[1:] {
+			types = append(types, string(t))
+		}
+
+		v.add(SeverityError, path, "unknown filter type %q%s", f.Type, suggest(string(f.Type), types))
+	}
+
+	if f.Type == filter.Regex {
+		if _, err := regexp.Compile(f.Value); err != nil {
+			v.add(SeverityError, path, "invalid regex: %s", err)
+		}
+	}
+
+	if (f.Type == filter.In || f.Type == filter.NotIn) && len(f.Values) == 0 {
+		v.add(SeverityWarning, path, "filter type %q uses values, but no values are set", f.Type)
+	}
+
+	// Note: a filter without a property matches against the string representation of the resource
+	if f.Property == "" || properties.Has(f.Property) {
+		return
+	}
+
+	if strings.HasPrefix(f.Property, "tag:") && len(properties.TagPrefixes) == 0 {
+		v.add(SeverityError, path, "property %q does not exist, the resource type has no tags", f.Property)
+		return
+	}
+
+	v.add(SeverityError, path, "property %q does not exist%s", f.Property, suggest(f.Property, properties.Names))
+}
+
+func (v *validator) validateSettings(c *Config) {
+	for _, resourceType := range sortedKeys(*c.Settings) {
+		path := fmt.Sprintf("settings.%s", resourceType)
+
+		if !slices.Contains(v.resourceTypes, resourceType) {
+			v.add(SeverityError, path, "unknown resource type %q%s", resourceType, suggest(resourceType, v.resourceTypes))
+			continue
+		}
+
+		setting := (*c.Settings)[resourceType]
+		if setting == nil {
+			continue
+		}
+
+		supported := registry.GetRegistration(resourceType).Settings
+		for _, name := range sortedKeys(*setting) {
+			if !slices.Contains(supported, name) {
+				v.add(SeverityError, fmt.Sprintf("%s.%s", path, name),
+					"resource type %q has no setting %q%s", resourceType, name, suggest(name, supported))
+			}
+		}
+	}
+}
+
+// suggest returns a hint with the closest candidate, if one is close enough to be a likely typo
+func suggest(value string, candidates []string) string {
+	best := ""
+	bestDistance := 0
+
+	for _, candidate := range candidates {
+		distance := levenshtein(strings.ToLower(value), strings.ToLower(candidate))
+		if best == "" || distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+
+	if best == "" || bestDistance > len(value)/3+1 {
+		return ""
+	}
+
+	return fmt.Sprintf(", did you mean %q?", best)
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// sortedKeys returns the keys of the map sorted, so problems are always reported in the same order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
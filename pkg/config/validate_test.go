@@ -0,0 +1,104 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	libconfig "github.com/ekristen/libnuke/pkg/config"
+	"github.com/ekristen/libnuke/pkg/registry"
+)
+
+type ValidateTestBase struct {
+	Region         *string
+	SubscriptionID *string
+}
+
+type validateTestGroup struct {
+	*ValidateTestBase `property:",inline"`
+
+	Name          *string
+	PrincipalName *string
+	Created       *time.Time
+	Tags          map[string]*string
+}
+
+type validateTestUser struct {
+	ID          *string `property:"-"`
+	Name        *string
+	DisplayName string `property:"name=Display"`
+}
+
+func init() {
+	registry.Register(&registry.Registration{
+		Name:     "ValidateTestGroup",
+		Scope:    "validate-test",
+		Resource: &validateTestGroup{},
+	})
+	registry.Register(&registry.Registration{
+		Name:     "ValidateTestUser",
+		Scope:    "validate-test",
+		Resource: &validateTestUser{},
+	})
+}
+
+func TestNewResourceProperties(t *testing.T) {
+	group := NewResourceProperties(&validateTestGroup{})
+	assert.Equal(t, []string{"Created", "Name", "PrincipalName", "Region", "SubscriptionID"}, group.Names)
+	assert.Equal(t, []string{"tag:"}, group.TagPrefixes)
+	assert.True(t, group.Has("tag:Environment"))
+	assert.False(t, group.Has("tag:"))
+
+	user := NewResourceProperties(&validateTestUser{})
+	assert.Equal(t, []string{"Display", "Name"}, user.Names)
+	assert.Empty(t, user.TagPrefixes)
+}
+
+func TestValidate(t *testing.T) {
+	config, err := New(libconfig.Options{
+		Path: "testdata/validate.yaml",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var problems []string
+	for _, problem := range config.Validate() {
+		problems = append(problems, problem.String())
+	}
+
+	assert.Equal(t, []string{
+		`error: regions[1]: region "East US" does not match the region of any resource, use the name of the region (i.e. "eastus")`,
+		`error: resource-types.includes[1]: unknown resource type "ValidateTestGrup", did you mean "ValidateTestGroup"?`,
+		`error: accounts.efda01a1-e2e4-4024-89f0-eb29793c605b.filters.ValidateTestGroup[0]: property "PrincipleName" does not exist, did you mean "PrincipalName"?`,
+		`error: accounts.efda01a1-e2e4-4024-89f0-eb29793c605b.filters.ValidateTestUser[0]: property "tag:Environment" does not exist, the resource type has no tags`,
+		`error: accounts.efda01a1-e2e4-4024-89f0-eb29793c605b.filters.ValidateTestUser[1]: unknown filter type "regx", did you mean "regex"?`,
+		`error: accounts.efda01a1-e2e4-4024-89f0-eb29793c605b.presets[1]: preset "comon" is not defined, did you mean "common"?`,
+		`error: presets.common.filters.ValidateTestGrup: unknown resource type "ValidateTestGrup", did you mean "ValidateTestGroup"?`,
+		`error: settings.ValidateTestGroup.DisableDeletionProtection: resource type "ValidateTestGroup" has no setting "DisableDeletionProtection"`,
+		`warning: credentials.c9c6c4e9-4a8a-4b0f-8d3e-2f0e1d2c3b4a: tenant is not configured in accounts`,
+	}, problems)
+}
+
+func TestValidateExampleConfig(t *testing.T) {
+	config, err := New(libconfig.Options{
+		Path: "testdata/subscriptions.yaml",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Empty(t, config.Validate())
+}
+
+func TestSchema(t *testing.T) {
+	schema := Schema()
+
+	definitions := schema["definitions"].(map[string]interface{})
+	assert.Contains(t, definitions["resourceType"].(map[string]interface{})["enum"], "ValidateTestGroup")
+
+	filters := definitions["filters"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Contains(t, filters, "__global__")
+	assert.Contains(t, filters, "ValidateTestUser")
+}
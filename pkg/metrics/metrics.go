@@ -0,0 +1,266 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+	"github.com/ekristen/azure-nuke/pkg/report"
+)
+
+const namespace = "azure_nuke"
+
+// listingFailed matches the message libnuke logs when the listing of a resource type failed
+var listingFailed = regexp.MustCompile(`^Listing (\S+) failed`)
+
+// Metrics are the prometheus metrics of a run, they are served while the run is in progress and/or written to a
+// textfile for the textfile collector of node_exporter when the run has finished. Every method is a no-op on nil
+// metrics, so that the metrics only have to be created when they are requested.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	lock     sync.Mutex
+	started  time.Time
+	finished time.Time
+
+	resources       *prometheus.GaugeVec
+	requestDuration *prometheus.HistogramVec
+	throttled       *prometheus.CounterVec
+	listingFailures *prometheus.CounterVec
+	success         prometheus.Gauge
+
+	server *http.Server
+}
+
+var _ azure.RequestObserver = &Metrics{}
+
+// New creates the metrics of a run that starts now. The metrics are registered with their own registry, the go and
+// process metrics are left out as node_exporter already exposes them for the host.
+func New(dryRun bool) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		started:  time.Now(),
+		resources: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "resources",
+			Help:      "The number of resources per state, as of the last time the queue was processed.",
+		}, []string{"tenant_id", "subscription_id", "resource_type", "state"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "The duration of the requests to the azure apis, including the retries of throttled requests.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		}, []string{"host", "method", "code"}),
+		throttled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_throttled_requests_total",
+			Help:      "The number of requests that were throttled by the azure apis.",
+		}, []string{"host"}),
+		listingFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "listing_failures_total",
+			Help:      "The number of listings of a resource type that failed.",
+		}, []string{"resource_type"}),
+		success: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "run_success",
+			Help:      "Whether the run succeeded (1) or failed (0), only set once the run has finished.",
+		}),
+	}
+
+	dryRunValue := 0.0
+	if dryRun {
+		dryRunValue = 1
+	}
+
+	m.registry.MustRegister(
+		m.resources,
+		m.requestDuration,
+		m.throttled,
+		m.listingFailures,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "run_start_time_seconds",
+			Help:      "The time the run started, in seconds since the epoch.",
+		}, func() float64 {
+			return float64(m.started.UnixNano()) / float64(time.Second)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "run_duration_seconds",
+			Help:      "How long the run has been in progress, or how long it took once it has finished.",
+		}, m.duration),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "dry_run",
+			Help:      "Whether the run is a dry run (1) or removes the resources (0).",
+		}, func() float64 {
+			return dryRunValue
+		}),
+	)
+
+	return m
+}
+
+// Listen serves the metrics on the /metrics path of the address until the metrics are closed
+func (m *Metrics) Listen(addr string) error {
+	if m == nil {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+
+	m.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := m.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.WithField("component", "metrics").WithError(err).Error("unable to serve metrics")
+		}
+	}()
+
+	logrus.WithField("component", "metrics").Infof("serving metrics on http://%s/metrics", listener.Addr())
+
+	return nil
+}
+
+// Handler returns the http handler that serves the metrics
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+	})
+}
+
+// Close stops serving the metrics
+func (m *Metrics) Close(ctx context.Context) error {
+	if m == nil || m.server == nil {
+		return nil
+	}
+
+	return m.server.Shutdown(ctx)
+}
+
+// Finish records the outcome of the run, the duration of the run stops growing
+func (m *Metrics) Finish(runErr error) {
+	if m == nil {
+		return
+	}
+
+	m.lock.Lock()
+	m.finished = time.Now()
+	m.lock.Unlock()
+
+	if runErr == nil {
+		m.success.Set(1)
+	} else {
+		m.success.Set(0)
+	}
+
+	// Note: the outcome is only registered once it is known, so that a run in progress is not reported as failed
+	_ = m.registry.Register(m.success)
+}
+
+// WriteTextfile writes the metrics to the path in the text format read by the textfile collector of node_exporter,
+// the file is replaced atomically so that the collector never reads a partial file
+func (m *Metrics) WriteTextfile(path string) error {
+	if m == nil {
+		return nil
+	}
+
+	return prometheus.WriteToTextfile(path, m.registry)
+}
+
+// ObserveRequest implements azure.RequestObserver
+func (m *Metrics) ObserveRequest(req *http.Request, status int, duration time.Duration, throttled bool) {
+	if m == nil {
+		return
+	}
+
+	host := strings.ToLower(req.URL.Host)
+
+	m.requestDuration.
+		WithLabelValues(host, req.Method, strconv.Itoa(status)).
+		Observe(duration.Seconds())
+
+	if throttled {
+		m.throttled.WithLabelValues(host).Inc()
+	}
+}
+
+// ObserveQueue counts the resources of the items of the tenant per state, the previous counts of the tenant are
+// replaced
+func (m *Metrics) ObserveQueue(tenantID string, items []*queue.Item) {
+	if m == nil {
+		return
+	}
+
+	type key struct {
+		subscriptionID string
+		resourceType   string
+		state          string
+	}
+
+	counts := make(map[key]int)
+	for _, item := range items {
+		k := key{
+			resourceType: item.Type,
+			state:        report.ItemStateName(item),
+		}
+
+		if opts, ok := item.Opts.(*azure.ListerOpts); ok {
+			k.subscriptionID = opts.SubscriptionID
+		}
+
+		counts[k]++
+	}
+
+	m.resources.DeletePartialMatch(prometheus.Labels{"tenant_id": tenantID})
+
+	for k, count := range counts {
+		m.resources.WithLabelValues(tenantID, k.subscriptionID, k.resourceType, k.state).Set(float64(count))
+	}
+}
+
+// Levels implements logrus.Hook
+func (m *Metrics) Levels() []logrus.Level {
+	return []logrus.Level{logrus.ErrorLevel}
+}
+
+// Fire implements logrus.Hook, it counts the listings that failed as the scanners of libnuke only log these
+func (m *Metrics) Fire(entry *logrus.Entry) error {
+	if match := listingFailed.FindStringSubmatch(entry.Message); match != nil {
+		m.listingFailures.WithLabelValues(match[1]).Inc()
+	}
+
+	return nil
+}
+
+func (m *Metrics) duration() float64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	end := m.finished
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	return end.Sub(m.started).Seconds()
+}
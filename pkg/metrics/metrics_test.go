@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+func TestObserveQueue(t *testing.T) {
+	m := New(true)
+
+	sub := &azure.ListerOpts{TenantID: "tenant-a", SubscriptionID: "sub-a"}
+	tenant := &azure.ListerOpts{TenantID: "tenant-a"}
+
+	items := []*queue.Item{
+		{Type: "VirtualMachine", State: queue.ItemStateNew, Opts: sub},
+		{Type: "VirtualMachine", State: queue.ItemStateNew, Opts: sub},
+		{Type: "VirtualMachine", State: queue.ItemStateFiltered, Opts: sub},
+		{Type: "Application", State: queue.ItemStateNew, Opts: tenant},
+	}
+
+	m.ObserveQueue("tenant-a", items)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.resources.WithLabelValues("tenant-a", "sub-a", "VirtualMachine", "discovered")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.resources.WithLabelValues("tenant-a", "sub-a", "VirtualMachine", "filtered")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.resources.WithLabelValues("tenant-a", "", "Application", "discovered")))
+
+	items[0].State = queue.ItemStateFinished
+	items[1].State = queue.ItemStateFailed
+	m.ObserveQueue("tenant-a", items)
+
+	// Note: the counts of the tenant are replaced, a state without resources is no longer reported
+	expected := `
+# HELP azure_nuke_resources The number of resources per state, as of the last time the queue was processed.
+# TYPE azure_nuke_resources gauge
+azure_nuke_resources{resource_type="Application",state="discovered",subscription_id="",tenant_id="tenant-a"} 1
+azure_nuke_resources{resource_type="VirtualMachine",state="failed",subscription_id="sub-a",tenant_id="tenant-a"} 1
+azure_nuke_resources{resource_type="VirtualMachine",state="filtered",subscription_id="sub-a",tenant_id="tenant-a"} 1
+azure_nuke_resources{resource_type="VirtualMachine",state="removed",subscription_id="sub-a",tenant_id="tenant-a"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(m.resources, strings.NewReader(expected)))
+}
+
+func TestObserveRequestsOfPipeline(t *testing.T) {
+	var requests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After-Ms", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	m := New(true)
+
+	p := azure.NewPipeline(nil)
+	p.SetObserver(m)
+
+	resp, err := p.HTTPClient().Get(server.URL + "/subscriptions/sub-a/resourceGroups")
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	assert.Equal(t, 2, testutil.CollectAndCount(m.requestDuration))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.throttled.WithLabelValues(host)))
+}
+
+func TestListingFailures(t *testing.T) {
+	m := New(true)
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.AddHook(m)
+
+	logger.Errorf("Listing %s failed:\n%s", "VirtualMachine", "    error")
+	logger.Errorf("Listing %s failed:\n%s", "VirtualMachine", "    error")
+	logger.Error("something else failed")
+
+	assert.Equal(t, 1, testutil.CollectAndCount(m.listingFailures))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.listingFailures.WithLabelValues("VirtualMachine")))
+}
+
+func TestWriteTextfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "azure-nuke.prom")
+
+	m := New(false)
+	m.ObserveQueue("tenant-a", []*queue.Item{
+		{Type: "VirtualMachine", State: queue.ItemStateFinished, Opts: &azure.ListerOpts{SubscriptionID: "sub-a"}},
+	})
+	m.Finish(errors.New("failed"))
+
+	assert.NoError(t, m.WriteTextfile(path))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	assert.Contains(t, string(data),
+		`azure_nuke_resources{resource_type="VirtualMachine",state="removed",subscription_id="sub-a",tenant_id="tenant-a"} 1`)
+	assert.Contains(t, string(data), "azure_nuke_run_success 0")
+	assert.Contains(t, string(data), "azure_nuke_dry_run 0")
+	assert.Contains(t, string(data), "azure_nuke_run_duration_seconds")
+	assert.NotContains(t, string(data), "go_goroutines")
+}
+
+func TestServeMetrics(t *testing.T) {
+	m := New(true)
+
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	assert.Contains(t, string(data), "azure_nuke_dry_run 1")
+	// Note: the outcome of a run in progress is not known yet
+	assert.NotContains(t, string(data), "azure_nuke_run_success")
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+
+	assert.NoError(t, m.Listen(":0"))
+	assert.NoError(t, m.WriteTextfile(filepath.Join(t.TempDir(), "azure-nuke.prom")))
+	assert.NoError(t, m.Close(context.Background()))
+
+	m.ObserveQueue("tenant-a", nil)
+	m.Finish(nil)
+}
@@ -0,0 +1,201 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+// Version is the version of the plan file format, it is bumped whenever the format changes in a way that older
+// versions of the tool would not be able to apply correctly.
+const Version = 1
+
+// Plan is a reviewable list of resources that a dry run would have removed, it is applied by the apply command
+type Plan struct {
+	Version     int       `json:"version"`
+	ToolVersion string    `json:"tool_version"`
+	CreatedAt   time.Time `json:"created_at"`
+	Items       []*Item   `json:"items"`
+}
+
+// Item is a single resource in the plan, along with everything needed to fetch it again
+type Item struct {
+	Type              string            `json:"type"`
+	ID                string            `json:"id,omitempty"`
+	Name              string            `json:"name"`
+	TenantID          string            `json:"tenant_id"`
+	ManagementGroupID string            `json:"management_group_id,omitempty"`
+	SubscriptionID    string            `json:"subscription_id,omitempty"`
+	ResourceGroup     string            `json:"resource_group,omitempty"`
+	Region            string            `json:"region,omitempty"`
+	Regions           []string          `json:"regions,omitempty"`
+	Properties        map[string]string `json:"properties"`
+}
+
+// New creates an empty plan
+func New(toolVersion string) *Plan {
+	return &Plan{
+		Version:     Version,
+		ToolVersion: toolVersion,
+		CreatedAt:   time.Now().UTC(),
+		Items:       make([]*Item, 0),
+	}
+}
+
+// Load reads a plan from a file
+func Load(path string) (*Plan, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Plan{}
+	if err := json.Unmarshal(raw, p); err != nil {
+		return nil, err
+	}
+
+	if p.Version != Version {
+		return nil, fmt.Errorf("unsupported plan version: %d", p.Version)
+	}
+
+	return p, nil
+}
+
+// Save writes the plan to a file
+func (p *Plan) Save(path string) error {
+	raw, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, raw, 0600)
+}
+
+// AddItems adds every item of the queue that would be removed to the plan
+func (p *Plan) AddItems(items []*queue.Item) {
+	for _, item := range items {
+		if item.GetState() != queue.ItemStateNew && item.GetState() != queue.ItemStateNewDependency {
+			continue
+		}
+
+		p.Items = append(p.Items, NewItem(item))
+	}
+}
+
+// TenantIDs returns the sorted unique tenant IDs of all the items in the plan
+func (p *Plan) TenantIDs() []string {
+	var tenantIDs []string
+	for _, item := range p.Items {
+		if !slices.Contains(tenantIDs, item.TenantID) {
+			tenantIDs = append(tenantIDs, item.TenantID)
+		}
+	}
+
+	sort.Strings(tenantIDs)
+
+	return tenantIDs
+}
+
+// NewItem converts a queue item into a plan item
+func NewItem(item *queue.Item) *Item {
+	i := &Item{
+		Type:       item.Type,
+		ID:         azure.ResourceID(item.Resource),
+		Name:       resourceName(item.Resource),
+		Properties: Properties(item.Resource),
+	}
+
+	if opts, ok := item.Opts.(*azure.ListerOpts); ok {
+		i.TenantID = opts.TenantID
+		i.ManagementGroupID = opts.ManagementGroupID
+		i.SubscriptionID = opts.SubscriptionID
+		i.ResourceGroup = opts.ResourceGroup
+		i.Regions = opts.Regions
+	}
+
+	if regional, ok := item.Resource.(interface{ GetRegion() string }); ok {
+		i.Region = regional.GetRegion()
+	}
+
+	return i
+}
+
+// ListerOpts rebuilds the options the resource was originally listed with
+func (i *Item) ListerOpts(authorizers *azure.Authorizers) *azure.ListerOpts {
+	return &azure.ListerOpts{
+		Authorizers:       authorizers,
+		TenantID:          i.TenantID,
+		ManagementGroupID: i.ManagementGroupID,
+		SubscriptionID:    i.SubscriptionID,
+		ResourceGroup:     i.ResourceGroup,
+		Regions:           i.Regions,
+	}
+}
+
+// Matches checks if the resource is the same resource as the one in the plan, by ARM or Graph object ID when the
+// resource has one, by its name otherwise. The lister options already narrow the resource down to its scope.
+func (i *Item) Matches(r resource.Resource) bool {
+	if i.ID != "" {
+		return strings.EqualFold(i.ID, azure.ResourceID(r))
+	}
+
+	return i.Name == resourceName(r)
+}
+
+// Changes returns the sorted names of the properties that differ between the plan and the resource
+func (i *Item) Changes(r resource.Resource) []string {
+	current := Properties(r)
+
+	var changes []string
+	for k, v := range i.Properties {
+		if current[k] != v {
+			changes = append(changes, k)
+		}
+	}
+	for k := range current {
+		if _, ok := i.Properties[k]; !ok {
+			changes = append(changes, k)
+		}
+	}
+
+	sort.Strings(changes)
+
+	return changes
+}
+
+// Properties returns the properties of the resource without the keys that are internal to libnuke
+func Properties(r resource.Resource) map[string]string {
+	props := make(map[string]string)
+
+	getter, ok := r.(resource.PropertyGetter)
+	if !ok {
+		return props
+	}
+
+	for k, v := range getter.Properties() {
+		if strings.HasPrefix(k, "_") {
+			continue
+		}
+
+		props[k] = v
+	}
+
+	return props
+}
+
+func resourceName(r resource.Resource) string {
+	if stringer, ok := r.(resource.LegacyStringer); ok {
+		return stringer.String()
+	}
+
+	return ""
+}
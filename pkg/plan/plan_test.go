@@ -0,0 +1,133 @@
+package plan
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/types"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+type testResource struct {
+	ID       *string
+	Name     string
+	Location string
+}
+
+func (r *testResource) Remove(_ context.Context) error {
+	return nil
+}
+
+func (r *testResource) GetRegion() string {
+	return r.Location
+}
+
+func (r *testResource) Properties() types.Properties {
+	return types.NewPropertiesFromStruct(r)
+}
+
+func (r *testResource) String() string {
+	return r.Name
+}
+
+type testNamedResource struct {
+	Name string
+}
+
+func (r *testNamedResource) Remove(_ context.Context) error {
+	return nil
+}
+
+func (r *testNamedResource) String() string {
+	return r.Name
+}
+
+func TestPlanRoundTrip(t *testing.T) {
+	p := New("test")
+	p.AddItems([]*queue.Item{
+		{
+			Resource: &testResource{
+				ID:       ptr.String("/subscriptions/sub-a/resourceGroups/rg/providers/Microsoft.Compute/disks/disk"),
+				Name:     "disk",
+				Location: "eastus",
+			},
+			State: queue.ItemStateNew,
+			Type:  "Disk",
+			Opts: &azure.ListerOpts{
+				TenantID:       "tenant-b",
+				SubscriptionID: "sub-a",
+				ResourceGroup:  "rg",
+				Regions:        []string{"eastus"},
+			},
+		},
+		{
+			Resource: &testNamedResource{Name: "user"},
+			State:    queue.ItemStateNew,
+			Type:     "AzureADUser",
+			Opts:     &azure.ListerOpts{TenantID: "tenant-a"},
+		},
+		{
+			Resource: &testNamedResource{Name: "filtered"},
+			State:    queue.ItemStateFiltered,
+			Type:     "AzureADUser",
+			Opts:     &azure.ListerOpts{TenantID: "tenant-a"},
+		},
+	})
+
+	path := filepath.Join(t.TempDir(), "plan.json")
+	assert.NoError(t, p.Save(path))
+
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Len(t, loaded.Items, 2)
+	assert.Equal(t, []string{"tenant-a", "tenant-b"}, loaded.TenantIDs())
+
+	disk := loaded.Items[0]
+	assert.Equal(t, "/subscriptions/sub-a/resourceGroups/rg/providers/Microsoft.Compute/disks/disk", disk.ID)
+	assert.Equal(t, "eastus", disk.Region)
+	assert.Equal(t, "disk", disk.Properties["Name"])
+	assert.NotContains(t, disk.Properties, "_tagPrefix")
+
+	opts := disk.ListerOpts(nil)
+	assert.Equal(t, "sub-a", opts.SubscriptionID)
+	assert.Equal(t, "rg", opts.ResourceGroup)
+
+	assert.Equal(t, "", loaded.Items[1].ID)
+	assert.Equal(t, "user", loaded.Items[1].Name)
+}
+
+func TestItemMatchesAndChanges(t *testing.T) {
+	original := &testResource{
+		ID:       ptr.String("/subscriptions/sub-a/resourceGroups/rg/providers/Microsoft.Compute/disks/disk"),
+		Name:     "disk",
+		Location: "eastus",
+	}
+
+	item := NewItem(&queue.Item{Resource: original, Type: "Disk"})
+
+	assert.True(t, item.Matches(&testResource{
+		ID:   ptr.String("/SUBSCRIPTIONS/sub-a/resourceGroups/rg/providers/Microsoft.Compute/disks/disk"),
+		Name: "renamed",
+	}))
+	assert.False(t, item.Matches(&testResource{
+		ID:   ptr.String("/subscriptions/sub-a/resourceGroups/rg/providers/Microsoft.Compute/disks/other"),
+		Name: "disk",
+	}))
+
+	assert.Empty(t, item.Changes(original))
+	assert.Equal(t, []string{"Location"}, item.Changes(&testResource{
+		ID:       original.ID,
+		Name:     "disk",
+		Location: "westus",
+	}))
+
+	named := NewItem(&queue.Item{Resource: &testNamedResource{Name: "user"}, Type: "AzureADUser"})
+	assert.True(t, named.Matches(&testNamedResource{Name: "user"}))
+	assert.False(t, named.Matches(&testNamedResource{Name: "other"}))
+}
@@ -0,0 +1,339 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// Formats are the supported report formats
+var Formats = []string{FormatJSON, FormatCSV}
+
+// StateStopped is the name of the state of the resources that were stopped instead of removed
+const StateStopped = "stopped"
+
+var stateNames = map[queue.ItemState]string{
+	queue.ItemStateNew:               "discovered",
+	queue.ItemStateNewDependency:     "discovered",
+	queue.ItemStateHold:              "hold",
+	queue.ItemStatePending:           "pending",
+	queue.ItemStatePendingDependency: "pending-dependency",
+	queue.ItemStateWaiting:           "waiting",
+	queue.ItemStateFailed:            "failed",
+	queue.ItemStateFiltered:          "filtered",
+	queue.ItemStateFinished:          "removed",
+}
+
+// StateName returns the name of the queue item state as it is used in the report
+func StateName(state queue.ItemState) string {
+	if name, ok := stateNames[state]; ok {
+		return name
+	}
+
+	return "unknown"
+}
+
+// ItemStateName returns the name of the state of the queue item as it is used in the report, the items that were
+// stopped instead of removed are finished as well but are named stopped
+func ItemStateName(item *queue.Item) string {
+	if item.GetState() == queue.ItemStateFinished {
+		if opts, ok := item.Opts.(*azure.ListerOpts); ok && opts.Stop {
+			return StateStopped
+		}
+	}
+
+	return StateName(item.GetState())
+}
+
+// Entry is a single resource in the report
+type Entry struct {
+	Type           string            `json:"type"`
+	Scanner        string            `json:"scanner"`
+	TenantID       string            `json:"tenant_id,omitempty"`
+	SubscriptionID string            `json:"subscription_id,omitempty"`
+	ResourceGroup  string            `json:"resource_group,omitempty"`
+	Region         string            `json:"region,omitempty"`
+	Name           string            `json:"name"`
+	Properties     map[string]string `json:"properties,omitempty"`
+	State          string            `json:"state"`
+	Reason         string            `json:"reason,omitempty"`
+	Error          string            `json:"error,omitempty"`
+}
+
+// Counts is the number of resources per state, along with the total
+type Counts struct {
+	Total  int            `json:"total"`
+	States map[string]int `json:"states"`
+}
+
+func (c *Counts) add(state string) {
+	if c.States == nil {
+		c.States = make(map[string]int)
+	}
+
+	c.Total++
+	c.States[state]++
+}
+
+// Summary contains the counts of all the resources in the report, per subscription and per resource type. Resources
+// that do not belong to a subscription (tenant and management group level) are counted under an empty subscription.
+type Summary struct {
+	Counts
+	BySubscription map[string]*Counts `json:"by_subscription"`
+	ByType         map[string]*Counts `json:"by_type"`
+}
+
+// Report is a machine-readable record of a run
+type Report struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	DryRun     bool      `json:"dry_run"`
+	Summary    Summary   `json:"summary"`
+	Entries    []*Entry  `json:"entries"`
+}
+
+// New creates an empty report, the start time is set to now
+func New(dryRun bool) *Report {
+	return &Report{
+		StartedAt: time.Now().UTC(),
+		DryRun:    dryRun,
+		Summary: Summary{
+			BySubscription: make(map[string]*Counts),
+			ByType:         make(map[string]*Counts),
+		},
+		Entries: make([]*Entry, 0),
+	}
+}
+
+// AddItems adds all the items of a queue to the report
+func (r *Report) AddItems(items []*queue.Item) {
+	for _, item := range items {
+		r.AddItem(item)
+	}
+}
+
+// AddItem converts a queue item to a report entry and updates the summary
+func (r *Report) AddItem(item *queue.Item) {
+	entry := &Entry{
+		Type:  item.Type,
+		State: ItemStateName(item),
+	}
+
+	if opts, ok := item.Opts.(*azure.ListerOpts); ok {
+		entry.Scanner = Scanner(opts)
+		entry.TenantID = opts.TenantID
+		entry.SubscriptionID = opts.SubscriptionID
+		entry.ResourceGroup = opts.ResourceGroup
+	}
+
+	// Note: subscription scoped listers (i.e. GenericResource) list the resources of every resource group, the
+	// resource knows which one it belongs to
+	if scoped, ok := item.Resource.(interface{ GetSubscriptionID() string }); ok && scoped.GetSubscriptionID() != "" {
+		entry.SubscriptionID = scoped.GetSubscriptionID()
+	}
+
+	if grouped, ok := item.Resource.(interface{ GetResourceGroup() string }); ok && grouped.GetResourceGroup() != "" {
+		entry.ResourceGroup = grouped.GetResourceGroup()
+	}
+
+	if regional, ok := item.Resource.(interface{ GetRegion() string }); ok {
+		entry.Region = regional.GetRegion()
+	}
+
+	if stringer, ok := item.Resource.(resource.LegacyStringer); ok {
+		entry.Name = stringer.String()
+	}
+
+	if getter, ok := item.Resource.(resource.PropertyGetter); ok {
+		entry.Properties = make(map[string]string)
+		for k, v := range getter.Properties() {
+			// Note: keys prefixed with an underscore are internal to libnuke (i.e. the tag prefix)
+			if strings.HasPrefix(k, "_") {
+				continue
+			}
+
+			entry.Properties[k] = v
+		}
+	}
+
+	switch item.GetState() {
+	case queue.ItemStateFailed:
+		entry.Error = item.GetReason()
+	default:
+		entry.Reason = item.GetReason()
+	}
+
+	r.Entries = append(r.Entries, entry)
+
+	r.Summary.add(entry.State)
+
+	if _, ok := r.Summary.BySubscription[entry.SubscriptionID]; !ok {
+		r.Summary.BySubscription[entry.SubscriptionID] = &Counts{}
+	}
+	r.Summary.BySubscription[entry.SubscriptionID].add(entry.State)
+
+	if _, ok := r.Summary.ByType[entry.Type]; !ok {
+		r.Summary.ByType[entry.Type] = &Counts{}
+	}
+	r.Summary.ByType[entry.Type].add(entry.State)
+}
+
+// Scanner returns a short description of the scanner that discovered the resource (i.e. tenant, mg/name, sub/id,
+// sub/id/rg/name) based on the lister options it was discovered with
+func Scanner(opts *azure.ListerOpts) string {
+	switch {
+	case opts.ResourceGroup != "":
+		return fmt.Sprintf("sub/%s/rg/%s", opts.SubscriptionID, opts.ResourceGroup)
+	case opts.SubscriptionID != "":
+		return fmt.Sprintf("sub/%s", opts.SubscriptionID)
+	case opts.ManagementGroupID != "":
+		return fmt.Sprintf("mg/%s", opts.ManagementGroupID)
+	default:
+		return "tenant"
+	}
+}
+
+// Write finishes the report and writes it to the path in the requested format. For the CSV format the summary is
+// written next to the report with a `.summary.csv` suffix, as it does not fit in the same set of columns.
+func (r *Report) Write(path, format string) error {
+	r.FinishedAt = time.Now().UTC()
+
+	switch format {
+	case FormatJSON:
+		return writeFile(path, r.WriteJSON)
+	case FormatCSV:
+		if err := writeFile(path, r.WriteCSV); err != nil {
+			return err
+		}
+
+		return writeFile(strings.TrimSuffix(path, ".csv")+".summary.csv", r.WriteSummaryCSV)
+	default:
+		return fmt.Errorf("unsupported report format: %s", format)
+	}
+}
+
+// WriteJSON writes the entire report, including the summary, as JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteCSV writes one row per entry, the properties are written as a single JSON encoded column
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{
+		"type", "scanner", "tenant_id", "subscription_id", "resource_group", "region",
+		"name", "state", "reason", "error", "properties",
+	}); err != nil {
+		return err
+	}
+
+	for _, e := range r.Entries {
+		props, err := json.Marshal(e.Properties)
+		if err != nil {
+			return err
+		}
+
+		if err := cw.Write([]string{
+			e.Type, e.Scanner, e.TenantID, e.SubscriptionID, e.ResourceGroup, e.Region,
+			e.Name, e.State, e.Reason, e.Error, string(props),
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteSummaryCSV writes the summary with one row per subscription and per resource type
+func (r *Report) WriteSummaryCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	states := r.states()
+
+	if err := cw.Write(append([]string{"group", "key", "total"}, states...)); err != nil {
+		return err
+	}
+
+	row := func(group, key string, c *Counts) error {
+		record := []string{group, key, fmt.Sprintf("%d", c.Total)}
+		for _, state := range states {
+			record = append(record, fmt.Sprintf("%d", c.States[state]))
+		}
+		return cw.Write(record)
+	}
+
+	if err := row("total", "", &r.Summary.Counts); err != nil {
+		return err
+	}
+
+	for _, key := range sortedKeys(r.Summary.BySubscription) {
+		if err := row("subscription", key, r.Summary.BySubscription[key]); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range sortedKeys(r.Summary.ByType) {
+		if err := row("type", key, r.Summary.ByType[key]); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// states returns the sorted names of all the states present in the report
+func (r *Report) states() []string {
+	states := make([]string, 0, len(r.Summary.States))
+	for state := range r.Summary.States {
+		states = append(states, state)
+	}
+
+	sort.Strings(states)
+
+	return states
+}
+
+func sortedKeys(m map[string]*Counts) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func writeFile(path string, fn func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := fn(f); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
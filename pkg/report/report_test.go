@@ -0,0 +1,176 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/types"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+type testResource struct {
+	name   string
+	region string
+}
+
+func (r *testResource) Remove(_ context.Context) error {
+	return nil
+}
+
+func (r *testResource) GetRegion() string {
+	return r.region
+}
+
+func (r *testResource) Properties() types.Properties {
+	return types.NewProperties().Set("Name", r.name)
+}
+
+func (r *testResource) String() string {
+	return r.name
+}
+
+func testItems() []*queue.Item {
+	return []*queue.Item{
+		{
+			Resource: &testResource{name: "rg-one", region: "eastus"},
+			State:    queue.ItemStateFinished,
+			Type:     "ResourceGroup",
+			Opts:     &azure.ListerOpts{TenantID: "tenant", SubscriptionID: "sub-a"},
+		},
+		{
+			Resource: &testResource{name: "vm-one", region: "eastus"},
+			State:    queue.ItemStateFailed,
+			Reason:   "conflict",
+			Type:     "VirtualMachine",
+			Opts:     &azure.ListerOpts{TenantID: "tenant", SubscriptionID: "sub-a", ResourceGroup: "rg-one"},
+		},
+		{
+			Resource: &testResource{name: "user", region: "global"},
+			State:    queue.ItemStateFiltered,
+			Reason:   "filtered by config",
+			Type:     "AzureADUser",
+			Opts:     &azure.ListerOpts{TenantID: "tenant"},
+		},
+	}
+}
+
+func TestReportJSON(t *testing.T) {
+	rpt := New(false)
+	rpt.AddItems(testItems())
+
+	var buf bytes.Buffer
+	assert.NoError(t, rpt.WriteJSON(&buf))
+
+	var out Report
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+
+	assert.Len(t, out.Entries, 3)
+	assert.Equal(t, "sub/sub-a/rg/rg-one", out.Entries[1].Scanner)
+	assert.Equal(t, "failed", out.Entries[1].State)
+	assert.Equal(t, "conflict", out.Entries[1].Error)
+	assert.Equal(t, "vm-one", out.Entries[1].Properties["Name"])
+	assert.Equal(t, "tenant", out.Entries[2].Scanner)
+	assert.Equal(t, "filtered by config", out.Entries[2].Reason)
+
+	assert.Equal(t, 3, out.Summary.Total)
+	assert.Equal(t, 2, out.Summary.BySubscription["sub-a"].Total)
+	assert.Equal(t, 1, out.Summary.BySubscription["sub-a"].States["removed"])
+	assert.Equal(t, 1, out.Summary.ByType["AzureADUser"].States["filtered"])
+}
+
+func TestReportCSV(t *testing.T) {
+	rpt := New(false)
+	rpt.AddItems(testItems())
+
+	path := filepath.Join(t.TempDir(), "report.csv")
+	assert.NoError(t, rpt.Write(path, FormatCSV))
+
+	records := readCSV(t, path)
+	assert.Len(t, records, 4)
+	assert.Equal(t, "type", records[0][0])
+	assert.Equal(t, []string{
+		"ResourceGroup", "sub/sub-a", "tenant", "sub-a", "", "eastus", "rg-one", "removed", "", "", `{"Name":"rg-one"}`,
+	}, records[1])
+
+	summary := readCSV(t, filepath.Join(filepath.Dir(path), "report.summary.csv"))
+	assert.Equal(t, []string{"group", "key", "total", "failed", "filtered", "removed"}, summary[0])
+	assert.Equal(t, []string{"total", "", "3", "1", "1", "1"}, summary[1])
+	assert.Equal(t, []string{"subscription", "sub-a", "2", "1", "0", "1"}, summary[3])
+}
+
+type testGroupedResource struct {
+	testResource
+	subscriptionID string
+	resourceGroup  string
+}
+
+func (r *testGroupedResource) GetSubscriptionID() string {
+	return r.subscriptionID
+}
+
+func (r *testGroupedResource) GetResourceGroup() string {
+	return r.resourceGroup
+}
+
+func TestReportSubscriptionScopedItem(t *testing.T) {
+	rpt := New(false)
+	rpt.AddItem(&queue.Item{
+		Resource: &testGroupedResource{
+			testResource:   testResource{name: "site", region: "eastus"},
+			subscriptionID: "sub-a",
+			resourceGroup:  "rg-web",
+		},
+		State: queue.ItemStateNew,
+		Type:  "GenericResource",
+		Opts:  &azure.ListerOpts{TenantID: "tenant", SubscriptionID: "sub-a"},
+	})
+	rpt.AddItem(&queue.Item{
+		Resource: &testGroupedResource{testResource: testResource{name: "vm", region: "eastus"}},
+		State:    queue.ItemStateNew,
+		Type:     "VirtualMachine",
+		Opts:     &azure.ListerOpts{TenantID: "tenant", SubscriptionID: "sub-b", ResourceGroup: "rg-vm"},
+	})
+
+	assert.Equal(t, "sub/sub-a", rpt.Entries[0].Scanner)
+	assert.Equal(t, "sub-a", rpt.Entries[0].SubscriptionID)
+	assert.Equal(t, "rg-web", rpt.Entries[0].ResourceGroup)
+	assert.Equal(t, "sub-b", rpt.Entries[1].SubscriptionID)
+	assert.Equal(t, "rg-vm", rpt.Entries[1].ResourceGroup)
+}
+
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return records
+}
+
+func TestItemStateName(t *testing.T) {
+	removed := &queue.Item{State: queue.ItemStateFinished, Opts: &azure.ListerOpts{}}
+	stopped := &queue.Item{State: queue.ItemStateFinished, Opts: &azure.ListerOpts{Stop: true}}
+	waiting := &queue.Item{State: queue.ItemStateWaiting, Opts: &azure.ListerOpts{Stop: true}}
+
+	assert.Equal(t, "removed", ItemStateName(removed))
+	assert.Equal(t, StateStopped, ItemStateName(stopped))
+	assert.Equal(t, "waiting", ItemStateName(waiting))
+	assert.Equal(t, "removed", ItemStateName(&queue.Item{State: queue.ItemStateFinished}))
+}
@@ -0,0 +1,178 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// providerPrefixes are the prefixes of the terraform resource types whose IDs are indexed
+var providerPrefixes = []string{"azurerm_", "azuread_"}
+
+// idAttributes are the attributes of a terraform resource that hold the ID of the resource in azure
+var idAttributes = []string{"id", "object_id"}
+
+// graphObjectID matches the IDs of the azuread resources that are prefixed with the collection of the object, the
+// resources of azure-nuke are identified by the object ID alone
+var graphObjectID = regexp.MustCompile(`^/(?:applications|servicePrincipals|groups|users)/([^/]+)$`)
+
+// State is the index of the resources that are managed by terraform, as read from one or more state files
+type State struct {
+	managed map[string]string
+}
+
+// Load reads the state files, relative paths are resolved against the directory. Both the state files themselves
+// (terraform.tfstate) and the output of `terraform show -json` are supported. It returns nil if there are no paths.
+func Load(dir string, paths ...string) (*State, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	s := &State{
+		managed: make(map[string]string),
+	}
+
+	for _, path := range paths {
+		resolved := path
+		if !filepath.IsAbs(resolved) {
+			resolved = filepath.Join(dir, resolved)
+		}
+
+		raw, err := os.ReadFile(resolved)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.parse(path, raw); err != nil {
+			return nil, fmt.Errorf("unable to read terraform state %s: %w", path, err)
+		}
+	}
+
+	return s, nil
+}
+
+// Lookup returns the state file that manages the resource with the ID, the lookup is case insensitive
+func (s *State) Lookup(id string) (string, bool) {
+	if s == nil || id == "" {
+		return "", false
+	}
+
+	source, ok := s.managed[strings.ToLower(id)]
+	return source, ok
+}
+
+// Len returns the number of resource IDs in the state
+func (s *State) Len() int {
+	if s == nil {
+		return 0
+	}
+
+	return len(s.managed)
+}
+
+type stateFile struct {
+	Version       int          `json:"version"`
+	FormatVersion string       `json:"format_version"`
+	Resources     []*resource  `json:"resources"`
+	Values        *stateValues `json:"values"`
+}
+
+// resource is a resource of a state file, or of the values of the `terraform show -json` output
+type resource struct {
+	Mode      string                 `json:"mode"`
+	Type      string                 `json:"type"`
+	Instances []*instance            `json:"instances"`
+	Values    map[string]interface{} `json:"values"`
+}
+
+type instance struct {
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+type stateValues struct {
+	RootModule *module `json:"root_module"`
+}
+
+type module struct {
+	Resources    []*resource `json:"resources"`
+	ChildModules []*module   `json:"child_modules"`
+}
+
+func (s *State) parse(source string, raw []byte) error {
+	var file stateFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return err
+	}
+
+	switch {
+	case file.FormatVersion != "":
+		if file.Values != nil {
+			s.addModule(source, file.Values.RootModule)
+		}
+	case file.Version == 4:
+		for _, r := range file.Resources {
+			for _, i := range r.Instances {
+				s.add(source, r, i.Attributes)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported state version %d", file.Version)
+	}
+
+	return nil
+}
+
+func (s *State) addModule(source string, m *module) {
+	if m == nil {
+		return
+	}
+
+	for _, r := range m.Resources {
+		s.add(source, r, r.Values)
+	}
+
+	for _, child := range m.ChildModules {
+		s.addModule(source, child)
+	}
+}
+
+func (s *State) add(source string, r *resource, attributes map[string]interface{}) {
+	// Note: data sources are in the state as well, they are not managed by terraform
+	if r.Mode != "managed" || !hasProviderPrefix(r.Type) {
+		return
+	}
+
+	for _, attribute := range idAttributes {
+		id, ok := attributes[attribute].(string)
+		if !ok || id == "" {
+			continue
+		}
+
+		s.index(source, id)
+
+		if match := graphObjectID.FindStringSubmatch(id); match != nil {
+			s.index(source, match[1])
+		}
+	}
+}
+
+// index adds the ID to the state, the first state file that manages a resource is kept
+func (s *State) index(source, id string) {
+	key := strings.ToLower(id)
+	if _, ok := s.managed[key]; !ok {
+		s.managed[key] = source
+	}
+}
+
+func hasProviderPrefix(resourceType string) bool {
+	for _, prefix := range providerPrefixes {
+		if strings.HasPrefix(resourceType, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
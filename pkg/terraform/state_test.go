@@ -0,0 +1,78 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	s, err := Load("testdata", "terraform.tfstate", "show.json")
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name   string
+		id     string
+		source string
+	}{
+		{
+			name:   "resource of the root module",
+			id:     "/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/app-rg",
+			source: "terraform.tfstate",
+		},
+		{
+			name:   "resource of a module is matched case insensitive",
+			id:     "/subscriptions/00000000-0000-0000-0000-000000000001/resourcegroups/APP-RG/providers/Microsoft.Network/virtualNetworks/app-vnet", //nolint:lll
+			source: "terraform.tfstate",
+		},
+		{
+			name:   "azuread object id",
+			id:     "11111111-1111-1111-1111-111111111111",
+			source: "terraform.tfstate",
+		},
+		{
+			name:   "resource of the show output",
+			id:     "/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/app-rg/providers/Microsoft.Storage/storageAccounts/applogs", //nolint:lll
+			source: "show.json",
+		},
+		{
+			name:   "azuread object id of a child module of the show output",
+			id:     "33333333-3333-3333-3333-333333333333",
+			source: "show.json",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			source, ok := s.Lookup(tc.id)
+			assert.True(t, ok)
+			assert.Equal(t, tc.source, source)
+		})
+	}
+
+	_, ok := s.Lookup("/subscriptions/00000000-0000-0000-0000-000000000001")
+	assert.False(t, ok, "data sources are not managed by terraform")
+
+	_, ok = s.Lookup("none")
+	assert.False(t, ok, "resources of other providers are not indexed")
+
+	// Note: the client ID of an application is not the ID of any resource
+	_, ok = s.Lookup("22222222-2222-2222-2222-222222222222")
+	assert.False(t, ok)
+}
+
+func TestLoadErrors(t *testing.T) {
+	s, err := Load("testdata")
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+
+	_, ok := s.Lookup("anything")
+	assert.False(t, ok)
+	assert.Equal(t, 0, s.Len())
+
+	_, err = Load("testdata", "legacy.tfstate")
+	assert.ErrorContains(t, err, "unsupported state version 3")
+
+	_, err = Load("testdata", "missing.tfstate")
+	assert.Error(t, err)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/go-azure-sdk/sdk/odata"
 	"github.com/manicminer/hamilton/msgraph"
@@ -33,11 +32,12 @@ type AzureAdGroupLister struct {
 func (l AzureAdGroupLister) List(_ context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
 
-	log := logrus.WithField("r", AzureAdGroupResource).WithField("s", opts.SubscriptionID)
+	log := opts.Logger(AzureAdGroupResource)
 
 	client := msgraph.NewGroupsClient()
+	client.BaseClient.Endpoint = opts.MicrosoftGraphEndpoint()
 	client.BaseClient.Authorizer = opts.Authorizers.MicrosoftGraph
-	client.BaseClient.DisableRetries = true
+	opts.Authorizers.Pipeline.ConfigureGraph(&client.BaseClient)
 
 	resources := make([]resource.Resource, 0)
 
@@ -57,7 +57,8 @@ func (l AzureAdGroupLister) List(_ context.Context, o interface{}) ([]resource.R
 
 		resources = append(resources, &AzureAdGroup{
 			BaseResource: &BaseResource{
-				Region: ptr.String("global"),
+				Region:     ptr.String("global"),
+				SystemData: &azure.SystemData{CreatedAt: entity.CreatedDateTime},
 			},
 			client: client,
 			ID:     entity.ID(),
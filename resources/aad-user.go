@@ -2,9 +2,9 @@ package resources
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/go-azure-sdk/sdk/odata"
 	"github.com/manicminer/hamilton/msgraph"
@@ -37,11 +37,12 @@ func (l AzureADUserLister) List(ctx context.Context, o interface{}) ([]resource.
 	var resources []resource.Resource
 	opts := o.(*azure.ListerOpts)
 
-	log := logrus.WithField("r", AzureADUserResource).WithField("s", opts.SubscriptionID)
+	log := opts.Logger(AzureADUserResource)
 
 	client := msgraph.NewUsersClient()
+	client.BaseClient.Endpoint = opts.MicrosoftGraphEndpoint()
 	client.BaseClient.Authorizer = opts.Authorizers.Graph
-	client.BaseClient.DisableRetries = true
+	opts.Authorizers.Pipeline.ConfigureGraph(&client.BaseClient)
 
 	log.Trace("attempting to list azure ad users")
 
@@ -57,12 +58,14 @@ func (l AzureADUserLister) List(ctx context.Context, o interface{}) ([]resource.
 
 		resources = append(resources, &AzureADUser{
 			BaseResource: &BaseResource{
-				Region: ptr.String("global"),
+				Region:     ptr.String("global"),
+				SystemData: &azure.SystemData{CreatedAt: entity.CreatedDateTime},
 			},
 			client: client,
 			ID:     entity.ID(),
 			Name:   entity.DisplayName,
 			UPN:    entity.UserPrincipalName,
+			caller: opts.Authorizers.Identity,
 		})
 	}
 
@@ -76,6 +79,16 @@ type AzureADUser struct {
 	ID     *string `description:"The ID of the Entra ID User"`
 	Name   *string `description:"The DisplayName of the Entra ID User"`
 	UPN    *string `description:"This is the user principal name of the Entra ID user, usually in the format of email"`
+
+	caller *azure.Identity
+}
+
+func (r *AzureADUser) Filter() error {
+	if r.caller.IsObject(r.ID) {
+		return fmt.Errorf("cannot delete the user of the identity running the nuke")
+	}
+
+	return r.BaseResource.Filter()
 }
 
 func (r *AzureADUser) Remove(ctx context.Context) error {
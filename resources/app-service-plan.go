@@ -2,9 +2,8 @@ package resources
 
 import (
 	"context"
-	"time"
 
-	"github.com/sirupsen/logrus"
+	"github.com/gotidy/ptr"
 
 	"github.com/Azure/azure-sdk-for-go/services/web/mgmt/2021-03-01/web" //nolint:staticcheck
 
@@ -31,19 +30,30 @@ type AppServicePlanLister struct {
 
 func (l AppServicePlanLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
 
-	log := logrus.WithField("r", AppServicePlanResource).WithField("s", opts.SubscriptionID)
+	if opts.SkipByInventory("microsoft.web/serverfarms") {
+		return nil, nil
+	}
+
+	return opts.ListByResourceGroup(ctx, AppServicePlanResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
+	})
+}
+
+// listSubscription lists the app service plans of every resource group of the subscription
+func (l AppServicePlanLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
+	log := opts.Logger(AppServicePlanResource)
 
-	client := web.NewAppServicePlansClient(opts.SubscriptionID)
+	client := web.NewAppServicePlansClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
 	client.Authorizer = opts.Authorizers.Management
-	client.RetryAttempts = 1
-	client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	resources := make([]resource.Resource, 0)
 
 	log.Trace("attempting to list ssh key")
 
-	list, err := client.ListByResourceGroup(ctx, opts.ResourceGroup)
+	list, err := client.List(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -55,10 +65,15 @@ func (l AppServicePlanLister) List(ctx context.Context, o interface{}) ([]resour
 		for _, g := range list.Values() {
 			resources = append(resources, &AppServicePlan{
 				BaseResource: &BaseResource{
-					ResourceGroup: &opts.ResourceGroup,
+					ResourceGroup: opts.ResourceGroupFromID(g.ID),
+					SystemData:    opts.SystemData(ctx, g.ID),
+					Tags:          g.Tags,
 				},
-				client: client,
-				Name:   *g.Name,
+				client:   client,
+				plan:     g,
+				ID:       g.ID,
+				Name:     *g.Name,
+				Capacity: appServicePlanCapacity(g),
 			})
 		}
 
@@ -75,15 +90,49 @@ func (l AppServicePlanLister) List(ctx context.Context, o interface{}) ([]resour
 type AppServicePlan struct {
 	*BaseResource `property:",inline"`
 
-	client web.AppServicePlansClient
-	Name   string
+	client   web.AppServicePlansClient
+	plan     web.AppServicePlan
+	ID       *string `property:"-"`
+	Name     string
+	Capacity *int32 `description:"The number of instances of the plan."`
 }
 
 func (r *AppServicePlan) Remove(ctx context.Context) error {
+	if r.StopMode() {
+		return r.Stop(ctx)
+	}
+
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Delete(ctx, r.GetResourceGroup(), r.Name)
 	return err
 }
 
+func (r *AppServicePlan) StopAction() string {
+	return "scale down to one instance"
+}
+
+func (r *AppServicePlan) Stopped() bool {
+	return r.Capacity == nil || *r.Capacity <= 1
+}
+
+// Stop scales the plan down, the plan is updated as it was listed so that none of its other settings change
+func (r *AppServicePlan) Stop(ctx context.Context) error {
+	plan := r.plan
+	sku := *plan.Sku
+	sku.Capacity = ptr.Int32(1)
+	plan.Sku = &sku
+
+	_, err := r.client.CreateOrUpdate(ctx, r.GetResourceGroup(), r.Name, plan)
+	return err
+}
+
+func (r *AppServicePlan) VolatileProperties() []string {
+	return []string{"Capacity"}
+}
+
 func (r *AppServicePlan) Properties() types.Properties {
 	return types.NewPropertiesFromStruct(r)
 }
@@ -91,3 +140,11 @@ func (r *AppServicePlan) Properties() types.Properties {
 func (r *AppServicePlan) String() string {
 	return r.Name
 }
+
+func appServicePlanCapacity(plan web.AppServicePlan) *int32 {
+	if plan.Sku == nil {
+		return nil
+	}
+
+	return plan.Sku.Capacity
+}
@@ -2,9 +2,9 @@ package resources
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/go-azure-sdk/sdk/odata"
 	"github.com/manicminer/hamilton/msgraph"
@@ -34,10 +34,17 @@ type ApplicationCertificate struct {
 	ID     *string
 	Name   *string
 	AppID  *string
+
+	clientID *string
+	caller   *azure.Identity
 }
 
 func (r *ApplicationCertificate) Filter() error {
-	return nil
+	if r.caller.IsApplication(r.clientID) {
+		return fmt.Errorf("cannot delete the credentials of the identity running the nuke")
+	}
+
+	return r.BaseResource.Filter()
 }
 
 func (r *ApplicationCertificate) Remove(ctx context.Context) error {
@@ -60,11 +67,12 @@ func (l ApplicationCertificateLister) List(ctx context.Context, o interface{}) (
 	var resources []resource.Resource
 	opts := o.(*azure.ListerOpts)
 
-	log := logrus.WithField("r", ApplicationCertificateResource).WithField("s", opts.SubscriptionID)
+	log := opts.Logger(ApplicationCertificateResource)
 
 	client := msgraph.NewApplicationsClient()
+	client.BaseClient.Endpoint = opts.MicrosoftGraphEndpoint()
 	client.BaseClient.Authorizer = opts.Authorizers.Graph
-	client.BaseClient.DisableRetries = true
+	opts.Authorizers.Pipeline.ConfigureGraph(&client.BaseClient)
 
 	log.Trace("attempting to list application certificates")
 
@@ -83,10 +91,12 @@ func (l ApplicationCertificateLister) List(ctx context.Context, o interface{}) (
 				BaseResource: &BaseResource{
 					Region: ptr.String("global"),
 				},
-				client: client,
-				ID:     cred.KeyId,
-				Name:   cred.DisplayName,
-				AppID:  entity.ID(),
+				client:   client,
+				ID:       cred.KeyId,
+				Name:     cred.DisplayName,
+				AppID:    entity.ID(),
+				clientID: entity.AppId,
+				caller:   opts.Authorizers.Identity,
 			})
 		}
 	}
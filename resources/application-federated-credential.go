@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/go-azure-sdk/sdk/odata"
 	"github.com/manicminer/hamilton/msgraph"
@@ -36,10 +35,17 @@ type ApplicationFederatedCredential struct {
 	Name        *string
 	AppID       *string
 	DisplayName *string
+
+	clientID *string
+	caller   *azure.Identity
 }
 
 func (r *ApplicationFederatedCredential) Filter() error {
-	return nil
+	if r.caller.IsApplication(r.clientID) {
+		return fmt.Errorf("cannot delete the credentials of the identity running the nuke")
+	}
+
+	return r.BaseResource.Filter()
 }
 
 func (r *ApplicationFederatedCredential) Remove(ctx context.Context) error {
@@ -64,11 +70,12 @@ func (l ApplicationFederatedCredentialLister) List(ctx context.Context, o interf
 	var resources []resource.Resource
 	opts := o.(*azure.ListerOpts)
 
-	log := logrus.WithField("r", ApplicationFederatedCredentialResource).WithField("s", opts.SubscriptionID)
+	log := opts.Logger(ApplicationFederatedCredentialResource)
 
 	client := msgraph.NewApplicationsClient()
+	client.BaseClient.Endpoint = opts.MicrosoftGraphEndpoint()
 	client.BaseClient.Authorizer = opts.Authorizers.Graph
-	client.BaseClient.DisableRetries = true
+	opts.Authorizers.Pipeline.ConfigureGraph(&client.BaseClient)
 
 	log.Trace("attempting to list application federated creds")
 
@@ -97,6 +104,8 @@ func (l ApplicationFederatedCredentialLister) List(ctx context.Context, o interf
 				Name:        cred.Name,
 				AppID:       entity.ID(),
 				DisplayName: entity.DisplayName,
+				clientID:    entity.AppId,
+				caller:      opts.Authorizers.Identity,
 			})
 		}
 	}
@@ -5,7 +5,6 @@ import (
 	"time"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
@@ -15,7 +14,6 @@ import (
 	network "github.com/hashicorp/go-azure-sdk/resource-manager/network/2023-09-01"
 	"github.com/hashicorp/go-azure-sdk/resource-manager/network/2023-09-01/applicationgateways"
 	"github.com/hashicorp/go-azure-sdk/sdk/client/resourcemanager"
-	"github.com/hashicorp/go-azure-sdk/sdk/environments"
 
 	"github.com/ekristen/azure-nuke/pkg/azure"
 )
@@ -34,13 +32,27 @@ func init() {
 type ApplicationGatewayLister struct{}
 
 func (l ApplicationGatewayLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
-	var resources []resource.Resource
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
+
+	if opts.SkipByInventory("microsoft.network/applicationgateways") {
+		return nil, nil
+	}
+
+	return opts.ListByResourceGroup(ctx, ApplicationGatewayResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
+	})
+}
 
-	log := logrus.WithField("r", ApplicationGatewayResource).WithField("s", opts.SubscriptionID)
+// listSubscription lists the application gateways of every resource group of the subscription
+func (l ApplicationGatewayLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
+	var resources []resource.Resource
+
+	log := opts.Logger(ApplicationGatewayResource)
 
-	client, err := network.NewClientWithBaseURI(environments.AzurePublic().ResourceManager, func(c *resourcemanager.Client) {
+	client, err := network.NewClientWithBaseURI(opts.Authorizers.Environment.ResourceManager, func(c *resourcemanager.Client) {
 		c.Authorizer = opts.Authorizers.ResourceManager
+		opts.Authorizers.Pipeline.ConfigureResourceManager(c)
 	})
 	if err != nil {
 		return nil, err
@@ -53,19 +65,21 @@ func (l ApplicationGatewayLister) List(ctx context.Context, o interface{}) ([]re
 
 	log.Trace("attempting to list applications")
 
-	listing, err := client.ApplicationGateways.List(ctx, commonids.NewResourceGroupID(opts.SubscriptionID, opts.ResourceGroup))
+	listing, err := client.ApplicationGateways.ListAllComplete(ctx, commonids.NewSubscriptionID(opts.SubscriptionID))
 	if err != nil {
 		return nil, err
 	}
 
 	log.Trace("listing applications")
 
-	for _, entry := range *listing.Model {
+	for _, entry := range listing.Items {
 		resources = append(resources, &ApplicationGateway{
 			BaseResource: &BaseResource{
 				Region:         ptr.String("global"),
 				SubscriptionID: ptr.String(opts.SubscriptionID), // note: this is just the guid
-				ResourceGroup:  ptr.String(opts.ResourceGroup),
+				ResourceGroup:  opts.ResourceGroupFromID(entry.Id),
+				SystemData:     opts.SystemData(ctx, entry.Id),
+				Tags:           azure.Tags(entry.Tags),
 			},
 			client: client,
 			ID:     entry.Id,
@@ -91,10 +105,14 @@ func (r *ApplicationGateway) CommonID() applicationgateways.ApplicationGatewayId
 }
 
 func (r *ApplicationGateway) Filter() error {
-	return nil
+	return r.BaseResource.Filter()
 }
 
 func (r *ApplicationGateway) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	if _, err := r.client.ApplicationGateways.Delete(ctx, r.CommonID()); err != nil {
 		return err
 	}
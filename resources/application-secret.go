@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/go-azure-sdk/sdk/odata"
 	"github.com/manicminer/hamilton/msgraph"
@@ -36,10 +35,17 @@ type ApplicationSecret struct {
 	Name    *string `description:"The display name of the Application Secret"`
 	AppID   *string `description:"The unique ID of the Application to which the secret belongs"`
 	AppName *string `description:"The display name of the Application to which the secret belongs"`
+
+	clientID *string
+	caller   *azure.Identity
 }
 
 func (r *ApplicationSecret) Filter() error {
-	return nil
+	if r.caller.IsApplication(r.clientID) {
+		return fmt.Errorf("cannot delete the credentials of the identity running the nuke")
+	}
+
+	return r.BaseResource.Filter()
 }
 
 func (r *ApplicationSecret) Remove(ctx context.Context) error {
@@ -62,11 +68,12 @@ func (l ApplicationSecretLister) List(ctx context.Context, o interface{}) ([]res
 	var resources []resource.Resource
 	opts := o.(*azure.ListerOpts)
 
-	log := logrus.WithField("r", ApplicationSecretResource).WithField("s", opts.SubscriptionID)
+	log := opts.Logger(ApplicationSecretResource)
 
 	client := msgraph.NewApplicationsClient()
+	client.BaseClient.Endpoint = opts.MicrosoftGraphEndpoint()
 	client.BaseClient.Authorizer = opts.Authorizers.Graph
-	client.BaseClient.DisableRetries = true
+	opts.Authorizers.Pipeline.ConfigureGraph(&client.BaseClient)
 
 	log.Trace("attempting to list application secrets")
 
@@ -85,11 +92,13 @@ func (l ApplicationSecretLister) List(ctx context.Context, o interface{}) ([]res
 				BaseResource: &BaseResource{
 					Region: ptr.String("global"),
 				},
-				client:  client,
-				KeyID:   cred.KeyId,
-				Name:    cred.DisplayName,
-				AppID:   entity.ID(),
-				AppName: entity.DisplayName,
+				client:   client,
+				KeyID:    cred.KeyId,
+				Name:     cred.DisplayName,
+				AppID:    entity.ID(),
+				AppName:  entity.DisplayName,
+				clientID: entity.AppId,
+				caller:   opts.Authorizers.Identity,
 			})
 		}
 	}
@@ -2,9 +2,9 @@ package resources
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/go-azure-sdk/sdk/odata"
 	"github.com/manicminer/hamilton/msgraph"
@@ -34,11 +34,12 @@ func (l ApplicationLister) List(ctx context.Context, o interface{}) ([]resource.
 	var resources []resource.Resource
 	opts := o.(*azure.ListerOpts)
 
-	log := logrus.WithField("r", ApplicationResource).WithField("s", opts.SubscriptionID)
+	log := opts.Logger(ApplicationResource)
 
 	client := msgraph.NewApplicationsClient()
+	client.BaseClient.Endpoint = opts.MicrosoftGraphEndpoint()
 	client.BaseClient.Authorizer = opts.Authorizers.Graph
-	client.BaseClient.DisableRetries = true
+	opts.Authorizers.Pipeline.ConfigureGraph(&client.BaseClient)
 
 	log.Trace("attempting to list applications")
 
@@ -54,11 +55,14 @@ func (l ApplicationLister) List(ctx context.Context, o interface{}) ([]resource.
 
 		resources = append(resources, &Application{
 			BaseResource: &BaseResource{
-				Region: ptr.String("global"),
+				Region:     ptr.String("global"),
+				SystemData: &azure.SystemData{CreatedAt: entity.CreatedDateTime},
 			},
-			client: client,
-			ID:     entity.ID(),
-			Name:   entity.DisplayName,
+			client:   client,
+			ID:       entity.ID(),
+			Name:     entity.DisplayName,
+			clientID: entity.AppId,
+			caller:   opts.Authorizers.Identity,
 		})
 	}
 
@@ -73,10 +77,17 @@ type Application struct {
 	client *msgraph.ApplicationsClient
 	ID     *string
 	Name   *string
+
+	clientID *string
+	caller   *azure.Identity
 }
 
 func (r *Application) Filter() error {
-	return nil
+	if r.caller.IsApplication(r.clientID) {
+		return fmt.Errorf("cannot delete the application of the identity running the nuke")
+	}
+
+	return r.BaseResource.Filter()
 }
 
 func (r *Application) Remove(ctx context.Context) error {
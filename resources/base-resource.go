@@ -1,9 +1,19 @@
 package resources
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/gotidy/ptr"
 
+	liberrors "github.com/ekristen/libnuke/pkg/errors"
 	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/resource"
+	"github.com/ekristen/libnuke/pkg/types"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
 )
 
 // BaseResource is a base struct that all Azure resources should embed to provide common fields and methods.
@@ -11,6 +21,28 @@ type BaseResource struct {
 	Region         *string `description:"The region that the resource group belongs to."`
 	SubscriptionID *string `description:"The subscription ID that the resource group belongs to."`
 	ResourceGroup  *string `description:"The resource group that the resource belongs to."`
+	ResourceID     *string `description:"The ARM resource ID, or the object ID of Entra ID objects, of the resource."`
+
+	Tags map[string]*string `description:"The tags assigned to the resource."`
+
+	// SystemData provides the CreatedAt, CreatedBy, CreatedByType, LastModifiedAt and LastModifiedBy properties
+	*azure.SystemData `property:",inline"`
+
+	ResourceGroupLocation *string `description:"The location of the resource group that the resource belongs to."`
+
+	ManagedByTerraform *bool   `description:"Whether the resource is in one of the configured terraform states."`
+	TerraformState     *string `description:"The terraform state file that manages the resource."`
+
+	// Note: the tag prefix applies to every map after this field, so this has to remain the last map of the resource
+	ResourceGroupTags map[string]*string `property:"tagPrefix=rg-tag" description:"The tags of the resource group that the resource belongs to."` //nolint:lll
+
+	locks      *azure.Locks
+	lockTarget azure.LockTarget
+	quarantine *quarantine
+	stop       bool
+
+	// filtered is the reason the resource is filtered by BeforeEnqueue
+	filtered string
 }
 
 // GetRegion returns the region that the resource belongs to.
@@ -28,10 +60,205 @@ func (r *BaseResource) GetResourceGroup() string {
 	return ptr.ToString(r.ResourceGroup)
 }
 
+// Filter filters the resource for the reason set by BeforeEnqueue. The state of the item cannot be set by
+// BeforeEnqueue, libnuke resets the state of the items with dependencies afterwards when waiting on dependencies.
+// Every resource with its own Filter function must return the result of this function if it does not filter the
+// resource itself. This is safe to call on a nil resource.
+func (r *BaseResource) Filter() error {
+	if r == nil || r.filtered == "" {
+		return nil
+	}
+
+	return errors.New(r.filtered)
+}
+
+// filter filters the resource for the reason, unless it is filtered already
+func (r *BaseResource) filter(reason string) {
+	if r.filtered == "" {
+		r.filtered = reason
+	}
+}
+
 // BeforeEnqueue is a special hook that is called from github.com/ekristen/libnuke that allows the resource to
-// modify the queue item before it is put on the queue, in this case it allows us to modify the owner field to
-// set it as the region so the behavior of this tool is consistent with the other tools based on libnuke and regions
+// modify the queue item before it is put on the queue. The resources it filters are filtered by Filter. It:
+//   - sets the owner of the item to the region, consistent with the other tools based on libnuke
+//   - exposes the ID of the resource as the ResourceID property, as most resources do not expose it themselves
+//   - sets the location and tags of the resource group and filters the resource if its resource group is filtered
+//   - sets whether the resource is managed by terraform
+//   - in stop mode, filters the resource if it cannot be stopped or is already stopped
+//   - otherwise, filters the resource until its quarantine grace period has expired, or if it is protected by a
+//     management lock that is kept
 func (r *BaseResource) BeforeEnqueue(item interface{}) {
 	i := item.(*queue.Item)
 	i.Owner = ptr.ToString(r.Region)
+
+	if id := azure.ResourceID(i.Resource); id != "" {
+		r.ResourceID = ptr.String(id)
+	}
+
+	opts, ok := i.Opts.(*azure.ListerOpts)
+	if !ok {
+		return
+	}
+
+	r.inheritResourceGroup(i, opts)
+	r.checkTerraform(i, opts)
+
+	// Note: locks are ignored in stop mode, a CanNotDelete lock does not prevent stopping the resource and a ReadOnly
+	// lock results in the failure of the stop
+	if opts.Stop {
+		r.checkStop(i)
+		return
+	}
+
+	r.checkLocks(i, opts)
+	r.checkQuarantine(i, opts)
+}
+
+// checkLocks keeps the locks that can block the removal of the resource and filters the resource if it is protected
+// by a management lock that is kept. The locks are checked before the quarantine, a resource that is kept by a lock is
+// not marked for removal.
+func (r *BaseResource) checkLocks(i *queue.Item, opts *azure.ListerOpts) {
+	if opts.Locks == nil || i.Type == ManagementLockResource {
+		return
+	}
+
+	r.locks = opts.Locks
+	r.lockTarget = azure.LockTarget{
+		ID:             azure.ResourceID(i.Resource),
+		SubscriptionID: ptr.ToString(r.SubscriptionID),
+		ResourceGroup:  ptr.ToString(r.ResourceGroup),
+	}
+
+	if stringer, ok := i.Resource.(resource.LegacyStringer); ok {
+		r.lockTarget.Name = stringer.String()
+	}
+
+	for _, lock := range r.locks.Blocking(r.lockTarget) {
+		if lock.Kept {
+			r.filter(fmt.Sprintf("protected by lock %s", lock.Name))
+		}
+		break
+	}
+}
+
+// inheritResourceGroup sets the location and tags of the resource group that the resource belongs to, the resource
+// is filtered if the resource group is filtered.
+func (r *BaseResource) inheritResourceGroup(i *queue.Item, opts *azure.ListerOpts) {
+	if opts.ResourceGroupDetails == nil || i.Type == ResourceGroupResource {
+		return
+	}
+
+	name := ptr.ToString(r.ResourceGroup)
+	if name == "" {
+		name = ptr.ToString(azure.GetResourceGroupFromID(azure.ResourceID(i.Resource)))
+	}
+
+	subscriptionID := ptr.ToString(r.SubscriptionID)
+	if subscriptionID == "" {
+		subscriptionID = opts.SubscriptionID
+	}
+
+	group := opts.ResourceGroupDetails.Get(subscriptionID, name)
+	if group == nil {
+		return
+	}
+
+	r.ResourceGroupLocation = ptr.String(group.Location)
+	r.ResourceGroupTags = group.Tags
+
+	if group.Filtered {
+		r.filter(fmt.Sprintf("resource group %s is filtered", group.Name))
+	}
+}
+
+// checkTerraform sets whether the resource is managed by terraform, the properties are only set when a terraform state
+// is configured
+func (r *BaseResource) checkTerraform(i *queue.Item, opts *azure.ListerOpts) {
+	if opts.Terraform == nil {
+		return
+	}
+
+	source, ok := opts.Terraform.Lookup(ptr.ToString(r.ResourceID))
+
+	r.ManagedByTerraform = ptr.Bool(ok)
+	if ok {
+		r.TerraformState = ptr.String(source)
+	}
+}
+
+// enqueuedProperties are the properties that are set by BeforeEnqueue, a resource that is listed again does not have
+// them. The tags of the resource group, prefixed with rg-tag, are set by BeforeEnqueue as well.
+var enqueuedProperties = []string{"ResourceID", "ResourceGroupLocation", "ManagedByTerraform", "TerraformState"}
+
+// volatileProperties are the properties of every resource that change while it is removed or stopped
+var volatileProperties = []string{"LastModifiedAt", "LastModifiedBy"}
+
+// VolatileResource is implemented by the resources whose own properties change while they are removed or stopped
+// (i.e. the power state of a virtual machine that is deallocated)
+type VolatileResource interface {
+	VolatileProperties() []string
+}
+
+// SameResource checks if a resource that is listed again, to check if the queued resource has been removed, is the
+// queued resource. The properties that are set before the resource is enqueued are left out of the comparison, the
+// listed resource does not have them, as are the properties that change while the resource is removed or stopped.
+func SameResource(queued, listed resource.Resource) bool {
+	if fmt.Sprintf("%T", queued) != fmt.Sprintf("%T", listed) {
+		return false
+	}
+
+	queuedGetter, queuedOK := queued.(resource.PropertyGetter)
+	listedGetter, listedOK := listed.(resource.PropertyGetter)
+	if queuedOK && listedOK {
+		excluded := append(append([]string{}, enqueuedProperties...), volatileProperties...)
+		if volatile, ok := queued.(VolatileResource); ok {
+			excluded = append(excluded, volatile.VolatileProperties()...)
+		}
+
+		return comparableProperties(queuedGetter.Properties(), excluded).
+			Equals(comparableProperties(listedGetter.Properties(), excluded))
+	}
+
+	queuedStringer, queuedOK := queued.(resource.LegacyStringer)
+	listedStringer, listedOK := listed.(resource.LegacyStringer)
+	if queuedOK && listedOK {
+		return queuedStringer.String() == listedStringer.String()
+	}
+
+	return false
+}
+
+// comparableProperties returns the properties without the excluded ones, the tags of the resource group and the keys
+// that are internal to libnuke
+func comparableProperties(props types.Properties, excluded []string) types.Properties {
+	filtered := make(types.Properties)
+	for k, v := range props {
+		if slices.Contains(excluded, k) || strings.HasPrefix(k, "rg-tag:") || strings.HasPrefix(k, "_") {
+			continue
+		}
+
+		filtered[k] = v
+	}
+
+	return filtered
+}
+
+// HoldForLocks must be called before a resource is removed, the removal is held until the management locks that
+// block it have been removed. An error is returned if the resource is blocked by a lock that is kept or that could
+// not be removed.
+func (r *BaseResource) HoldForLocks() error {
+	for _, lock := range r.locks.Blocking(r.lockTarget) {
+		if lock.Kept {
+			return fmt.Errorf("protected by lock %s", lock.Name)
+		}
+
+		if lock.Failed {
+			return fmt.Errorf("blocked by lock %s, which could not be removed", lock.Name)
+		}
+
+		return liberrors.ErrHoldResource(fmt.Sprintf("waiting for lock %s to be removed", lock.Name))
+	}
+
+	return nil
 }
@@ -0,0 +1,54 @@
+package resources
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+)
+
+// enqueue calls the BeforeEnqueue hook of the resource of the item and filters it by its Filter function, as libnuke
+// does for every resource that is listed
+func enqueue(item *queue.Item) {
+	if hook, ok := item.Resource.(resource.QueueItemHook); ok {
+		hook.BeforeEnqueue(item)
+	}
+
+	if checker, ok := item.Resource.(resource.Filter); ok {
+		if err := checker.Filter(); err != nil {
+			item.State = queue.ItemStateFiltered
+			item.Reason = err.Error()
+		}
+	}
+}
+
+func TestResourcesAreFilteredByBaseResource(t *testing.T) {
+	for _, name := range registry.GetNames() {
+		reg := registry.GetRegistration(name)
+
+		typ := reflect.TypeOf(reg.Resource)
+		if typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+			continue
+		}
+
+		field, ok := typ.Elem().FieldByName("BaseResource")
+		if !assert.True(t, ok, "%s does not embed BaseResource", name) || !field.Anonymous {
+			continue
+		}
+
+		r := reflect.New(typ.Elem())
+		r.Elem().FieldByName("BaseResource").Set(reflect.ValueOf(&BaseResource{filtered: "filtered before enqueue"}))
+
+		checker, ok := r.Interface().(resource.Filter)
+		if !assert.True(t, ok, "%s has no Filter function", name) {
+			continue
+		}
+
+		assert.Error(t, checker.Filter(),
+			"the Filter function of %s has to return the result of BaseResource.Filter", name)
+	}
+}
@@ -6,11 +6,9 @@ import (
 	"time"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/go-azure-helpers/resourcemanager/commonids"
 	"github.com/hashicorp/go-azure-sdk/resource-manager/consumption/2021-10-01/budgets"
-	"github.com/hashicorp/go-azure-sdk/sdk/environments"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
@@ -42,15 +40,17 @@ type BudgetLister struct{}
 
 func (l BudgetLister) List(pctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	pctx = azure.WithSystemData(pctx)
 	var resources []resource.Resource
 
-	log := logrus.WithField("r", BudgetResource).WithField("s", opts.SubscriptionID)
+	log := opts.Logger(BudgetResource)
 
-	client, err := budgets.NewBudgetsClientWithBaseURI(environments.AzurePublic().ResourceManager)
+	client, err := budgets.NewBudgetsClientWithBaseURI(opts.Authorizers.Environment.ResourceManager)
 	if err != nil {
 		return nil, err
 	}
 	client.Client.Authorizer = opts.Authorizers.Management
+	opts.Authorizers.Pipeline.ConfigureResourceManager(client.Client)
 
 	log.Trace("attempting to list budgets for subscription")
 
@@ -71,6 +71,7 @@ func (l BudgetLister) List(pctx context.Context, o interface{}) ([]resource.Reso
 			BaseResource: &BaseResource{
 				Region:         ptr.String("global"),
 				SubscriptionID: ptr.String(opts.SubscriptionID), // note: this is just the guid
+				SystemData:     opts.SystemData(ctx, entry.Id),
 			},
 			client: client,
 			ID:     entry.Id,
@@ -84,6 +85,10 @@ func (l BudgetLister) List(pctx context.Context, o interface{}) ([]resource.Reso
 }
 
 func (r *Budget) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(10*time.Second))
 	defer cancel()
 
@@ -2,9 +2,6 @@ package resources
 
 import (
 	"context"
-	"time"
-
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/services/containerregistry/mgmt/2019-05-01/containerregistry" //nolint:staticcheck
 
@@ -30,11 +27,15 @@ type ContainerRegistry struct {
 	*BaseResource `property:",inline"`
 
 	client containerregistry.RegistriesClient
+	ID     *string `property:"-"`
 	Name   *string
-	Tags   map[string]*string
 }
 
 func (r *ContainerRegistry) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Delete(ctx, *r.ResourceGroup, *r.Name)
 	return err
 }
@@ -52,18 +53,30 @@ type ContainerRegistryLister struct {
 
 func (l ContainerRegistryLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
+
+	if opts.SkipByInventory("microsoft.containerregistry/registries") {
+		return nil, nil
+	}
+
+	return opts.ListByResourceGroup(ctx, ContainerRegistryResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
+	})
+}
+
+// listSubscription lists the container registries of every resource group of the subscription
+func (l ContainerRegistryLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
 	var resources []resource.Resource
 
-	log := logrus.WithField("r", ContainerRegistryResource).WithField("s", opts.SubscriptionID)
+	log := opts.Logger(ContainerRegistryResource)
 
-	client := containerregistry.NewRegistriesClient(opts.SubscriptionID)
+	client := containerregistry.NewRegistriesClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
 	client.Authorizer = opts.Authorizers.Management
-	client.RetryAttempts = 1
-	client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	log.Trace("attempting to list container registries")
 
-	list, err := client.ListByResourceGroup(ctx, opts.ResourceGroup)
+	list, err := client.List(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -76,12 +89,14 @@ func (l ContainerRegistryLister) List(ctx context.Context, o interface{}) ([]res
 			resources = append(resources, &ContainerRegistry{
 				BaseResource: &BaseResource{
 					Region:         entity.Location,
-					ResourceGroup:  &opts.ResourceGroup,
+					ResourceGroup:  opts.ResourceGroupFromID(entity.ID),
 					SubscriptionID: &opts.SubscriptionID,
+					SystemData:     opts.SystemData(ctx, entity.ID),
+					Tags:           entity.Tags,
 				},
 				client: client,
+				ID:     entity.ID,
 				Name:   entity.Name,
-				Tags:   entity.Tags,
 			})
 		}
 
@@ -5,7 +5,6 @@ import (
 	"time"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute" //nolint:staticcheck
 
@@ -34,12 +33,16 @@ type Disk struct {
 	*BaseResource `property:",inline"`
 
 	client       compute.DisksClient
+	ID           *string `property:"-"`
 	Name         *string
-	Tags         map[string]*string
 	CreationDate *time.Time
 }
 
 func (r *Disk) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Delete(ctx, *r.ResourceGroup, *r.Name)
 	return err
 }
@@ -57,19 +60,30 @@ type DiskLister struct {
 
 func (l DiskLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
+
+	if opts.SkipByInventory("microsoft.compute/disks") {
+		return nil, nil
+	}
+
+	return opts.ListByResourceGroup(ctx, DiskResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
+	})
+}
 
-	log := logrus.WithField("r", DiskResource).WithField("s", opts.SubscriptionID)
+// listSubscription lists the disks of every resource group of the subscription
+func (l DiskLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
+	log := opts.Logger(DiskResource)
 
-	client := compute.NewDisksClient(opts.SubscriptionID)
+	client := compute.NewDisksClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
 	client.Authorizer = opts.Authorizers.Management
-	client.RetryAttempts = 1
-	client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	resources := make([]resource.Resource, 0)
 
 	log.Trace("attempting to list disks")
 
-	list, err := client.ListByResourceGroup(ctx, opts.ResourceGroup)
+	list, err := client.List(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -82,12 +96,14 @@ func (l DiskLister) List(ctx context.Context, o interface{}) ([]resource.Resourc
 			resources = append(resources, &Disk{
 				BaseResource: &BaseResource{
 					Region:         r.Location,
-					ResourceGroup:  &opts.ResourceGroup,
+					ResourceGroup:  opts.ResourceGroupFromID(r.ID),
 					SubscriptionID: &opts.SubscriptionID,
+					SystemData:     opts.SystemData(ctx, r.ID),
+					Tags:           r.Tags,
 				},
 				client:       client,
+				ID:           r.ID,
 				Name:         r.Name,
-				Tags:         r.Tags,
 				CreationDate: ptr.Time(r.DiskProperties.TimeCreated.Time),
 			})
 		}
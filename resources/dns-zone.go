@@ -2,9 +2,6 @@ package resources
 
 import (
 	"context"
-	"time"
-
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/services/dns/mgmt/2018-05-01/dns" //nolint:staticcheck
 
@@ -31,22 +28,30 @@ type DNSZoneLister struct {
 
 func (l DNSZoneLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
+
+	if opts.SkipByInventory("microsoft.network/dnszones") {
+		return nil, nil
+	}
 
-	log := logrus.WithFields(logrus.Fields{
-		"r": DNSZoneResource,
-		"s": opts.SubscriptionID,
+	return opts.ListByResourceGroup(ctx, DNSZoneResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
 	})
+}
+
+// listSubscription lists the dns zones of every resource group of the subscription
+func (l DNSZoneLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
+	log := opts.Logger(DNSZoneResource)
 
 	log.Trace("start")
 
-	client := dns.NewZonesClient(opts.SubscriptionID)
+	client := dns.NewZonesClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
 	client.Authorizer = opts.Authorizers.Management
-	client.RetryAttempts = 1
-	client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	resources := make([]resource.Resource, 0)
 
-	list, err := client.ListByResourceGroup(ctx, opts.ResourceGroup, nil)
+	list, err := client.List(ctx, nil)
 	if err != nil {
 		log.WithError(err).Error("unable to list")
 		return nil, err
@@ -61,12 +66,14 @@ func (l DNSZoneLister) List(ctx context.Context, o interface{}) ([]resource.Reso
 			resources = append(resources, &DNSZone{
 				BaseResource: &BaseResource{
 					Region:         g.Location,
-					ResourceGroup:  &opts.ResourceGroup,
+					ResourceGroup:  opts.ResourceGroupFromID(g.ID),
 					SubscriptionID: &opts.SubscriptionID,
+					SystemData:     opts.SystemData(ctx, g.ID),
+					Tags:           g.Tags,
 				},
 				client: client,
+				ID:     g.ID,
 				Name:   g.Name,
-				Tags:   g.Tags,
 			})
 		}
 
@@ -84,11 +91,15 @@ type DNSZone struct {
 	*BaseResource `property:",inline"`
 
 	client dns.ZonesClient
+	ID     *string `property:"-"`
 	Name   *string
-	Tags   map[string]*string
 }
 
 func (r *DNSZone) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Delete(ctx, *r.ResourceGroup, *r.Name, "")
 	return err
 }
@@ -0,0 +1,111 @@
+package resources
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+
+	"github.com/hashicorp/go-azure-sdk/sdk/auth/autorest"
+	"github.com/hashicorp/go-azure-sdk/sdk/environments"
+
+	"github.com/ekristen/libnuke/pkg/registry"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+// testAuthorizer is a fake go-azure-sdk authorizer that hands out a static token
+type testAuthorizer struct{}
+
+func (a *testAuthorizer) Token(_ context.Context, _ *http.Request) (*oauth2.Token, error) {
+	return &oauth2.Token{AccessToken: "fake-token", TokenType: "Bearer"}, nil
+}
+
+func (a *testAuthorizer) AuxiliaryTokens(_ context.Context, _ *http.Request) ([]*oauth2.Token, error) {
+	return nil, nil
+}
+
+// testCredential is a fake azcore credential that hands out a static token
+type testCredential struct{}
+
+func (c *testCredential) GetToken(_ context.Context, _ policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "fake-token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+// newTestAuthorizers returns authorizers whose environment points both resource manager and microsoft graph
+// at the provided endpoint
+func newTestAuthorizers(t testing.TB, endpoint string) *azure.Authorizers {
+	t.Helper()
+
+	env := environments.AzurePublic()
+	env.Name = "test"
+	env.ResourceManager = environments.ResourceManagerAPI(endpoint)
+	env.MicrosoftGraph = environments.MicrosoftGraphAPI(endpoint)
+
+	cloudConfig, err := azure.NewCloudConfiguration(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authorizer := &testAuthorizer{}
+	pipeline := azure.NewPipeline(nil)
+
+	return &azure.Authorizers{
+		Graph:           autorest.AutorestAuthorizer(authorizer),
+		Management:      autorest.AutorestAuthorizer(authorizer),
+		MicrosoftGraph:  authorizer,
+		ResourceManager: authorizer,
+		IdentityCreds:   &testCredential{},
+		Environment:     env,
+		ClientOptions: azcore.ClientOptions{
+			Cloud:                           cloudConfig,
+			InsecureAllowCredentialWithHTTP: true,
+			Transport:                       pipeline.HTTPClient(),
+			Retry: policy.RetryOptions{
+				MaxRetries: -1,
+			},
+		},
+		Pipeline: pipeline,
+	}
+}
+
+func TestListersUseConfiguredEnvironment(t *testing.T) {
+	var requests atomic.Int64
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"value":[]}`))
+	}))
+	defer server.Close()
+
+	authorizers := newTestAuthorizers(t, server.URL)
+
+	for name, reg := range registry.GetRegistrations() {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			before := requests.Load()
+
+			_, err := reg.Lister.List(ctx, &azure.ListerOpts{
+				Authorizers:       authorizers,
+				TenantID:          "00000000-0000-0000-0000-000000000000",
+				ManagementGroupID: "test-mg",
+				SubscriptionID:    "00000000-0000-0000-0000-000000000001",
+				ResourceGroup:     "test-rg",
+				Regions:           []string{"all"},
+			})
+			assert.NoError(t, err)
+			assert.Greater(t, requests.Load(), before, "lister did not call the configured environment endpoint")
+		})
+	}
+}
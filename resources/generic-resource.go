@@ -0,0 +1,332 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gotidy/ptr"
+
+	resourcesapi "github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2021-04-01/resources" //nolint:staticcheck
+
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+	libsettings "github.com/ekristen/libnuke/pkg/settings"
+	"github.com/ekristen/libnuke/pkg/types"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+const GenericResourceResource = "GenericResource"
+
+// GenericResourceRemoveManagedSetting is the setting that removes the resources that are managed by another resource,
+// these are filtered by default as their owner creates and removes them (i.e. the node resource group and disks of an
+// AKS cluster) and removing them directly races with the owner.
+const GenericResourceRemoveManagedSetting = "RemoveManagedResources"
+
+// userAssignedIdentityResourceType is the ARM resource type of user-assigned managed identities, which the nuke may be
+// running as
+const userAssignedIdentityResourceType = "Microsoft.ManagedIdentity/userAssignedIdentities"
+
+func init() {
+	registry.Register(&registry.Registration{
+		Name:     GenericResourceResource,
+		Scope:    azure.SubscriptionScope,
+		Resource: &GenericResource{},
+		Lister:   &GenericResourceLister{},
+		Settings: []string{
+			GenericResourceRemoveManagedSetting,
+		},
+	})
+}
+
+// dedicatedResourceTypes are the ARM resource types that have a dedicated registration, these and their child resource
+// types (i.e. virtual machine extensions) are never listed as generic resources so that nothing is removed twice.
+var dedicatedResourceTypes = map[string]string{
+	"microsoft.compute/disks":                    DiskResource,
+	"microsoft.compute/snapshots":                ComputeSnapshotResource,
+	"microsoft.compute/sshpublickeys":            SSHPublicKeyResource,
+	"microsoft.compute/virtualmachines":          VirtualMachineResource,
+	"microsoft.compute/virtualmachinescalesets":  VirtualMachineScaleSetResource,
+	"microsoft.containerregistry/registries":     ContainerRegistryResource,
+	"microsoft.containerservice/managedclusters": KubernetesClusterResource,
+	"microsoft.keyvault/vaults":                  KeyVaultResource,
+	"microsoft.network/applicationgateways":      ApplicationGatewayResource,
+	"microsoft.network/dnszones":                 DNSZoneResource,
+	"microsoft.network/ipallocations":            IPAllocationResource,
+	"microsoft.network/networkinterfaces":        NetworkInterfaceResource,
+	"microsoft.network/networksecuritygroups":    NetworkSecurityGroupResource,
+	"microsoft.network/privatednszones":          PrivateDNSZoneResource,
+	"microsoft.network/publicipaddresses":        PublicIPAddressesResource,
+	"microsoft.network/virtualnetworks":          VirtualNetworkResource,
+	"microsoft.recoveryservices/vaults":          RecoveryServicesVaultResource,
+	"microsoft.sql/servers/databases":            SQLDatabaseResource,
+	"microsoft.storage/storageaccounts":          StorageAccountResource,
+	"microsoft.web/serverfarms":                  AppServicePlanResource,
+}
+
+// dedicatedResourceType returns the name of the dedicated registration for the ARM resource type, or for the resource
+// type it is a child of, an empty string is returned if there is none
+func dedicatedResourceType(resourceType string) string {
+	resourceType = strings.ToLower(resourceType)
+	for armType, name := range dedicatedResourceTypes {
+		if resourceType == armType || strings.HasPrefix(resourceType, armType+"/") {
+			return name
+		}
+	}
+
+	return ""
+}
+
+type GenericResource struct {
+	*BaseResource `property:",inline"`
+
+	client      resourcesapi.Client
+	apiVersions *genericAPIVersions
+	settings    *libsettings.Setting
+
+	caller      *azure.Identity
+	principalID *string
+	clientID    *string
+
+	ID           *string `description:"The resource ID of the resource."`
+	Name         *string `description:"The name of the resource."`
+	ResourceType *string `description:"The ARM resource type of the resource (i.e. Microsoft.Web/sites)."`
+	Kind         *string `description:"The kind of the resource, if the resource type has kinds."`
+	Location     *string `description:"The location of the resource."`
+	ManagedBy    *string `description:"The ID of the resource that manages this resource, if any."`
+}
+
+func (r *GenericResource) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(30*time.Second))
+	defer cancel()
+
+	apiVersion, err := r.apiVersions.Get(ctx, ptr.ToString(r.ResourceType))
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.DeleteByID(ctx, ptr.ToString(r.ID), apiVersion)
+	return err
+}
+
+func (r *GenericResource) Filter() error {
+	if r.caller != nil && strings.EqualFold(ptr.ToString(r.ResourceType), userAssignedIdentityResourceType) {
+		if r.principalID == nil && r.clientID == nil {
+			return fmt.Errorf("cannot verify that the managed identity is not the identity running the nuke")
+		}
+
+		if r.caller.IsObject(r.principalID) || r.caller.IsApplication(r.clientID) {
+			return fmt.Errorf("cannot delete the managed identity of the identity running the nuke")
+		}
+	}
+
+	if ptr.ToString(r.ManagedBy) != "" &&
+		(r.settings == nil || !r.settings.GetBool(GenericResourceRemoveManagedSetting)) {
+		return fmt.Errorf("managed by %s", ptr.ToString(r.ManagedBy))
+	}
+
+	return r.BaseResource.Filter()
+}
+
+func (r *GenericResource) Settings(setting *libsettings.Setting) {
+	r.settings = setting
+}
+
+func (r *GenericResource) Properties() types.Properties {
+	return types.NewPropertiesFromStruct(r)
+}
+
+func (r *GenericResource) String() string {
+	return ptr.ToString(r.Name)
+}
+
+// resolveManagedIdentity fetches the principal and client ID of a user-assigned managed identity
+func (r *GenericResource) resolveManagedIdentity(ctx context.Context) error {
+	apiVersion, err := r.apiVersions.Get(ctx, ptr.ToString(r.ResourceType))
+	if err != nil {
+		return err
+	}
+
+	res, err := r.client.GetByID(ctx, ptr.ToString(r.ID), apiVersion)
+	if err != nil {
+		return err
+	}
+
+	properties, ok := res.Properties.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("the managed identity has no properties")
+	}
+
+	if principalID, ok := properties["principalId"].(string); ok {
+		r.principalID = ptr.String(principalID)
+	}
+
+	if clientID, ok := properties["clientId"].(string); ok {
+		r.clientID = ptr.String(clientID)
+	}
+
+	return nil
+}
+
+// -------------------
+
+type GenericResourceLister struct {
+}
+
+func (l GenericResourceLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
+	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
+
+	log := opts.Logger(GenericResourceResource)
+
+	client := resourcesapi.NewClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
+	client.Authorizer = opts.Authorizers.Management
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
+
+	providersClient := resourcesapi.NewProvidersClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
+	providersClient.Authorizer = opts.Authorizers.Management
+	opts.Authorizers.Pipeline.ConfigureAutorest(&providersClient.Client)
+
+	// Note: the api versions are shared by all resources of the subscription so every provider is only looked up once
+	apiVersions := &genericAPIVersions{
+		client:   providersClient,
+		versions: make(map[string]string),
+	}
+
+	resources := make([]resource.Resource, 0)
+
+	log.Trace("attempting to list resources")
+
+	list, err := client.ListComplete(ctx, "", "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for list.NotDone() {
+		r := list.Value()
+
+		if name := dedicatedResourceType(ptr.ToString(r.Type)); name != "" {
+			log.Tracef("skipping resource: %s (reason: listed by %s)", ptr.ToString(r.ID), name)
+
+			if err := list.NextWithContext(ctx); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		genericResource := &GenericResource{
+			BaseResource: &BaseResource{
+				Region:         r.Location,
+				SubscriptionID: ptr.String(opts.SubscriptionID),
+				ResourceGroup:  azure.GetResourceGroupFromID(ptr.ToString(r.ID)),
+				SystemData:     opts.SystemData(ctx, r.ID),
+				Tags:           r.Tags,
+			},
+			client:       client,
+			apiVersions:  apiVersions,
+			caller:       opts.Authorizers.Identity,
+			ID:           r.ID,
+			Name:         r.Name,
+			ResourceType: r.Type,
+			Kind:         r.Kind,
+			Location:     r.Location,
+			ManagedBy:    r.ManagedBy,
+		}
+
+		// Note: the listing does not include the properties of the resources, the principal and client ID of the
+		// managed identities are fetched so that the identity running the nuke is never removed
+		if genericResource.caller != nil &&
+			strings.EqualFold(ptr.ToString(r.Type), userAssignedIdentityResourceType) {
+			if err := genericResource.resolveManagedIdentity(ctx); err != nil {
+				log.WithError(err).Warnf("unable to fetch managed identity: %s", ptr.ToString(r.ID))
+			}
+		}
+
+		resources = append(resources, genericResource)
+
+		if err := list.NextWithContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Trace("done")
+
+	return resources, nil
+}
+
+// genericAPIVersions resolves the api version to use for a resource type from the metadata of its resource provider
+type genericAPIVersions struct {
+	client resourcesapi.ProvidersClient
+
+	lock     sync.Mutex
+	versions map[string]string
+}
+
+// Get returns the api version for the resource type (i.e. Microsoft.Web/sites), this is the default api version of
+// the resource type if the provider has one, otherwise the latest stable api version, otherwise the latest preview.
+func (v *genericAPIVersions) Get(ctx context.Context, resourceType string) (string, error) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	key := strings.ToLower(resourceType)
+	if version, ok := v.versions[key]; ok {
+		return version, nil
+	}
+
+	namespace, typeName, ok := strings.Cut(resourceType, "/")
+	if !ok {
+		return "", fmt.Errorf("invalid resource type: %s", resourceType)
+	}
+
+	provider, err := v.client.Get(ctx, namespace, "")
+	if err != nil {
+		return "", err
+	}
+
+	if provider.ResourceTypes != nil {
+		for _, rt := range *provider.ResourceTypes {
+			if !strings.EqualFold(ptr.ToString(rt.ResourceType), typeName) {
+				continue
+			}
+
+			version := ptr.ToString(rt.DefaultAPIVersion)
+			if version == "" && rt.APIVersions != nil {
+				version = latestAPIVersion(*rt.APIVersions)
+			}
+
+			if version != "" {
+				v.versions[key] = version
+				return version, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("unable to resolve api version for resource type: %s", resourceType)
+}
+
+// latestAPIVersion returns the latest stable api version, or the latest preview version if there is no stable one
+func latestAPIVersion(versions []string) string {
+	sorted := append([]string{}, versions...)
+	sort.Sort(sort.Reverse(sort.StringSlice(sorted)))
+
+	for _, version := range sorted {
+		if !strings.Contains(version, "preview") {
+			return version
+		}
+	}
+
+	if len(sorted) > 0 {
+		return sorted[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,125 @@
+package resources
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/registry"
+	libsettings "github.com/ekristen/libnuke/pkg/settings"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+// notTrackedResourceTypes are the registrations of resources that are not returned by the resources api (resource
+// groups, extension and proxy resources), so they cannot be listed twice
+var notTrackedResourceTypes = []string{
+	BudgetResource,
+	ManagementLockResource,
+	MonitorDiagnosticSettingResource,
+	PolicyAssignmentResource,
+	PolicyDefinitionResource,
+	RecoveryServicesBackupPolicyResource,
+	RecoveryServicesBackupProtectedItemResource,
+	RecoveryServicesBackupProtectionContainerResource,
+	RecoveryServicesBackupProtectionIntentResource,
+	ResourceGroupResource,
+	SecurityAlertResource,
+	SecurityAssessmentResource,
+	SecurityPricingResource,
+	SecurityWorkspaceResource,
+	SubscriptionRoleAssignmentResource,
+}
+
+func TestDedicatedResourceType(t *testing.T) {
+	cases := map[string]string{
+		"Microsoft.Compute/virtualMachines":                         VirtualMachineResource,
+		"microsoft.compute/virtualmachines":                         VirtualMachineResource,
+		"MICROSOFT.COMPUTE/VIRTUALMACHINES":                         VirtualMachineResource,
+		"Microsoft.Compute/virtualMachines/extensions":              VirtualMachineResource,
+		"Microsoft.Compute/virtualMachineScaleSets":                 VirtualMachineScaleSetResource,
+		"Microsoft.Compute/virtualMachineScaleSets/virtualMachines": VirtualMachineScaleSetResource,
+		"Microsoft.Sql/servers/databases":                           SQLDatabaseResource,
+		"Microsoft.Sql/servers":                                     "",
+		"Microsoft.Web/sites":                                       "",
+		"Microsoft.Compute/virtualMachinesSomething":                "",
+	}
+
+	for resourceType, expected := range cases {
+		assert.Equal(t, expected, dedicatedResourceType(resourceType), resourceType)
+	}
+}
+
+func TestDedicatedResourceTypesCoverRegistry(t *testing.T) {
+	dedicated := make(map[string]bool)
+	for _, name := range dedicatedResourceTypes {
+		assert.NotNil(t, registry.GetRegistration(name), "%s is not registered", name)
+		dedicated[name] = true
+	}
+
+	for _, name := range registry.GetNames() {
+		reg := registry.GetRegistration(name)
+		if reg.Scope != azure.SubscriptionScope && reg.Scope != azure.ResourceGroupScope {
+			continue
+		}
+
+		if name == GenericResourceResource {
+			continue
+		}
+
+		if slices.Contains(notTrackedResourceTypes, name) {
+			assert.False(t, dedicated[name], "%s is tracked", name)
+			continue
+		}
+
+		assert.True(t, dedicated[name],
+			"%s has to be added to dedicatedResourceTypes, otherwise it is also removed as a generic resource", name)
+	}
+}
+
+func TestLatestAPIVersion(t *testing.T) {
+	cases := map[string]struct {
+		versions []string
+		expected string
+	}{
+		"stable": {
+			versions: []string{"2021-04-01", "2023-01-01", "2022-09-01"},
+			expected: "2023-01-01",
+		},
+		"stable before newer preview": {
+			versions: []string{"2023-05-01-preview", "2022-09-01", "2023-01-01-preview"},
+			expected: "2022-09-01",
+		},
+		"only preview": {
+			versions: []string{"2022-01-01-preview", "2023-05-01-preview"},
+			expected: "2023-05-01-preview",
+		},
+		"none": {
+			versions: nil,
+			expected: "",
+		},
+	}
+
+	for name, tc := range cases {
+		assert.Equal(t, tc.expected, latestAPIVersion(tc.versions), name)
+	}
+}
+
+func TestGenericResourceManagedByIsFiltered(t *testing.T) {
+	managed := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/aks"
+
+	r := &GenericResource{ManagedBy: ptr.String(managed)}
+	err := r.Filter()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), managed)
+
+	r.Settings(&libsettings.Setting{})
+	assert.Error(t, r.Filter())
+
+	r.Settings(&libsettings.Setting{GenericResourceRemoveManagedSetting: true})
+	assert.NoError(t, r.Filter())
+
+	assert.NoError(t, (&GenericResource{}).Filter())
+}
@@ -2,9 +2,6 @@ package resources
 
 import (
 	"context"
-	"time"
-
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-05-01/network" //nolint:staticcheck
 
@@ -31,19 +28,30 @@ type IPAllocationLister struct {
 
 func (l IPAllocationLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
+
+	if opts.SkipByInventory("microsoft.network/ipallocations") {
+		return nil, nil
+	}
+
+	return opts.ListByResourceGroup(ctx, IPAllocationResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
+	})
+}
 
-	log := logrus.WithField("r", IPAllocationResource).WithField("s", opts.SubscriptionID)
+// listSubscription lists the ip allocations of every resource group of the subscription
+func (l IPAllocationLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
+	log := opts.Logger(IPAllocationResource)
 
-	client := network.NewIPAllocationsClient(opts.SubscriptionID)
+	client := network.NewIPAllocationsClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
 	client.Authorizer = opts.Authorizers.Management
-	client.RetryAttempts = 1
-	client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	resources := make([]resource.Resource, 0)
 
 	log.Trace("attempting to list virtual networks")
 
-	list, err := client.ListByResourceGroup(ctx, opts.ResourceGroup)
+	list, err := client.List(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -56,13 +64,15 @@ func (l IPAllocationLister) List(ctx context.Context, o interface{}) ([]resource
 			resources = append(resources, &IPAllocation{
 				BaseResource: &BaseResource{
 					Region:         g.Location,
-					ResourceGroup:  &opts.ResourceGroup,
+					ResourceGroup:  opts.ResourceGroupFromID(g.ID),
 					SubscriptionID: &opts.SubscriptionID,
+					SystemData:     opts.SystemData(ctx, g.ID),
+					Tags:           g.Tags,
 				},
 				client: client,
 
+				ID:   g.ID,
 				Name: g.Name,
-				Tags: g.Tags,
 			})
 		}
 
@@ -80,11 +90,15 @@ type IPAllocation struct {
 	*BaseResource `property:",inline"`
 
 	client network.IPAllocationsClient
+	ID     *string `property:"-"`
 	Name   *string
-	Tags   map[string]*string
 }
 
 func (r *IPAllocation) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Delete(ctx, *r.ResourceGroup, *r.Name)
 	return err
 }
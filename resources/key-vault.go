@@ -2,9 +2,6 @@ package resources
 
 import (
 	"context"
-	"time"
-
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/services/keyvault/mgmt/2019-09-01/keyvault" //nolint:staticcheck
 
@@ -31,13 +28,17 @@ type KeyVaultLister struct {
 
 func (l KeyVaultLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
+
+	if opts.SkipByInventory("microsoft.keyvault/vaults") {
+		return nil, nil
+	}
 
-	log := logrus.WithField("r", KeyVaultResource).WithField("s", opts.SubscriptionID)
+	log := opts.Logger(KeyVaultResource)
 
-	client := keyvault.NewVaultsClient(opts.SubscriptionID)
+	client := keyvault.NewVaultsClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
 	client.Authorizer = opts.Authorizers.Management
-	client.RetryAttempts = 1
-	client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	resources := make([]resource.Resource, 0)
 
@@ -58,10 +59,12 @@ func (l KeyVaultLister) List(ctx context.Context, o interface{}) ([]resource.Res
 					Region:         g.Location,
 					ResourceGroup:  azure.GetResourceGroupFromID(*g.ID),
 					SubscriptionID: &opts.SubscriptionID,
+					SystemData:     opts.SystemData(ctx, g.ID),
+					Tags:           g.Tags,
 				},
 				client: client,
+				ID:     g.ID,
 				Name:   g.Name,
-				Tags:   g.Tags,
 			})
 		}
 
@@ -79,11 +82,15 @@ type KeyVault struct {
 	*BaseResource `property:",inline"`
 
 	client keyvault.VaultsClient
+	ID     *string `property:"-"`
 	Name   *string
-	Tags   map[string]*string
 }
 
 func (r *KeyVault) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Delete(ctx, *r.ResourceGroup, *r.Name)
 
 	return err
@@ -0,0 +1,143 @@
+package resources
+
+import (
+	"context"
+
+	"github.com/gotidy/ptr"
+
+	"github.com/Azure/azure-sdk-for-go/services/containerservice/mgmt/2022-07-01/containerservice" //nolint:staticcheck
+
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+	"github.com/ekristen/libnuke/pkg/types"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+const KubernetesClusterResource = "KubernetesCluster"
+
+func init() {
+	registry.Register(&registry.Registration{
+		Name:     KubernetesClusterResource,
+		Scope:    azure.ResourceGroupScope,
+		Resource: &KubernetesCluster{},
+		Lister:   &KubernetesClusterLister{},
+	})
+}
+
+// KubernetesCluster is an Azure Kubernetes Service (AKS) managed cluster
+type KubernetesCluster struct {
+	*BaseResource `property:",inline"`
+
+	client     containerservice.ManagedClustersClient
+	ID         *string `property:"-"`
+	Name       *string
+	PowerState *string `description:"The power state of the cluster (Running or Stopped)."`
+}
+
+func (r *KubernetesCluster) Remove(ctx context.Context) error {
+	if r.StopMode() {
+		return r.Stop(ctx)
+	}
+
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
+	_, err := r.client.Delete(ctx, *r.ResourceGroup, *r.Name)
+	return err
+}
+
+func (r *KubernetesCluster) StopAction() string {
+	return "stop"
+}
+
+func (r *KubernetesCluster) Stopped() bool {
+	return ptr.ToString(r.PowerState) == string(containerservice.Stopped)
+}
+
+func (r *KubernetesCluster) Stop(ctx context.Context) error {
+	_, err := r.client.Stop(ctx, *r.ResourceGroup, *r.Name)
+	return err
+}
+
+func (r *KubernetesCluster) VolatileProperties() []string {
+	return []string{"PowerState"}
+}
+
+func (r *KubernetesCluster) Properties() types.Properties {
+	return types.NewPropertiesFromStruct(r)
+}
+
+func (r *KubernetesCluster) String() string {
+	return *r.Name
+}
+
+// -----------------------------------------
+
+type KubernetesClusterLister struct {
+}
+
+func (l KubernetesClusterLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
+	opts := o.(*azure.ListerOpts)
+
+	if opts.SkipByInventory("microsoft.containerservice/managedclusters") {
+		return nil, nil
+	}
+
+	return opts.ListByResourceGroup(ctx, KubernetesClusterResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
+	})
+}
+
+// listSubscription lists the kubernetes clusters of every resource group of the subscription
+func (l KubernetesClusterLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
+	log := opts.Logger(KubernetesClusterResource)
+
+	client := containerservice.NewManagedClustersClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
+	client.Authorizer = opts.Authorizers.Management
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
+
+	resources := make([]resource.Resource, 0)
+
+	log.Trace("attempting to list kubernetes clusters")
+
+	list, err := client.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Trace("listing resources")
+
+	for list.NotDone() {
+		log.Trace("list not done")
+		for _, g := range list.Values() {
+			var powerState *string
+			if g.ManagedClusterProperties != nil && g.PowerState != nil {
+				powerState = ptr.String(string(g.PowerState.Code))
+			}
+
+			resources = append(resources, &KubernetesCluster{
+				BaseResource: &BaseResource{
+					Region:         g.Location,
+					ResourceGroup:  opts.ResourceGroupFromID(g.ID),
+					SubscriptionID: &opts.SubscriptionID,
+					SystemData:     azure.NewSystemData(g.SystemData),
+					Tags:           g.Tags,
+				},
+				client:     client,
+				ID:         g.ID,
+				Name:       g.Name,
+				PowerState: powerState,
+			})
+		}
+
+		if err := list.NextWithContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Trace("done")
+
+	return resources, nil
+}
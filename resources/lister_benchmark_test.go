@@ -0,0 +1,133 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ekristen/libnuke/pkg/registry"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+const (
+	benchmarkSubscriptionID = "00000000-0000-0000-0000-000000000001"
+	benchmarkResourceGroups = 50
+	benchmarkResourcesPerRG = 4
+)
+
+// newListerBenchmarkServer returns a fake resource manager server whose subscription has benchmarkResourceGroups
+// resource groups with benchmarkResourcesPerRG resources of every type, the number of requests it served is counted
+func newListerBenchmarkServer() (*httptest.Server, *atomic.Int64) {
+	requests := &atomic.Int64{}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		// Note: a subscription wide listing returns the resources of every resource group, a listing of a resource
+		// group (or of the child resources of a resource in it) only returns the resources of the resource group
+		prefixes := []string{r.URL.Path}
+		if match := listerTestSubscriptionPath.FindStringSubmatch(r.URL.Path); match != nil {
+			prefixes = make([]string, 0, benchmarkResourceGroups)
+			for i := 0; i < benchmarkResourceGroups; i++ {
+				prefixes = append(prefixes, fmt.Sprintf("%s/resourceGroups/rg-%d%s", match[1], i, match[2]))
+			}
+		}
+
+		values := make([]string, 0, len(prefixes)*benchmarkResourcesPerRG)
+		for _, prefix := range prefixes {
+			for i := 0; i < benchmarkResourcesPerRG; i++ {
+				values = append(values, fmt.Sprintf(`{
+					"id":"%s/resource-%d",
+					"name":"resource-%d",
+					"location":"eastus",
+					"tags":{"owner":"test"},
+					"properties":{"timeCreated":"2024-01-02T03:04:05Z"}
+				}`, prefix, i, i))
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"value":[%s]}`, strings.Join(values, ","))
+	})), requests
+}
+
+// BenchmarkResourceGroupListers scans every resource group of the subscription, once with the listers listing the
+// subscription for every resource group and once with the subscription wide listings shared between the resource
+// groups, the requests to resource manager per scan are reported as requests/op. The default request rate of the
+// pipeline applies, so the time per scan follows the number of requests as it would against resource manager.
+func BenchmarkResourceGroupListers(b *testing.B) {
+	server, requests := newListerBenchmarkServer()
+	defer server.Close()
+
+	authorizers := newTestAuthorizers(b, server.URL)
+
+	resourceGroups := make([]string, 0, benchmarkResourceGroups)
+	for i := 0; i < benchmarkResourceGroups; i++ {
+		resourceGroups = append(resourceGroups, fmt.Sprintf("rg-%d", i))
+	}
+
+	resourceTypes := []string{
+		VirtualMachineResource,
+		DiskResource,
+		NetworkInterfaceResource,
+		StorageAccountResource,
+		RecoveryServicesBackupPolicyResource,
+	}
+
+	for _, resourceType := range resourceTypes {
+		reg := registry.GetRegistration(resourceType)
+
+		// Note: both modes have to find the same resources, the child resources (i.e. the backup policies of the
+		// vaults) are found for every resource of their parent
+		expected := 0
+
+		for _, shared := range []bool{false, true} {
+			mode := "per-resource-group"
+			if shared {
+				mode = "subscription-wide"
+			}
+
+			b.Run(fmt.Sprintf("%s/%s", resourceType, mode), func(b *testing.B) {
+				ctx := context.Background()
+				requests.Store(0)
+
+				for n := 0; n < b.N; n++ {
+					var listings *azure.Listings
+					if shared {
+						listings = azure.NewListings(map[string][]string{benchmarkSubscriptionID: resourceGroups})
+					}
+
+					found := 0
+					for _, rg := range resourceGroups {
+						resources, err := reg.Lister.List(ctx, &azure.ListerOpts{
+							Authorizers:    authorizers,
+							SubscriptionID: benchmarkSubscriptionID,
+							ResourceGroup:  rg,
+							Listings:       listings,
+						})
+						if err != nil {
+							b.Fatal(err)
+						}
+
+						found += len(resources)
+					}
+
+					if expected == 0 {
+						expected = found
+					}
+
+					if found == 0 || found != expected {
+						b.Fatalf("found %d resources, expected %d", found, expected)
+					}
+				}
+
+				b.ReportMetric(float64(requests.Load())/float64(b.N), "requests/op")
+			})
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package resources
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+)
+
+// listerTestResponse is returned for every resource manager request, it is a page with a single resource that
+// has the fields every lister reads, the ID of the resource is the path of the request
+const listerTestResponse = `{"value":[{
+	"id":"%s/test",
+	"name":"test",
+	"type":"Microsoft.Test/tests",
+	"location":"eastus",
+	"tags":{"owner":"test"},
+	"properties":{
+		"scope":"/subscriptions/00000000-0000-0000-0000-000000000001",
+		"roleDefinitionId":"/providers/Microsoft.Authorization/roleDefinitions/test",
+		"principalId":"00000000-0000-0000-0000-000000000002",
+		"level":"CanNotDelete",
+		"pricingTier":"Standard",
+		"timeCreated":"2024-01-02T03:04:05Z",
+		"resourceDetails":{"source":"Azure"}
+	},
+	"systemData":{
+		"createdAt":"2024-01-02T03:04:05.123Z",
+		"createdBy":"creator@example.com",
+		"createdByType":"User",
+		"lastModifiedAt":"2024-02-03T04:05:06Z",
+		"lastModifiedBy":"00000000-0000-0000-0000-000000000003"
+	}
+}]}`
+
+// listerTestGraphResponse is returned for every microsoft graph request
+const listerTestGraphResponse = `{"value":[{
+	"id":"00000000-0000-0000-0000-000000000004",
+	"appId":"00000000-0000-0000-0000-000000000005",
+	"displayName":"test",
+	"createdDateTime":"2024-01-02T03:04:05Z"
+}]}`
+
+// newListerTestServer returns a fake resource manager and microsoft graph server that returns a single resource for
+// every list request, so that every lister can be run against it
+func newListerTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/v1.0/") || strings.HasPrefix(r.URL.Path, "/beta/"):
+			_, _ = w.Write([]byte(listerTestGraphResponse))
+		case strings.Contains(r.URL.Path, "/roleDefinitions/"):
+			_, _ = fmt.Fprintf(w, `{"id":%q,"properties":{"roleName":"Reader"}}`, r.URL.Path)
+		case strings.HasSuffix(r.URL.Path, "/Microsoft.Security/alerts"):
+			_, _ = fmt.Fprintf(w, listerTestResponse,
+				strings.Replace(r.URL.Path, "/alerts", "/locations/eastus/alerts", 1))
+		default:
+			_, _ = fmt.Fprintf(w, listerTestResponse, listerTestID(r.URL.Path))
+		}
+	}))
+}
+
+// listerTestSubscriptionPath matches the subscription wide listings of the resource providers whose resources are
+// in resource groups
+var listerTestSubscriptionPath = regexp.MustCompile(
+	`^(/subscriptions/[^/]+)(/providers/Microsoft\.(Compute|ContainerRegistry|ContainerService|KeyVault|Network|` +
+		`RecoveryServices|Sql|Storage|Web)/.*)$`)
+
+// listerTestID returns the path the ID of the resource of the listing is built from, resources of a subscription
+// wide listing are put in the test-rg resource group as the listers attribute them to their resource group by their ID
+func listerTestID(path string) string {
+	return listerTestSubscriptionPath.ReplaceAllString(path, "${1}/resourceGroups/test-rg${2}")
+}
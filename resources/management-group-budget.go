@@ -0,0 +1,110 @@
+package resources
+
+import (
+	"context"
+	"time"
+
+	"github.com/gotidy/ptr"
+
+	"github.com/hashicorp/go-azure-helpers/resourcemanager/commonids"
+	"github.com/hashicorp/go-azure-sdk/resource-manager/consumption/2021-10-01/budgets"
+
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+	"github.com/ekristen/libnuke/pkg/types"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+const ManagementGroupBudgetResource = "ManagementGroupBudget"
+
+func init() {
+	registry.Register(&registry.Registration{
+		Name:     ManagementGroupBudgetResource,
+		Scope:    azure.ManagementGroupScope,
+		Resource: &ManagementGroupBudget{},
+		Lister:   &ManagementGroupBudgetLister{},
+	})
+}
+
+type ManagementGroupBudget struct {
+	*BaseResource `property:",inline"`
+
+	client          *budgets.BudgetsClient
+	ID              *string
+	Name            *string
+	ManagementGroup string
+	scope           string
+}
+
+type ManagementGroupBudgetLister struct{}
+
+func (l ManagementGroupBudgetLister) List(pctx context.Context, o interface{}) ([]resource.Resource, error) {
+	opts := o.(*azure.ListerOpts)
+	pctx = azure.WithSystemData(pctx)
+	var resources []resource.Resource
+
+	log := opts.Logger(ManagementGroupBudgetResource)
+
+	client, err := budgets.NewBudgetsClientWithBaseURI(opts.Authorizers.Environment.ResourceManager)
+	if err != nil {
+		return nil, err
+	}
+	client.Client.Authorizer = opts.Authorizers.Management
+	opts.Authorizers.Pipeline.ConfigureResourceManager(client.Client)
+
+	log.Trace("attempting to list budgets for management group")
+
+	ctx, cancel := context.WithDeadline(pctx, time.Now().Add(10*time.Second))
+	defer cancel()
+
+	list, err := client.List(ctx, commonids.ScopeId{
+		Scope: opts.ManagementGroupScopeID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Trace("listing budgets for management group")
+
+	if list.Model == nil {
+		return resources, nil
+	}
+
+	for _, entry := range *list.Model {
+		resources = append(resources, &ManagementGroupBudget{
+			BaseResource: &BaseResource{
+				Region:     ptr.String("global"),
+				SystemData: opts.SystemData(ctx, entry.Id),
+			},
+			client:          client,
+			ID:              entry.Id,
+			Name:            entry.Name,
+			ManagementGroup: opts.ManagementGroupID,
+			scope:           opts.ManagementGroupScopeID(),
+		})
+	}
+
+	log.Trace("done")
+
+	return resources, nil
+}
+
+func (r *ManagementGroupBudget) Remove(ctx context.Context) error {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(10*time.Second))
+	defer cancel()
+
+	_, err := r.client.Delete(ctx, budgets.ScopedBudgetId{
+		Scope:      r.scope,
+		BudgetName: *r.Name,
+	})
+	return err
+}
+
+func (r *ManagementGroupBudget) Properties() types.Properties {
+	return types.NewPropertiesFromStruct(r)
+}
+
+func (r *ManagementGroupBudget) String() string {
+	return *r.Name
+}
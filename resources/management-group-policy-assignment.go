@@ -0,0 +1,111 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gotidy/ptr"
+
+	"github.com/Azure/azure-sdk-for-go/services/preview/resources/mgmt/2021-06-01-preview/policy" //nolint:staticcheck
+
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+	"github.com/ekristen/libnuke/pkg/types"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+const ManagementGroupPolicyAssignmentResource = "ManagementGroupPolicyAssignment"
+
+func init() {
+	registry.Register(&registry.Registration{
+		Name:     ManagementGroupPolicyAssignmentResource,
+		Scope:    azure.ManagementGroupScope,
+		Resource: &ManagementGroupPolicyAssignment{},
+		Lister:   &ManagementGroupPolicyAssignmentLister{},
+	})
+}
+
+type ManagementGroupPolicyAssignment struct {
+	*BaseResource `property:",inline"`
+
+	client          policy.AssignmentsClient
+	Name            string
+	Scope           string
+	EnforcementMode string
+	ManagementGroup string
+	mgScope         string
+}
+
+func (r *ManagementGroupPolicyAssignment) Remove(ctx context.Context) error {
+	_, err := r.client.Delete(ctx, r.Scope, r.Name)
+	return err
+}
+
+func (r *ManagementGroupPolicyAssignment) Filter() error {
+	if !strings.EqualFold(r.Scope, r.mgScope) {
+		return fmt.Errorf("policy assigned at a different level than the management group")
+	}
+
+	return r.BaseResource.Filter()
+}
+
+func (r *ManagementGroupPolicyAssignment) Properties() types.Properties {
+	return types.NewPropertiesFromStruct(r)
+}
+
+func (r *ManagementGroupPolicyAssignment) String() string {
+	return r.Name
+}
+
+type ManagementGroupPolicyAssignmentLister struct {
+}
+
+func (l ManagementGroupPolicyAssignmentLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
+	opts := o.(*azure.ListerOpts)
+
+	log := opts.Logger(ManagementGroupPolicyAssignmentResource)
+
+	client := policy.NewAssignmentsClientWithBaseURI(opts.ResourceManagerEndpoint(), "")
+	client.Authorizer = opts.Authorizers.Management
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
+
+	resources := make([]resource.Resource, 0)
+
+	log.Trace("attempting to list policy assignments")
+
+	// Note: atScope() is required by the API, it returns the assignments at the management group and above
+	list, err := client.ListForManagementGroup(ctx, opts.ManagementGroupID, "atScope()", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Trace("listing policy assignments")
+
+	for list.NotDone() {
+		log.Trace("list not done")
+		for _, g := range list.Values() {
+			resources = append(resources, &ManagementGroupPolicyAssignment{
+				BaseResource: &BaseResource{
+					Region:     ptr.String("global"),
+					SystemData: azure.NewSystemData(g.SystemData),
+				},
+				client:          client,
+				Name:            ptr.ToString(g.Name),
+				Scope:           ptr.ToString(g.Scope),
+				EnforcementMode: string(g.EnforcementMode),
+				ManagementGroup: opts.ManagementGroupID,
+				mgScope:         opts.ManagementGroupScopeID(),
+			})
+		}
+
+		if err := list.NextWithContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Trace("done")
+
+	return resources, nil
+}
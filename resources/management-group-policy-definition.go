@@ -0,0 +1,112 @@
+package resources
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gotidy/ptr"
+
+	"github.com/Azure/azure-sdk-for-go/services/preview/resources/mgmt/2021-06-01-preview/policy" //nolint:staticcheck
+
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+	"github.com/ekristen/libnuke/pkg/types"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+const ManagementGroupPolicyDefinitionResource = "ManagementGroupPolicyDefinition"
+
+func init() {
+	registry.Register(&registry.Registration{
+		Name:     ManagementGroupPolicyDefinitionResource,
+		Scope:    azure.ManagementGroupScope,
+		Resource: &ManagementGroupPolicyDefinition{},
+		Lister:   &ManagementGroupPolicyDefinitionLister{},
+	})
+}
+
+type ManagementGroupPolicyDefinition struct {
+	*BaseResource `property:",inline"`
+
+	client          policy.DefinitionsClient
+	Name            *string
+	DisplayName     string
+	PolicyType      string `property:"name=Type"`
+	ManagementGroup string
+}
+
+func (r *ManagementGroupPolicyDefinition) Remove(ctx context.Context) error {
+	_, err := r.client.DeleteAtManagementGroup(ctx, *r.Name, r.ManagementGroup)
+	return err
+}
+
+func (r *ManagementGroupPolicyDefinition) Properties() types.Properties {
+	return types.NewPropertiesFromStruct(r)
+}
+
+func (r *ManagementGroupPolicyDefinition) String() string {
+	return *r.Name
+}
+
+type ManagementGroupPolicyDefinitionLister struct {
+}
+
+func (l ManagementGroupPolicyDefinitionLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
+	opts := o.(*azure.ListerOpts)
+
+	log := opts.Logger(ManagementGroupPolicyDefinitionResource)
+
+	client := policy.NewDefinitionsClientWithBaseURI(opts.ResourceManagerEndpoint(), "")
+	client.Authorizer = opts.Authorizers.Management
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
+
+	resources := make([]resource.Resource, 0)
+
+	log.Trace("attempting to list policy definitions")
+
+	list, err := client.ListByManagementGroup(ctx, opts.ManagementGroupID, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Trace("listing policy definitions")
+
+	prefix := strings.ToLower(opts.ManagementGroupScopeID() + "/")
+
+	for list.NotDone() {
+		log.Trace("list not done")
+		for _, g := range list.Values() {
+			// Same as the subscription level, BuiltIn and Static definitions are filtered out optimistically here.
+			if g.PolicyType == "BuiltIn" || g.PolicyType == "Static" {
+				continue
+			}
+
+			// The list also includes the definitions inherited from the parent management groups, those are
+			// handled by the scanner of the management group they are defined on.
+			if !strings.HasPrefix(strings.ToLower(ptr.ToString(g.ID)), prefix) {
+				continue
+			}
+
+			resources = append(resources, &ManagementGroupPolicyDefinition{
+				BaseResource: &BaseResource{
+					Region:     ptr.String("global"),
+					SystemData: azure.NewSystemData(g.SystemData),
+				},
+				client:          client,
+				Name:            g.Name,
+				DisplayName:     ptr.ToString(g.DisplayName),
+				PolicyType:      string(g.PolicyType),
+				ManagementGroup: opts.ManagementGroupID,
+			})
+		}
+
+		if err := list.NextWithContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	log.WithField("total", len(resources)).Trace("done")
+
+	return resources, nil
+}
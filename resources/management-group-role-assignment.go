@@ -0,0 +1,137 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gotidy/ptr"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization"
+
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+	"github.com/ekristen/libnuke/pkg/types"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+const ManagementGroupRoleAssignmentResource = "ManagementGroupRoleAssignment"
+
+func init() {
+	registry.Register(&registry.Registration{
+		Name:     ManagementGroupRoleAssignmentResource,
+		Scope:    azure.ManagementGroupScope,
+		Resource: &ManagementGroupRoleAssignment{},
+		Lister: &ManagementGroupRoleAssignmentLister{
+			roleNameCache: make(map[string]*string),
+		},
+	})
+}
+
+type ManagementGroupRoleAssignment struct {
+	*BaseResource `property:",inline"`
+
+	client *armauthorization.RoleAssignmentsClient
+
+	ID               *string `property:"-"`
+	Name             *string
+	RoleName         *string
+	RoleDefinitionID *string
+	PrincipalID      *string
+	ManagementGroup  string
+	scope            *string
+	mgScope          string
+	caller           *azure.Identity
+}
+
+func (r *ManagementGroupRoleAssignment) Remove(ctx context.Context) error {
+	_, err := r.client.Delete(ctx, *r.scope, *r.Name, nil)
+	return err
+}
+
+func (r *ManagementGroupRoleAssignment) Filter() error {
+	if r.caller.IsObject(r.PrincipalID) {
+		return fmt.Errorf("cannot delete role assignments of the identity running the nuke")
+	}
+
+	if !strings.EqualFold(ptr.ToString(r.scope), r.mgScope) {
+		return fmt.Errorf("role assigned at a different level than the management group")
+	}
+
+	return r.BaseResource.Filter()
+}
+
+func (r *ManagementGroupRoleAssignment) Properties() types.Properties {
+	return types.NewPropertiesFromStruct(r)
+}
+
+func (r *ManagementGroupRoleAssignment) String() string {
+	return fmt.Sprintf("%s -> %s", ptr.ToString(r.PrincipalID), ptr.ToString(r.RoleName))
+}
+
+type ManagementGroupRoleAssignmentLister struct {
+	roleNameCache map[string]*string
+}
+
+func (l *ManagementGroupRoleAssignmentLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
+	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
+	var resources []resource.Resource
+
+	log := opts.Logger(ManagementGroupRoleAssignmentResource)
+
+	client, err := armauthorization.NewRoleAssignmentsClient("", opts.Authorizers.IdentityCreds, opts.ARMClientOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	defClient, err := armauthorization.NewRoleDefinitionsClient(opts.Authorizers.IdentityCreds, opts.ARMClientOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("listing management group role assignments")
+	pager := client.NewListForScopePager(opts.ManagementGroupScopeID(),
+		&armauthorization.RoleAssignmentsClientListForScopeOptions{Filter: ptr.String("atScope()")})
+
+	for pager.More() {
+		nextResult, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range nextResult.Value {
+			roleDefinitionID := ptr.ToString(t.Properties.RoleDefinitionID)
+			if _, ok := l.roleNameCache[roleDefinitionID]; !ok {
+				rel, defErr := defClient.GetByID(ctx, roleDefinitionID, nil)
+				if defErr != nil {
+					return nil, defErr
+				}
+
+				l.roleNameCache[roleDefinitionID] = rel.RoleDefinition.Properties.RoleName
+			}
+
+			roleDefinitionIDParts := strings.Split(roleDefinitionID, "/")
+
+			resources = append(resources, &ManagementGroupRoleAssignment{
+				BaseResource: &BaseResource{
+					Region:     ptr.String("global"),
+					SystemData: opts.SystemData(ctx, t.ID),
+				},
+				client:           client,
+				ID:               t.ID,
+				Name:             t.Name,
+				RoleName:         l.roleNameCache[roleDefinitionID],
+				RoleDefinitionID: ptr.String(roleDefinitionIDParts[len(roleDefinitionIDParts)-1]),
+				PrincipalID:      t.Properties.PrincipalID,
+				ManagementGroup:  opts.ManagementGroupID,
+				scope:            t.Properties.Scope,
+				mgScope:          opts.ManagementGroupScopeID(),
+				caller:           opts.Authorizers.Identity,
+			})
+		}
+	}
+
+	return resources, nil
+}
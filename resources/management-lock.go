@@ -9,7 +9,6 @@ import (
 
 	"github.com/hashicorp/go-azure-helpers/resourcemanager/commonids"
 	"github.com/hashicorp/go-azure-sdk/resource-manager/resources/2020-05-01/managementlocks"
-	"github.com/hashicorp/go-azure-sdk/sdk/environments"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
@@ -23,7 +22,7 @@ const ManagementLockResource = "ManagementLock"
 func init() {
 	registry.Register(&registry.Registration{
 		Name:     ManagementLockResource,
-		Scope:    azure.ResourceGroupScope,
+		Scope:    azure.SubscriptionScope,
 		Resource: &ManagementLock{},
 		Lister:   &ManagementLockLister{},
 	})
@@ -34,10 +33,11 @@ type ManagementLock struct {
 
 	client    *managementlocks.ManagementLocksClient
 	ID        *string `property:"-"`
-	Scope     string  `property:"-"`
-	Name      *string
-	LockLevel string
+	Scope     string  `description:"The ID of the subscription, resource group or resource the lock is applied to."`
+	Name      *string `description:"The name of the lock."`
+	LockLevel string  `description:"The level of the lock, either CanNotDelete or ReadOnly."`
 
+	locks        *azure.Locks
 	scopedLockID *managementlocks.ScopedLockId
 }
 
@@ -45,9 +45,14 @@ func (r *ManagementLock) Remove(ctx context.Context) error {
 	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(30*time.Second))
 	defer cancel()
 
-	_, err := r.client.DeleteByScope(ctx, *r.scopedLockID)
+	if _, err := r.client.DeleteByScope(ctx, *r.scopedLockID); err != nil {
+		r.locks.Failed(*r.ID)
+		return err
+	}
+
+	r.locks.Removed(*r.ID)
 
-	return err
+	return nil
 }
 
 func (r *ManagementLock) Properties() types.Properties {
@@ -66,21 +71,24 @@ func (l ManagementLockLister) List(ctx context.Context, o interface{}) ([]resour
 	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(30*time.Second))
 	defer cancel()
 
-	log := logrus.WithField("r", ManagementLockResource).WithField("s", opts.SubscriptionID)
+	log := opts.Logger(ManagementLockResource)
 
 	resources := make([]resource.Resource, 0)
 
-	client, err := managementlocks.NewManagementLocksClientWithBaseURI(environments.AzurePublic().ResourceManager)
+	client, err := managementlocks.NewManagementLocksClientWithBaseURI(opts.Authorizers.Environment.ResourceManager)
 	if err != nil {
 		return resources, err
 	}
 	client.Client.Authorizer = opts.Authorizers.Management
+	opts.Authorizers.Pipeline.ConfigureResourceManager(client.Client)
 
 	log.Trace("attempting to list resources")
 
-	list, err := client.ListAtResourceGroupLevelComplete(ctx,
-		commonids.NewResourceGroupID(opts.SubscriptionID, opts.ResourceGroup),
-		managementlocks.ListAtResourceGroupLevelOperationOptions{})
+	// Note: the locks at the subscription level include the locks on the resource groups and resources, so the
+	// locks inherited by a resource are all known before anything is removed
+	list, err := client.ListAtSubscriptionLevelComplete(ctx,
+		commonids.NewSubscriptionID(opts.SubscriptionID),
+		managementlocks.ListAtSubscriptionLevelOperationOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -95,12 +103,15 @@ func (l ManagementLockLister) List(ctx context.Context, o interface{}) ([]resour
 		resources = append(resources, &ManagementLock{
 			BaseResource: &BaseResource{
 				Region:         ptr.String("global"),
-				ResourceGroup:  &opts.ResourceGroup,
+				ResourceGroup:  azure.GetResourceGroupFromID(*lock.Id),
 				SubscriptionID: &opts.SubscriptionID,
+				SystemData:     azure.NewSystemData(lock.SystemData),
 			},
 			client:       client,
+			locks:        opts.Locks,
 			scopedLockID: scopedLockID,
 			ID:           lock.Id,
+			Scope:        azure.LockScope(*lock.Id),
 			Name:         lock.Name,
 			LockLevel:    string(lock.Properties.Level),
 		})
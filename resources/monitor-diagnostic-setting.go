@@ -5,11 +5,9 @@ import (
 	"strings"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/go-azure-helpers/resourcemanager/commonids"
 	"github.com/hashicorp/go-azure-sdk/resource-manager/insights/2021-05-01-preview/diagnosticsettings"
-	"github.com/hashicorp/go-azure-sdk/sdk/environments"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
@@ -38,6 +36,10 @@ type MonitorDiagnosticSetting struct {
 }
 
 func (r *MonitorDiagnosticSetting) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	// Note: we do this because the regex on the ParsedScope is case-sensitive, and the ID returned from
 	// the API is not always consistent with the casing.
 	cleanedID := strings.ReplaceAll(*r.id, "microsoft.insights", "Microsoft.Insights")
@@ -68,11 +70,11 @@ type MonitorDiagnosticSettingLister struct {
 func (l MonitorDiagnosticSettingLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
 
-	log := logrus.WithField("r", MonitorDiagnosticSettingResource).WithField("s", opts.SubscriptionID)
+	log := opts.Logger(MonitorDiagnosticSettingResource)
 
-	endpoint, _ := environments.AzurePublic().ResourceManager.Endpoint()
-	client := diagnosticsettings.NewDiagnosticSettingsClientWithBaseURI(*endpoint)
+	client := diagnosticsettings.NewDiagnosticSettingsClientWithBaseURI(opts.ResourceManagerEndpoint())
 	client.Client.Authorizer = opts.Authorizers.Management
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	resources := make([]resource.Resource, 0)
 
@@ -89,6 +91,7 @@ func (l MonitorDiagnosticSettingLister) List(ctx context.Context, o interface{})
 			BaseResource: &BaseResource{
 				Region:         ptr.String("global"),
 				SubscriptionID: &opts.SubscriptionID,
+				SystemData:     azure.NewSystemData(ds.SystemData),
 			},
 			client: client,
 			id:     ds.Id,
@@ -4,11 +4,8 @@ import (
 	"context"
 	"time"
 
-	"github.com/sirupsen/logrus"
-
 	"github.com/hashicorp/go-azure-helpers/resourcemanager/commonids"
 	"github.com/hashicorp/go-azure-sdk/resource-manager/network/2023-09-01/networkinterfaces"
-	"github.com/hashicorp/go-azure-sdk/sdk/environments"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
@@ -33,23 +30,36 @@ type NetworkInterfaceLister struct {
 
 func (l NetworkInterfaceLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
+
+	if opts.SkipByInventory("microsoft.network/networkinterfaces") {
+		return nil, nil
+	}
+
+	return opts.ListByResourceGroup(ctx, NetworkInterfaceResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
+	})
+}
 
+// listSubscription lists the network interfaces of every resource group of the subscription
+func (l NetworkInterfaceLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
 	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(30*time.Second))
 	defer cancel()
 
-	log := logrus.WithField("r", NetworkInterfaceResource).WithField("s", opts.SubscriptionID)
+	log := opts.Logger(NetworkInterfaceResource)
 
 	resources := make([]resource.Resource, 0)
 
-	client, err := networkinterfaces.NewNetworkInterfacesClientWithBaseURI(environments.AzurePublic().ResourceManager)
+	client, err := networkinterfaces.NewNetworkInterfacesClientWithBaseURI(opts.Authorizers.Environment.ResourceManager)
 	if err != nil {
 		return resources, err
 	}
 	client.Client.Authorizer = opts.Authorizers.Management
+	opts.Authorizers.Pipeline.ConfigureResourceManager(client.Client)
 
 	log.Trace("attempting to list network interfaces")
 
-	list, err := client.ListComplete(ctx, commonids.NewResourceGroupID(opts.SubscriptionID, opts.ResourceGroup))
+	list, err := client.ListAllComplete(ctx, commonids.NewSubscriptionID(opts.SubscriptionID))
 	if err != nil {
 		return nil, err
 	}
@@ -60,12 +70,14 @@ func (l NetworkInterfaceLister) List(ctx context.Context, o interface{}) ([]reso
 		resources = append(resources, &NetworkInterface{
 			BaseResource: &BaseResource{
 				Region:         g.Location,
-				ResourceGroup:  &opts.ResourceGroup,
+				ResourceGroup:  opts.ResourceGroupFromID(g.Id),
 				SubscriptionID: &opts.SubscriptionID,
+				SystemData:     opts.SystemData(ctx, g.Id),
+				Tags:           azure.Tags(g.Tags),
 			},
 			client: client,
+			ID:     g.Id,
 			Name:   g.Name,
-			Tags:   g.Tags,
 		})
 	}
 
@@ -78,11 +90,15 @@ type NetworkInterface struct {
 	*BaseResource `property:",inline"`
 
 	client *networkinterfaces.NetworkInterfacesClient
+	ID     *string `property:"-"`
 	Name   *string
-	Tags   *map[string]string
 }
 
 func (r *NetworkInterface) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(30*time.Second))
 	defer cancel()
 
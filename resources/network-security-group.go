@@ -2,9 +2,6 @@ package resources
 
 import (
 	"context"
-	"time"
-
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2022-05-01/network" //nolint:staticcheck
 
@@ -30,11 +27,15 @@ type NetworkSecurityGroup struct {
 	*BaseResource `property:",inline"`
 
 	client network.SecurityGroupsClient
+	ID     *string `property:"-"`
 	Name   *string
-	Tags   map[string]*string
 }
 
 func (r *NetworkSecurityGroup) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Delete(ctx, *r.ResourceGroup, *r.Name)
 	return err
 }
@@ -52,19 +53,30 @@ type NetworkSecurityGroupLister struct {
 
 func (l NetworkSecurityGroupLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
+
+	if opts.SkipByInventory("microsoft.network/networksecuritygroups") {
+		return nil, nil
+	}
+
+	return opts.ListByResourceGroup(ctx, NetworkSecurityGroupResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
+	})
+}
 
-	log := logrus.WithField("r", NetworkSecurityGroupResource).WithField("s", opts.SubscriptionID)
+// listSubscription lists the network security groups of every resource group of the subscription
+func (l NetworkSecurityGroupLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
+	log := opts.Logger(NetworkSecurityGroupResource)
 
-	client := network.NewSecurityGroupsClient(opts.SubscriptionID)
+	client := network.NewSecurityGroupsClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
 	client.Authorizer = opts.Authorizers.Management
-	client.RetryAttempts = 1
-	client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	resources := make([]resource.Resource, 0)
 
 	log.Trace("attempting to list groups")
 
-	list, err := client.List(ctx, opts.ResourceGroup)
+	list, err := client.ListAll(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -77,11 +89,13 @@ func (l NetworkSecurityGroupLister) List(ctx context.Context, o interface{}) ([]
 			resources = append(resources, &NetworkSecurityGroup{
 				BaseResource: &BaseResource{
 					Region:        g.Location,
-					ResourceGroup: &opts.ResourceGroup,
+					ResourceGroup: opts.ResourceGroupFromID(g.ID),
+					SystemData:    opts.SystemData(ctx, g.ID),
+					Tags:          g.Tags,
 				},
 				client: client,
+				ID:     g.ID,
 				Name:   g.Name,
-				Tags:   g.Tags,
 			})
 		}
 
@@ -0,0 +1,216 @@
+package resources
+
+import (
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+// Note: the permissions are only used by the preflight check, they are kept in one place so that they can be reviewed
+// together. Every resource type must have an entry, this is enforced by a test.
+
+// applicationGraphPermissions are shared by the app registrations, their credentials and the service principals
+var applicationGraphPermissions = azure.Permissions{
+	GraphList: []string{
+		"Application.Read.All", "Application.ReadWrite.All", "Application.ReadWrite.OwnedBy",
+		"Directory.Read.All", "Directory.ReadWrite.All",
+	},
+	GraphDelete: []string{"Application.ReadWrite.All", "Application.ReadWrite.OwnedBy"},
+}
+
+// graphPermissions are the microsoft graph permissions of the tenant scoped resource types, any one of the listed
+// permissions is sufficient
+var graphPermissions = map[string]azure.Permissions{
+	ApplicationCertificateResource:         applicationGraphPermissions,
+	ApplicationFederatedCredentialResource: applicationGraphPermissions,
+	ApplicationResource:                    applicationGraphPermissions,
+	ApplicationSecretResource:              applicationGraphPermissions,
+	AzureAdGroupResource: {
+		GraphList:   []string{"Group.Read.All", "Group.ReadWrite.All", "Directory.Read.All", "Directory.ReadWrite.All"},
+		GraphDelete: []string{"Group.ReadWrite.All", "Directory.ReadWrite.All"},
+	},
+	AzureADUserResource: {
+		GraphList:   []string{"User.Read.All", "User.ReadWrite.All", "Directory.Read.All", "Directory.ReadWrite.All"},
+		GraphDelete: []string{"User.ReadWrite.All", "Directory.ReadWrite.All"},
+	},
+	ServicePrincipalResource: applicationGraphPermissions,
+}
+
+// rbacPermissions are the azure rbac actions of all other resource types, all of the listed actions are required.
+// GenericResource can remove any resource type, so it requires a role that can delete everything (i.e. Contributor).
+var rbacPermissions = map[string]azure.Permissions{
+	ApplicationGatewayResource: {
+		List:   []string{"Microsoft.Network/applicationGateways/read"},
+		Delete: []string{"Microsoft.Network/applicationGateways/delete"},
+	},
+	AppServicePlanResource: {
+		List:   []string{"Microsoft.Web/serverfarms/read"},
+		Delete: []string{"Microsoft.Web/serverfarms/delete"},
+	},
+	BudgetResource: {
+		List:   []string{"Microsoft.Consumption/budgets/read"},
+		Delete: []string{"Microsoft.Consumption/budgets/delete"},
+	},
+	ComputeSnapshotResource: {
+		List:   []string{"Microsoft.Compute/snapshots/read"},
+		Delete: []string{"Microsoft.Compute/snapshots/delete"},
+	},
+	ContainerRegistryResource: {
+		List:   []string{"Microsoft.ContainerRegistry/registries/read"},
+		Delete: []string{"Microsoft.ContainerRegistry/registries/delete"},
+	},
+	DiskResource: {
+		List:   []string{"Microsoft.Compute/disks/read"},
+		Delete: []string{"Microsoft.Compute/disks/delete"},
+	},
+	DNSZoneResource: {
+		List:   []string{"Microsoft.Network/dnsZones/read"},
+		Delete: []string{"Microsoft.Network/dnsZones/delete"},
+	},
+	GenericResourceResource: {
+		List:   []string{"Microsoft.Resources/subscriptions/resources/read", "Microsoft.Resources/providers/read"},
+		Delete: []string{"*/delete"},
+	},
+	IPAllocationResource: {
+		List:   []string{"Microsoft.Network/IpAllocations/read"},
+		Delete: []string{"Microsoft.Network/IpAllocations/delete"},
+	},
+	KeyVaultResource: {
+		List:   []string{"Microsoft.KeyVault/vaults/read"},
+		Delete: []string{"Microsoft.KeyVault/vaults/delete"},
+	},
+	KubernetesClusterResource: {
+		List:   []string{"Microsoft.ContainerService/managedClusters/read"},
+		Delete: []string{"Microsoft.ContainerService/managedClusters/delete"},
+	},
+	ManagementGroupBudgetResource: {
+		List:   []string{"Microsoft.Consumption/budgets/read"},
+		Delete: []string{"Microsoft.Consumption/budgets/delete"},
+	},
+	ManagementGroupPolicyAssignmentResource: {
+		List:   []string{"Microsoft.Authorization/policyAssignments/read"},
+		Delete: []string{"Microsoft.Authorization/policyAssignments/delete"},
+	},
+	ManagementGroupPolicyDefinitionResource: {
+		List:   []string{"Microsoft.Authorization/policyDefinitions/read"},
+		Delete: []string{"Microsoft.Authorization/policyDefinitions/delete"},
+	},
+	ManagementGroupRoleAssignmentResource: {
+		List:   []string{"Microsoft.Authorization/roleAssignments/read", "Microsoft.Authorization/roleDefinitions/read"},
+		Delete: []string{"Microsoft.Authorization/roleAssignments/delete"},
+	},
+	ManagementLockResource: {
+		List:   []string{"Microsoft.Authorization/locks/read"},
+		Delete: []string{"Microsoft.Authorization/locks/delete"},
+	},
+	MonitorDiagnosticSettingResource: {
+		List:   []string{"Microsoft.Insights/diagnosticSettings/read"},
+		Delete: []string{"Microsoft.Insights/diagnosticSettings/delete"},
+	},
+	NetworkInterfaceResource: {
+		List:   []string{"Microsoft.Network/networkInterfaces/read"},
+		Delete: []string{"Microsoft.Network/networkInterfaces/delete"},
+	},
+	NetworkSecurityGroupResource: {
+		List:   []string{"Microsoft.Network/networkSecurityGroups/read"},
+		Delete: []string{"Microsoft.Network/networkSecurityGroups/delete"},
+	},
+	PolicyAssignmentResource: {
+		List:   []string{"Microsoft.Authorization/policyAssignments/read"},
+		Delete: []string{"Microsoft.Authorization/policyAssignments/delete"},
+	},
+	PolicyDefinitionResource: {
+		List:   []string{"Microsoft.Authorization/policyDefinitions/read"},
+		Delete: []string{"Microsoft.Authorization/policyDefinitions/delete"},
+	},
+	PrivateDNSZoneResource: {
+		List:   []string{"Microsoft.Network/privateDnsZones/read"},
+		Delete: []string{"Microsoft.Network/privateDnsZones/delete"},
+	},
+	PublicIPAddressesResource: {
+		List:   []string{"Microsoft.Network/publicIPAddresses/read"},
+		Delete: []string{"Microsoft.Network/publicIPAddresses/delete"},
+	},
+	RecoveryServicesBackupPolicyResource: {
+		List:   []string{"Microsoft.RecoveryServices/vaults/read", "Microsoft.RecoveryServices/vaults/backupPolicies/read"},
+		Delete: []string{"Microsoft.RecoveryServices/vaults/backupPolicies/delete"},
+	},
+	RecoveryServicesBackupProtectedItemResource: {
+		List: []string{
+			"Microsoft.RecoveryServices/vaults/read", "Microsoft.RecoveryServices/vaults/backupProtectedItems/read",
+		},
+		Delete: []string{"Microsoft.RecoveryServices/vaults/backupFabrics/protectionContainers/protectedItems/delete"},
+	},
+	RecoveryServicesBackupProtectionContainerResource: {
+		List: []string{
+			"Microsoft.RecoveryServices/vaults/read", "Microsoft.RecoveryServices/vaults/backupProtectionContainers/read",
+		},
+		Delete: []string{"Microsoft.RecoveryServices/vaults/backupFabrics/protectionContainers/delete"},
+	},
+	RecoveryServicesBackupProtectionIntentResource: {
+		List: []string{
+			"Microsoft.RecoveryServices/vaults/read", "Microsoft.RecoveryServices/vaults/backupProtectionIntents/read",
+		},
+		Delete: []string{"Microsoft.RecoveryServices/vaults/backupFabrics/backupProtectionIntent/delete"},
+	},
+	RecoveryServicesVaultResource: {
+		List:   []string{"Microsoft.RecoveryServices/vaults/read"},
+		Delete: []string{"Microsoft.RecoveryServices/vaults/delete"},
+	},
+	ResourceGroupResource: {
+		List:   []string{"Microsoft.Resources/subscriptions/resourceGroups/read"},
+		Delete: []string{"Microsoft.Resources/subscriptions/resourceGroups/delete"},
+	},
+	SecurityAlertResource: {
+		List:   []string{"Microsoft.Security/locations/alerts/read"},
+		Delete: []string{"Microsoft.Security/locations/alerts/dismiss/action"},
+	},
+	SecurityAssessmentResource: {
+		List:   []string{"Microsoft.Security/assessments/read"},
+		Delete: []string{"Microsoft.Security/assessments/delete"},
+	},
+	SecurityPricingResource: {
+		List:   []string{"Microsoft.Security/pricings/read"},
+		Delete: []string{"Microsoft.Security/pricings/write"},
+	},
+	SecurityWorkspaceResource: {
+		List:   []string{"Microsoft.Security/workspaceSettings/read"},
+		Delete: []string{"Microsoft.Security/workspaceSettings/delete"},
+	},
+	SQLDatabaseResource: {
+		List:   []string{"Microsoft.Sql/servers/read", "Microsoft.Sql/servers/databases/read"},
+		Delete: []string{"Microsoft.Sql/servers/databases/delete"},
+	},
+	SSHPublicKeyResource: {
+		List:   []string{"Microsoft.Compute/sshPublicKeys/read"},
+		Delete: []string{"Microsoft.Compute/sshPublicKeys/delete"},
+	},
+	StorageAccountResource: {
+		List:   []string{"Microsoft.Storage/storageAccounts/read"},
+		Delete: []string{"Microsoft.Storage/storageAccounts/delete"},
+	},
+	SubscriptionRoleAssignmentResource: {
+		List:   []string{"Microsoft.Authorization/roleAssignments/read", "Microsoft.Authorization/roleDefinitions/read"},
+		Delete: []string{"Microsoft.Authorization/roleAssignments/delete"},
+	},
+	VirtualMachineResource: {
+		List:   []string{"Microsoft.Compute/virtualMachines/read"},
+		Delete: []string{"Microsoft.Compute/virtualMachines/delete"},
+	},
+	VirtualMachineScaleSetResource: {
+		List:   []string{"Microsoft.Compute/virtualMachineScaleSets/read"},
+		Delete: []string{"Microsoft.Compute/virtualMachineScaleSets/delete"},
+	},
+	VirtualNetworkResource: {
+		List:   []string{"Microsoft.Network/virtualNetworks/read"},
+		Delete: []string{"Microsoft.Network/virtualNetworks/delete"},
+	},
+}
+
+func init() {
+	for resourceType, p := range graphPermissions {
+		azure.RegisterPermissions(resourceType, p)
+	}
+
+	for resourceType, p := range rbacPermissions {
+		azure.RegisterPermissions(resourceType, p)
+	}
+}
@@ -0,0 +1,28 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/registry"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+func TestEveryResourceTypeHasPermissions(t *testing.T) {
+	for _, name := range registry.GetNames() {
+		p, ok := azure.GetPermissions(name)
+		if !assert.True(t, ok, "no permissions registered for %s", name) {
+			continue
+		}
+
+		if registry.GetRegistration(name).Scope == azure.TenantScope {
+			assert.NotEmpty(t, p.GraphList, name)
+			assert.NotEmpty(t, p.GraphDelete, name)
+		} else {
+			assert.NotEmpty(t, p.List, name)
+			assert.NotEmpty(t, p.Delete, name)
+		}
+	}
+}
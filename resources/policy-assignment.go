@@ -2,10 +2,10 @@ package resources
 
 import (
 	"context"
-	"time"
+	"fmt"
+	"strings"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/services/preview/resources/mgmt/2021-06-01-preview/policy" //nolint:staticcheck
 
@@ -37,10 +37,24 @@ type PolicyAssignment struct {
 }
 
 func (r *PolicyAssignment) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Delete(ctx, r.Scope, r.Name)
 	return err
 }
 
+// Filter excludes the policy assignments that are inherited from a management group, those are handled by the
+// management group scanners instead.
+func (r *PolicyAssignment) Filter() error {
+	if strings.HasPrefix(strings.ToLower(r.Scope), "/providers/microsoft.management/managementgroups/") {
+		return fmt.Errorf("policy assigned at the management group level")
+	}
+
+	return r.BaseResource.Filter()
+}
+
 func (r *PolicyAssignment) Properties() types.Properties {
 	return types.NewPropertiesFromStruct(r)
 }
@@ -55,12 +69,11 @@ type PolicyAssignmentLister struct {
 func (l PolicyAssignmentLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
 
-	log := logrus.WithField("r", PolicyAssignmentResource).WithField("s", opts.SubscriptionID)
+	log := opts.Logger(PolicyAssignmentResource)
 
-	client := policy.NewAssignmentsClient(opts.SubscriptionID)
+	client := policy.NewAssignmentsClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
 	client.Authorizer = opts.Authorizers.Management
-	client.RetryAttempts = 1
-	client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	resources := make([]resource.Resource, 0)
 
@@ -78,7 +91,8 @@ func (l PolicyAssignmentLister) List(ctx context.Context, o interface{}) ([]reso
 		for _, g := range list.Values() {
 			resources = append(resources, &PolicyAssignment{
 				BaseResource: &BaseResource{
-					Region: ptr.String("global"),
+					Region:     ptr.String("global"),
+					SystemData: azure.NewSystemData(g.SystemData),
 				},
 				client:          client,
 				Name:            *g.Name,
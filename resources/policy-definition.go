@@ -2,10 +2,8 @@ package resources
 
 import (
 	"context"
-	"time"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/services/preview/resources/mgmt/2021-06-01-preview/policy" //nolint:staticcheck
 
@@ -37,6 +35,10 @@ type PolicyDefinition struct {
 }
 
 func (r *PolicyDefinition) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Delete(ctx, *r.Name)
 	return err
 }
@@ -55,12 +57,11 @@ type PolicyDefinitionLister struct {
 func (l PolicyDefinitionLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
 
-	log := logrus.WithField("r", PolicyDefinitionResource).WithField("s", opts.SubscriptionID)
+	log := opts.Logger(PolicyDefinitionResource)
 
-	client := policy.NewDefinitionsClient(opts.SubscriptionID)
+	client := policy.NewDefinitionsClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
 	client.Authorizer = opts.Authorizers.Management
-	client.RetryAttempts = 1
-	client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	resources := make([]resource.Resource, 0)
 
@@ -85,7 +86,8 @@ func (l PolicyDefinitionLister) List(ctx context.Context, o interface{}) ([]reso
 
 			resources = append(resources, &PolicyDefinition{
 				BaseResource: &BaseResource{
-					Region: ptr.String("global"),
+					Region:     ptr.String("global"),
+					SystemData: azure.NewSystemData(g.SystemData),
 				},
 				client:      client,
 				Name:        g.Name,
@@ -2,10 +2,8 @@ package resources
 
 import (
 	"context"
-	"time"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/services/privatedns/mgmt/2018-09-01/privatedns" //nolint:staticcheck
 
@@ -31,11 +29,15 @@ type PrivateDNSZone struct {
 	*BaseResource `property:",inline"`
 
 	client privatedns.PrivateZonesClient
+	ID     *string `property:"-"`
 	Name   *string
-	Tags   map[string]*string
 }
 
 func (r *PrivateDNSZone) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Delete(ctx, *r.ResourceGroup, *r.Name, "")
 	return err
 }
@@ -54,18 +56,19 @@ type PrivateDNSZoneLister struct {
 func (l PrivateDNSZoneLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	var resources []resource.Resource
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
 
-	log := logrus.WithFields(logrus.Fields{
-		"r": PrivateDNSZoneResource,
-		"s": opts.SubscriptionID,
-	})
+	if opts.SkipByInventory("microsoft.network/privatednszones") {
+		return nil, nil
+	}
+
+	log := opts.Logger(PrivateDNSZoneResource)
 
 	log.Trace("start")
 
-	client := privatedns.NewPrivateZonesClient(opts.SubscriptionID)
+	client := privatedns.NewPrivateZonesClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
 	client.Authorizer = opts.Authorizers.Management
-	client.RetryAttempts = 1
-	client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	list, err := client.List(ctx, nil)
 	if err != nil {
@@ -85,10 +88,12 @@ func (l PrivateDNSZoneLister) List(ctx context.Context, o interface{}) ([]resour
 					Region:         g.Location,
 					ResourceGroup:  azure.GetResourceGroupFromID(*g.ID),
 					SubscriptionID: ptr.String(opts.SubscriptionID),
+					SystemData:     opts.SystemData(ctx, g.ID),
+					Tags:           g.Tags,
 				},
 				client: client,
+				ID:     g.ID,
 				Name:   g.Name,
-				Tags:   g.Tags,
 			})
 		}
 
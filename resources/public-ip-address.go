@@ -2,9 +2,6 @@ package resources
 
 import (
 	"context"
-	"time"
-
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2022-05-01/network" //nolint:staticcheck
 
@@ -33,11 +30,15 @@ type PublicIPAddresses struct {
 	*BaseResource `property:",inline"`
 
 	client network.PublicIPAddressesClient
+	ID     *string `property:"-"`
 	Name   *string
-	Tags   map[string]*string
 }
 
 func (r *PublicIPAddresses) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Delete(ctx, *r.ResourceGroup, *r.Name)
 	return err
 }
@@ -55,19 +56,30 @@ type PublicIPAddressesLister struct {
 
 func (l PublicIPAddressesLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
+
+	if opts.SkipByInventory("microsoft.network/publicipaddresses") {
+		return nil, nil
+	}
+
+	return opts.ListByResourceGroup(ctx, PublicIPAddressesResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
+	})
+}
 
-	log := logrus.WithField("r", PublicIPAddressesResource).WithField("s", opts.SubscriptionID)
+// listSubscription lists the public ip addresses of every resource group of the subscription
+func (l PublicIPAddressesLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
+	log := opts.Logger(PublicIPAddressesResource)
 
-	client := network.NewPublicIPAddressesClient(opts.SubscriptionID)
+	client := network.NewPublicIPAddressesClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
 	client.Authorizer = opts.Authorizers.Management
-	client.RetryAttempts = 1
-	client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	resources := make([]resource.Resource, 0)
 
 	log.Trace("attempting to list public ip addresses")
 
-	list, err := client.List(ctx, opts.ResourceGroup)
+	list, err := client.ListAll(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -80,12 +92,14 @@ func (l PublicIPAddressesLister) List(ctx context.Context, o interface{}) ([]res
 			resources = append(resources, &PublicIPAddresses{
 				BaseResource: &BaseResource{
 					Region:         g.Location,
-					ResourceGroup:  &opts.ResourceGroup,
+					ResourceGroup:  opts.ResourceGroupFromID(g.ID),
 					SubscriptionID: &opts.SubscriptionID,
+					SystemData:     opts.SystemData(ctx, g.ID),
+					Tags:           g.Tags,
 				},
 				client: client,
+				ID:     g.ID,
 				Name:   g.Name,
-				Tags:   g.Tags,
 			})
 		}
 
@@ -0,0 +1,134 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gotidy/ptr"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+// untaggedResourceTypes are the resource manager types that cannot be tagged, every other type must set the tags of
+// the resource so that `tag:` filters apply to it. Only add a type here if resource manager has no tags for it.
+var untaggedResourceTypes = []string{
+	BudgetResource,
+	ManagementGroupBudgetResource,
+	ManagementGroupPolicyAssignmentResource,
+	ManagementGroupPolicyDefinitionResource,
+	ManagementGroupRoleAssignmentResource,
+	ManagementLockResource,
+	MonitorDiagnosticSettingResource,
+	PolicyAssignmentResource,
+	PolicyDefinitionResource,
+	SecurityAlertResource,
+	SecurityAssessmentResource,
+	SecurityPricingResource,
+	SecurityWorkspaceResource,
+	SubscriptionRoleAssignmentResource,
+}
+
+// quarantine is the quarantine state of a resource, it is set when the resource is enqueued
+type quarantine struct {
+	*azure.Quarantine
+
+	authorizers *azure.Authorizers
+	id          string
+	key         string
+	taggable    bool
+
+	// pending is the mark the resource receives if it would be removed, nil if the resource was already marked
+	pending *azure.QuarantineMark
+}
+
+// checkQuarantine filters the resource unless its grace period has expired, a resource that has not been marked yet
+// is marked after the scan if it is not filtered otherwise.
+func (r *BaseResource) checkQuarantine(i *queue.Item, opts *azure.ListerOpts) {
+	if opts.Quarantine == nil || i.State == queue.ItemStateFiltered || r.filtered != "" {
+		return
+	}
+
+	q := &quarantine{
+		Quarantine:  opts.Quarantine,
+		authorizers: opts.Authorizers,
+		id:          azure.ResourceID(i.Resource),
+	}
+
+	reg := registry.GetRegistration(i.Type)
+	q.taggable = q.id != "" && reg != nil && reg.Scope != azure.TenantScope &&
+		!slices.Contains(untaggedResourceTypes, i.Type)
+
+	q.key = strings.Join([]string{i.Type, q.id}, "/")
+	if q.id == "" {
+		name := ""
+		if stringer, ok := i.Resource.(resource.LegacyStringer); ok {
+			name = stringer.String()
+		}
+
+		q.key = strings.Join([]string{
+			i.Type, ptr.ToString(r.SubscriptionID), ptr.ToString(r.ResourceGroup), name,
+		}, "/")
+	}
+
+	r.quarantine = q
+
+	var mark *azure.QuarantineMark
+	if q.taggable {
+		mark = q.TaggedMark(r.Tags)
+	} else {
+		mark = q.StateMark(q.key)
+	}
+
+	if mark == nil {
+		q.pending = q.NewMark()
+		r.filter(pendingQuarantineReason(q.pending))
+		return
+	}
+
+	if !mark.Expired(q.Now()) {
+		r.filter(fmt.Sprintf("quarantined until %s", mark.DeleteAfter.Format(time.RFC3339)))
+	}
+}
+
+// Quarantine must be called for every resource after the run, it marks the resource if it would have been removed
+// and was not marked yet, the state of a resource that has been removed is dropped. It returns true if the resource
+// was marked.
+func (r *BaseResource) Quarantine(ctx context.Context, item *queue.Item) (bool, error) {
+	q := r.quarantine
+	if q == nil {
+		return false, nil
+	}
+
+	if item.GetState() == queue.ItemStateFinished {
+		q.SetStateMark(q.key, nil)
+		return false, nil
+	}
+
+	// Note: the reason is replaced if the resource was filtered by its own filter or by the configuration
+	if q.pending == nil || item.GetState() != queue.ItemStateFiltered ||
+		item.GetReason() != pendingQuarantineReason(q.pending) {
+		return false, nil
+	}
+
+	if !q.taggable {
+		q.SetStateMark(q.key, q.pending)
+		return true, nil
+	}
+
+	if err := q.Tag(ctx, q.authorizers, q.id, q.pending); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func pendingQuarantineReason(mark *azure.QuarantineMark) string {
+	return fmt.Sprintf("marking for removal after %s", mark.DeleteAfter.Format(time.RFC3339))
+}
@@ -0,0 +1,145 @@
+package resources
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+type quarantineTestResource interface {
+	resource.Resource
+	resource.QueueItemHook
+	Quarantine(ctx context.Context, item *queue.Item) (bool, error)
+}
+
+func TestQuarantine(t *testing.T) {
+	var lock sync.Mutex
+	var tagged []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if r.Method == http.MethodPatch && strings.HasSuffix(r.URL.Path, "/providers/Microsoft.Resources/tags/default") {
+			tagged = append(tagged, strings.TrimSuffix(r.URL.Path, "/providers/Microsoft.Resources/tags/default"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	q, err := azure.NewQuarantine(filepath.Join(t.TempDir(), "quarantine.json"), time.Hour)
+	assert.NoError(t, err)
+
+	expired := &azure.QuarantineMark{MarkedAt: q.Now().Add(-2 * time.Hour), DeleteAfter: q.Now().Add(-time.Hour)}
+	q.SetStateMark("AzureADUser/expired", expired)
+
+	opts := &azure.ListerOpts{
+		Authorizers: newTestAuthorizers(t, server.URL),
+		Quarantine:  q,
+	}
+
+	gateway := func(name string, tags map[string]*string) *ApplicationGateway {
+		return &ApplicationGateway{
+			BaseResource: &BaseResource{
+				SubscriptionID: ptr.String("sub-a"),
+				ResourceGroup:  ptr.String("rg"),
+				Tags:           tags,
+			},
+			ID:   ptr.String("/subscriptions/sub-a/resourceGroups/rg/providers/Microsoft.Network/applicationGateways/" + name),
+			Name: ptr.String(name),
+		}
+	}
+
+	cases := []struct {
+		name         string
+		resource     quarantineTestResource
+		resourceType string
+		filtered     bool
+		want         queue.ItemState
+		marked       bool
+	}{
+		{
+			name:         "unmarked resource is marked",
+			resource:     gateway("new", nil),
+			resourceType: ApplicationGatewayResource,
+			want:         queue.ItemStateFiltered,
+			marked:       true,
+		},
+		{
+			name:         "filtered resource is not marked",
+			resource:     gateway("filtered", nil),
+			resourceType: ApplicationGatewayResource,
+			filtered:     true,
+			want:         queue.ItemStateFiltered,
+		},
+		{
+			name:         "marked resource is kept during the grace period",
+			resource:     gateway("marked", q.NewMark().Tags()),
+			resourceType: ApplicationGatewayResource,
+			want:         queue.ItemStateFiltered,
+		},
+		{
+			name:         "marked resource is removed after the grace period",
+			resource:     gateway("expired", expired.Tags()),
+			resourceType: ApplicationGatewayResource,
+			want:         queue.ItemStateNew,
+		},
+		{
+			name: "unmarked entra object is recorded in the state",
+			resource: &AzureADUser{
+				BaseResource: &BaseResource{},
+				ID:           ptr.String("new"),
+			},
+			resourceType: AzureADUserResource,
+			want:         queue.ItemStateFiltered,
+			marked:       true,
+		},
+		{
+			name: "entra object is removed after the grace period",
+			resource: &AzureADUser{
+				BaseResource: &BaseResource{},
+				ID:           ptr.String("expired"),
+			},
+			resourceType: AzureADUserResource,
+			want:         queue.ItemStateNew,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			item := &queue.Item{Resource: tc.resource, State: queue.ItemStateNew, Type: tc.resourceType, Opts: opts}
+			enqueue(item)
+			assert.Equal(t, tc.want, item.State)
+
+			// Note: this is what the configuration filters do to a resource that they match
+			if tc.filtered {
+				item.State = queue.ItemStateFiltered
+				item.Reason = "filtered by config"
+			}
+
+			marked, err := tc.resource.Quarantine(context.Background(), item)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.marked, marked)
+		})
+	}
+
+	assert.Equal(t, []string{
+		"/subscriptions/sub-a/resourceGroups/rg/providers/Microsoft.Network/applicationGateways/new",
+	}, tagged)
+	assert.NotNil(t, q.StateMark("AzureADUser/new"))
+}
@@ -0,0 +1,79 @@
+package resources
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/registry"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+// newRecordingTestOpts returns the lister options used to record and replay every lister
+func newRecordingTestOpts(authorizers *azure.Authorizers) *azure.ListerOpts {
+	return &azure.ListerOpts{
+		Authorizers:       authorizers,
+		TenantID:          "00000000-0000-0000-0000-000000000000",
+		ManagementGroupID: "test-mg",
+		SubscriptionID:    "00000000-0000-0000-0000-000000000001",
+		ResourceGroup:     "test-rg",
+		Regions:           []string{"all"},
+	}
+}
+
+func TestListersReplayRecording(t *testing.T) {
+	server := newListerTestServer()
+	dir := t.TempDir()
+
+	authorizers := newTestAuthorizers(t, server.URL)
+
+	recorder, err := azure.NewRecorder(dir, authorizers.Environment)
+	assert.NoError(t, err)
+	authorizers.Pipeline.SetRecorder(recorder)
+
+	recorded := make(map[string]int)
+	for name, reg := range registry.GetRegistrations() {
+		if reg.Scope != azure.ResourceGroupScope {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		resources, err := reg.Lister.List(ctx, newRecordingTestOpts(authorizers))
+		cancel()
+
+		assert.NoError(t, err, name)
+		assert.NotEmpty(t, resources, name)
+		recorded[name] = len(resources)
+	}
+
+	// Note: nothing can be sent to the server from now on, every response has to come from the recording
+	server.Close()
+
+	replayer, err := azure.NewReplayer(dir)
+	assert.NoError(t, err)
+	defer replayer.Close()
+
+	replayed := newTestAuthorizers(t, server.URL)
+	replayed.Pipeline = azure.NewPipeline(replayer)
+	replayed.ClientOptions.Transport = replayed.Pipeline.HTTPClient()
+
+	for name, reg := range registry.GetRegistrations() {
+		if reg.Scope != azure.ResourceGroupScope {
+			continue
+		}
+
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			resources, err := reg.Lister.List(ctx, newRecordingTestOpts(replayed))
+			assert.NoError(t, err)
+			assert.Len(t, resources, recorded[name])
+		})
+	}
+
+	assert.Equal(t, int64(0), replayer.Unmatched())
+}
@@ -5,13 +5,11 @@ import (
 	"time"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/go-azure-helpers/resourcemanager/commonids"
 	"github.com/hashicorp/go-azure-sdk/resource-manager/recoveryservices/2023-02-01/vaults"
 	"github.com/hashicorp/go-azure-sdk/resource-manager/recoveryservicesbackup/2023-02-01/backuppolicies"
 	"github.com/hashicorp/go-azure-sdk/resource-manager/recoveryservicesbackup/2023-02-01/protectionpolicies"
-	"github.com/hashicorp/go-azure-sdk/sdk/environments"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
@@ -47,10 +45,14 @@ type RecoveryServicesBackupPolicy struct {
 }
 
 func (r *RecoveryServicesBackupPolicy) Filter() error {
-	return nil
+	return r.BaseResource.Filter()
 }
 
 func (r *RecoveryServicesBackupPolicy) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.protectionsClient.Delete(ctx, r.backupPolicyID)
 	return err
 }
@@ -68,50 +70,59 @@ type RecoveryServicesBackupPolicyLister struct {
 
 func (l RecoveryServicesBackupPolicyLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
 
+	if opts.SkipByInventory("microsoft.recoveryservices/vaults") {
+		return nil, nil
+	}
+
+	return opts.ListByResourceGroup(ctx, RecoveryServicesBackupPolicyResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
+	})
+}
+
+// listSubscription lists the backup policies of every resource group of the subscription
+func (l RecoveryServicesBackupPolicyLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
 	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(30*time.Second))
 	defer cancel()
 
-	log := logrus.
-		WithField("r", RecoveryServicesBackupPolicyResource).
-		WithField("s", opts.SubscriptionID).
-		WithField("rg", opts.ResourceGroup)
+	log := opts.Logger(RecoveryServicesBackupPolicyResource)
 
 	log.Trace("creating client")
 
-	vaultsClient, err := vaults.NewVaultsClientWithBaseURI(environments.AzurePublic().ResourceManager) // TODO: pass in the endpoint
+	vaultsClient, err := vaults.NewVaultsClientWithBaseURI(opts.Authorizers.Environment.ResourceManager)
 	if err != nil {
 		return nil, err
 	}
 	vaultsClient.Client.Authorizer = opts.Authorizers.Management
+	opts.Authorizers.Pipeline.ConfigureResourceManager(vaultsClient.Client)
 
-	// TODO: pass in the endpoint
 	client :=
-		backuppolicies.NewBackupPoliciesClientWithBaseURI("https://management.azure.com")
+		backuppolicies.NewBackupPoliciesClientWithBaseURI(opts.ResourceManagerEndpoint())
 	client.Client.Authorizer = opts.Authorizers.Management
-	client.Client.RetryAttempts = 1
-	client.Client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
-	// TODO: pass in the endpoint
 	protectionsClient :=
-		protectionpolicies.NewProtectionPoliciesClientWithBaseURI("https://management.azure.com")
+		protectionpolicies.NewProtectionPoliciesClientWithBaseURI(opts.ResourceManagerEndpoint())
 	protectionsClient.Client.Authorizer = opts.Authorizers.Management
-	protectionsClient.Client.RetryAttempts = 1
-	protectionsClient.Client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&protectionsClient.Client)
 
 	resources := make([]resource.Resource, 0)
 
 	log.Trace("listing resources")
 
-	vaultsRes, err :=
-		vaultsClient.ListByResourceGroupComplete(
-			ctx, commonids.NewResourceGroupID(opts.SubscriptionID, opts.ResourceGroup))
+	vaultsRes, err := vaultsClient.ListBySubscriptionIdComplete(ctx, commonids.NewSubscriptionID(opts.SubscriptionID))
 	if err != nil {
 		return nil, err
 	}
 
 	for _, v := range vaultsRes.Items {
-		vaultID := backuppolicies.NewVaultID(opts.SubscriptionID, opts.ResourceGroup, ptr.ToString(v.Name))
+		resourceGroup := opts.ResourceGroupFromID(v.Id)
+		if resourceGroup == nil || !opts.ListsResourceGroup(*resourceGroup) {
+			continue
+		}
+
+		vaultID := backuppolicies.NewVaultID(opts.SubscriptionID, *resourceGroup, ptr.ToString(v.Name))
 		items, err := client.ListComplete(ctx, vaultID, backuppolicies.DefaultListOperationOptions())
 		if err != nil {
 			return nil, err
@@ -121,15 +132,17 @@ func (l RecoveryServicesBackupPolicyLister) List(ctx context.Context, o interfac
 			resources = append(resources, &RecoveryServicesBackupPolicy{
 				BaseResource: &BaseResource{
 					Region:         item.Location,
-					ResourceGroup:  &opts.ResourceGroup,
+					ResourceGroup:  resourceGroup,
 					SubscriptionID: &opts.SubscriptionID,
+					SystemData:     opts.SystemData(ctx, item.Id),
+					Tags:           azure.Tags(item.Tags),
 				},
 				client:            client,
 				protectionsClient: protectionsClient,
 				ID:                item.Id,
 				Name:              item.Name,
 				backupPolicyID: protectionpolicies.NewBackupPolicyID(
-					opts.SubscriptionID, opts.ResourceGroup, ptr.ToString(v.Name), ptr.ToString(item.Name)),
+					opts.SubscriptionID, *resourceGroup, ptr.ToString(v.Name), ptr.ToString(item.Name)),
 			})
 		}
 	}
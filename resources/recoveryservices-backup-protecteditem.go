@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/recoveryservices/armrecoveryservices"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/recoveryservices/armrecoveryservicesbackup"
@@ -45,10 +44,14 @@ type RecoveryServicesBackupProtectedItem struct {
 }
 
 func (r *RecoveryServicesBackupProtectedItem) Filter() error {
-	return nil
+	return r.BaseResource.Filter()
 }
 
 func (r *RecoveryServicesBackupProtectedItem) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.itemClient.Delete(
 		ctx, to.String(r.VaultName), to.String(r.ResourceGroup),
 		to.String(r.backupFabric), to.String(r.ContainerName), to.String(r.Name), nil)
@@ -68,36 +71,45 @@ type RecoveryServicesBackupProtectedItemLister struct {
 
 func (l RecoveryServicesBackupProtectedItemLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
 
+	if opts.SkipByInventory("microsoft.recoveryservices/vaults") {
+		return nil, nil
+	}
+
+	return opts.ListByResourceGroup(ctx, RecoveryServicesBackupProtectedItemResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
+	})
+}
+
+// listSubscription lists the backup protected items of every resource group of the subscription
+func (l RecoveryServicesBackupProtectedItemLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
 	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(30*time.Second))
 	defer cancel()
 
 	resources := make([]resource.Resource, 0)
 
-	log := logrus.
-		WithField("r", RecoveryServicesBackupProtectedItemResource).
-		WithField("s", opts.SubscriptionID).
-		WithField("rg", opts.ResourceGroup)
+	log := opts.Logger(RecoveryServicesBackupProtectedItemResource)
 
 	log.Trace("creating client")
-	vaultsClient, err := armrecoveryservices.NewVaultsClient(opts.SubscriptionID, opts.Authorizers.IdentityCreds, nil)
+	vaultsClient, err := armrecoveryservices.NewVaultsClient(opts.SubscriptionID, opts.Authorizers.IdentityCreds, opts.ARMClientOptions())
 	if err != nil {
 		return resources, err
 	}
 
-	client, err := armrecoveryservicesbackup.NewBackupProtectedItemsClient(opts.SubscriptionID, opts.Authorizers.IdentityCreds, nil)
+	client, err := armrecoveryservicesbackup.NewBackupProtectedItemsClient(opts.SubscriptionID, opts.Authorizers.IdentityCreds, opts.ARMClientOptions())
 	if err != nil {
 		return resources, err
 	}
 
-	protectedItems, err := armrecoveryservicesbackup.NewProtectedItemsClient(opts.SubscriptionID, opts.Authorizers.IdentityCreds, nil)
+	protectedItems, err := armrecoveryservicesbackup.NewProtectedItemsClient(opts.SubscriptionID, opts.Authorizers.IdentityCreds, opts.ARMClientOptions())
 	if err != nil {
 		return resources, err
 	}
 
 	log.Trace("listing resources")
 
-	vaultsPager := vaultsClient.NewListByResourceGroupPager(opts.ResourceGroup, nil)
+	vaultsPager := vaultsClient.NewListBySubscriptionIDPager(nil)
 	for vaultsPager.More() {
 		log.Trace("not done")
 		page, err := vaultsPager.NextPage(ctx)
@@ -106,7 +118,12 @@ func (l RecoveryServicesBackupProtectedItemLister) List(ctx context.Context, o i
 		}
 
 		for _, v := range page.Value {
-			itemPager := client.NewListPager(to.String(v.Name), opts.ResourceGroup, nil)
+			resourceGroup := opts.ResourceGroupFromID(v.ID)
+			if resourceGroup == nil || !opts.ListsResourceGroup(*resourceGroup) {
+				continue
+			}
+
+			itemPager := client.NewListPager(to.String(v.Name), *resourceGroup, nil)
 			for itemPager.More() {
 				page, err := itemPager.NextPage(ctx)
 				if err != nil {
@@ -124,8 +141,10 @@ func (l RecoveryServicesBackupProtectedItemLister) List(ctx context.Context, o i
 					resources = append(resources, &RecoveryServicesBackupProtectedItem{
 						BaseResource: &BaseResource{
 							Region:         i.Location,
-							ResourceGroup:  &opts.ResourceGroup,
+							ResourceGroup:  resourceGroup,
 							SubscriptionID: &opts.SubscriptionID,
+							SystemData:     opts.SystemData(ctx, i.ID),
+							Tags:           i.Tags,
 						},
 						client:     client,
 						itemClient: protectedItems,
@@ -5,7 +5,6 @@ import (
 	"time"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/recoveryservices/armrecoveryservices"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/recoveryservices/armrecoveryservicesbackup"
@@ -41,10 +40,14 @@ type RecoveryServicesBackupProtectionContainers struct {
 }
 
 func (r *RecoveryServicesBackupProtectionContainers) Filter() error {
-	return nil
+	return r.BaseResource.Filter()
 }
 
 func (r *RecoveryServicesBackupProtectionContainers) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.pClient.Unregister(ctx, to.String(r.VaultName), to.String(r.ResourceGroup), to.String(r.backupFabric), to.String(r.Name), nil)
 	return err
 }
@@ -62,43 +65,52 @@ type RecoveryServicesBackupProtectionContainersLister struct {
 
 func (l RecoveryServicesBackupProtectionContainersLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
 
+	if opts.SkipByInventory("microsoft.recoveryservices/vaults") {
+		return nil, nil
+	}
+
+	return opts.ListByResourceGroup(ctx, RecoveryServicesBackupProtectionContainerResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
+	})
+}
+
+// listSubscription lists the backup protection containers of every resource group of the subscription
+func (l RecoveryServicesBackupProtectionContainersLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
 	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(30*time.Second))
 	defer cancel()
 
 	resources := make([]resource.Resource, 0)
 
-	log := logrus.
-		WithField("r", RecoveryServicesBackupProtectionContainerResource).
-		WithField("s", opts.SubscriptionID).
-		WithField("rg", opts.ResourceGroup)
+	log := opts.Logger(RecoveryServicesBackupProtectionContainerResource)
 
 	log.Trace("creating client")
 
 	vaultsClient, err :=
 		armrecoveryservices.NewVaultsClient(
-			opts.SubscriptionID, opts.Authorizers.IdentityCreds, nil)
+			opts.SubscriptionID, opts.Authorizers.IdentityCreds, opts.ARMClientOptions())
 	if err != nil {
 		return resources, err
 	}
 
 	client, err :=
 		armrecoveryservicesbackup.NewBackupProtectionContainersClient(
-			opts.SubscriptionID, opts.Authorizers.IdentityCreds, nil)
+			opts.SubscriptionID, opts.Authorizers.IdentityCreds, opts.ARMClientOptions())
 	if err != nil {
 		return resources, err
 	}
 
 	protectedContainers, err :=
 		armrecoveryservicesbackup.NewProtectionContainersClient(
-			opts.SubscriptionID, opts.Authorizers.IdentityCreds, nil)
+			opts.SubscriptionID, opts.Authorizers.IdentityCreds, opts.ARMClientOptions())
 	if err != nil {
 		return resources, err
 	}
 
 	log.Trace("listing resources")
 
-	vaultsPager := vaultsClient.NewListByResourceGroupPager(opts.ResourceGroup, nil)
+	vaultsPager := vaultsClient.NewListBySubscriptionIDPager(nil)
 	for vaultsPager.More() {
 		page, err := vaultsPager.NextPage(ctx)
 		if err != nil {
@@ -106,7 +118,12 @@ func (l RecoveryServicesBackupProtectionContainersLister) List(ctx context.Conte
 		}
 
 		for _, v := range page.Value {
-			itemPager := client.NewListPager(to.String(v.Name), opts.ResourceGroup, nil)
+			resourceGroup := opts.ResourceGroupFromID(v.ID)
+			if resourceGroup == nil || !opts.ListsResourceGroup(*resourceGroup) {
+				continue
+			}
+
+			itemPager := client.NewListPager(to.String(v.Name), *resourceGroup, nil)
 			for itemPager.More() {
 				page, err := itemPager.NextPage(ctx)
 				if err != nil {
@@ -117,8 +134,10 @@ func (l RecoveryServicesBackupProtectionContainersLister) List(ctx context.Conte
 					resources = append(resources, &RecoveryServicesBackupProtectionContainers{
 						BaseResource: &BaseResource{
 							Region:         i.Location,
-							ResourceGroup:  &opts.ResourceGroup,
+							ResourceGroup:  resourceGroup,
 							SubscriptionID: &opts.SubscriptionID,
+							SystemData:     opts.SystemData(ctx, i.ID),
+							Tags:           i.Tags,
 						},
 						client:       client,
 						pClient:      protectedContainers,
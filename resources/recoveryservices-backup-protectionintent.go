@@ -5,7 +5,6 @@ import (
 	"time"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/recoveryservices/armrecoveryservices"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/recoveryservices/armrecoveryservicesbackup"
@@ -41,10 +40,14 @@ type RecoveryServicesBackupProtectionIntent struct {
 }
 
 func (r *RecoveryServicesBackupProtectionIntent) Filter() error {
-	return nil
+	return r.BaseResource.Filter()
 }
 
 func (r *RecoveryServicesBackupProtectionIntent) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.pClient.Delete(ctx, to.String(r.VaultName), to.String(r.ResourceGroup), to.String(r.backupFabric), to.String(r.Name), nil)
 	return err
 }
@@ -62,36 +65,46 @@ type RecoveryServicesBackupProtectionIntentLister struct {
 
 func (l RecoveryServicesBackupProtectionIntentLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
+
+	if opts.SkipByInventory("microsoft.recoveryservices/vaults") {
+		return nil, nil
+	}
+
+	return opts.ListByResourceGroup(ctx, RecoveryServicesBackupProtectionIntentResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
+	})
+}
+
+// listSubscription lists the backup protection intents of every resource group of the subscription
+func (l RecoveryServicesBackupProtectionIntentLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
 	resources := make([]resource.Resource, 0)
 
 	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(30*time.Second))
 	defer cancel()
 
-	log := logrus.
-		WithField("r", RecoveryServicesBackupProtectionIntentResource).
-		WithField("s", opts.SubscriptionID).
-		WithField("rg", opts.ResourceGroup)
+	log := opts.Logger(RecoveryServicesBackupProtectionIntentResource)
 
 	log.Trace("creating client")
 
-	vaultsClient, err := armrecoveryservices.NewVaultsClient(opts.SubscriptionID, opts.Authorizers.IdentityCreds, nil)
+	vaultsClient, err := armrecoveryservices.NewVaultsClient(opts.SubscriptionID, opts.Authorizers.IdentityCreds, opts.ARMClientOptions())
 	if err != nil {
 		return resources, err
 	}
 
-	client, err := armrecoveryservicesbackup.NewBackupProtectionIntentClient(opts.SubscriptionID, opts.Authorizers.IdentityCreds, nil)
+	client, err := armrecoveryservicesbackup.NewBackupProtectionIntentClient(opts.SubscriptionID, opts.Authorizers.IdentityCreds, opts.ARMClientOptions())
 	if err != nil {
 		return resources, err
 	}
 
-	protectedContainers, err := armrecoveryservicesbackup.NewProtectionIntentClient(opts.SubscriptionID, opts.Authorizers.IdentityCreds, nil)
+	protectedContainers, err := armrecoveryservicesbackup.NewProtectionIntentClient(opts.SubscriptionID, opts.Authorizers.IdentityCreds, opts.ARMClientOptions())
 	if err != nil {
 		return resources, err
 	}
 
 	log.Trace("listing resources")
 
-	vaultsPager := vaultsClient.NewListByResourceGroupPager(opts.ResourceGroup, nil)
+	vaultsPager := vaultsClient.NewListBySubscriptionIDPager(nil)
 	for vaultsPager.More() {
 		page, err := vaultsPager.NextPage(ctx)
 		if err != nil {
@@ -99,7 +112,12 @@ func (l RecoveryServicesBackupProtectionIntentLister) List(ctx context.Context,
 		}
 
 		for _, v := range page.Value {
-			itemPager := client.NewListPager(to.String(v.Name), opts.ResourceGroup, nil)
+			resourceGroup := opts.ResourceGroupFromID(v.ID)
+			if resourceGroup == nil || !opts.ListsResourceGroup(*resourceGroup) {
+				continue
+			}
+
+			itemPager := client.NewListPager(to.String(v.Name), *resourceGroup, nil)
 			for itemPager.More() {
 				page, err := itemPager.NextPage(ctx)
 				if err != nil {
@@ -110,7 +128,9 @@ func (l RecoveryServicesBackupProtectionIntentLister) List(ctx context.Context,
 					resources = append(resources, &RecoveryServicesBackupProtectionIntent{
 						BaseResource: &BaseResource{
 							Region:        i.Location,
-							ResourceGroup: to.StringPtr(opts.ResourceGroup),
+							ResourceGroup: resourceGroup,
+							SystemData:    opts.SystemData(ctx, i.ID),
+							Tags:          i.Tags,
 						},
 						client:       client,
 						pClient:      protectedContainers,
@@ -5,11 +5,9 @@ import (
 	"time"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/go-azure-helpers/resourcemanager/commonids"
 	"github.com/hashicorp/go-azure-sdk/resource-manager/recoveryservices/2023-02-01/vaults"
-	"github.com/hashicorp/go-azure-sdk/sdk/environments"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
@@ -42,10 +40,14 @@ type RecoveryServicesVault struct {
 }
 
 func (r *RecoveryServicesVault) Filter() error {
-	return nil
+	return r.BaseResource.Filter()
 }
 
 func (r *RecoveryServicesVault) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Delete(ctx, r.vaultID)
 	return err
 }
@@ -64,39 +66,55 @@ type RecoveryServicesVaultLister struct {
 func (l RecoveryServicesVaultLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
 
+	if opts.SkipByInventory("microsoft.recoveryservices/vaults") {
+		return nil, nil
+	}
+
+	return opts.ListByResourceGroup(ctx, RecoveryServicesVaultResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
+	})
+}
+
+// listSubscription lists the recovery services vaults of every resource group of the subscription
+func (l RecoveryServicesVaultLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
 	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(30*time.Second))
 	defer cancel()
 
-	log := logrus.
-		WithField("r", RecoveryServicesVaultResource).
-		WithField("s", opts.SubscriptionID).
-		WithField("rg", opts.ResourceGroup)
+	log := opts.Logger(RecoveryServicesVaultResource)
 
 	log.Trace("creating client")
 
-	client, err := vaults.NewVaultsClientWithBaseURI(environments.AzurePublic().ResourceManager) // TODO: pass in the endpoint
+	client, err := vaults.NewVaultsClientWithBaseURI(opts.Authorizers.Environment.ResourceManager)
 	if err != nil {
 		return nil, err
 	}
 	client.Client.Authorizer = opts.Authorizers.Management
+	opts.Authorizers.Pipeline.ConfigureResourceManager(client.Client)
 
 	resources := make([]resource.Resource, 0)
 
 	log.Trace("listing resources")
 
-	items, err := client.ListByResourceGroupComplete(ctx, commonids.NewResourceGroupID(opts.SubscriptionID, opts.ResourceGroup))
+	items, err := client.ListBySubscriptionIdComplete(ctx, commonids.NewSubscriptionID(opts.SubscriptionID))
 	if err != nil {
 		return nil, err
 	}
 
 	for _, item := range items.Items {
+		resourceGroup := opts.ResourceGroupFromID(item.Id)
+		if resourceGroup == nil {
+			continue
+		}
+
 		resources = append(resources, &RecoveryServicesVault{
 			BaseResource: &BaseResource{
 				Region:        ptr.String(item.Location),
-				ResourceGroup: ptr.String(opts.ResourceGroup),
+				ResourceGroup: resourceGroup,
+				SystemData:    azure.NewSystemData(item.SystemData),
+				Tags:          azure.Tags(item.Tags),
 			},
 			client:  client,
-			vaultID: vaults.NewVaultID(opts.SubscriptionID, opts.ResourceGroup, ptr.ToString(item.Id)),
+			vaultID: vaults.NewVaultID(opts.SubscriptionID, *resourceGroup, ptr.ToString(item.Name)),
 
 			ID:   item.Id,
 			Name: item.Name,
@@ -0,0 +1,121 @@
+package resources
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+func TestResourcesInheritResourceGroup(t *testing.T) {
+	server := newListerTestServer()
+	defer server.Close()
+
+	for name, reg := range registry.GetRegistrations() {
+		if reg.Scope != azure.ResourceGroupScope {
+			continue
+		}
+
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			groups := azure.NewResourceGroupIndex()
+			groups.Add("00000000-0000-0000-0000-000000000001", &azure.ResourceGroupDetails{
+				Name:     "TEST-RG",
+				Location: "westeurope",
+				Tags:     map[string]*string{"keep": ptr.String("true")},
+			})
+
+			opts := &azure.ListerOpts{
+				Authorizers:          newTestAuthorizers(t, server.URL),
+				TenantID:             "00000000-0000-0000-0000-000000000000",
+				SubscriptionID:       "00000000-0000-0000-0000-000000000001",
+				ResourceGroup:        "test-rg",
+				Regions:              []string{"all"},
+				ResourceGroupDetails: groups,
+			}
+
+			resources, err := reg.Lister.List(ctx, opts)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, resources)
+
+			for _, r := range resources {
+				item := &queue.Item{Resource: r, State: queue.ItemStateNew, Type: name, Opts: opts}
+				enqueue(item)
+
+				properties := r.(resource.PropertyGetter).Properties()
+				assert.Equal(t, "true", properties.Get("rg-tag:keep"))
+				assert.Equal(t, "westeurope", properties.Get("ResourceGroupLocation"))
+				assert.Equal(t, "test", properties.Get("tag:owner"), "the resource group tags replaced the tags")
+				assert.Equal(t, queue.ItemStateNew, item.State)
+			}
+		})
+	}
+}
+
+func TestResourcesOfFilteredResourceGroup(t *testing.T) {
+	groups := azure.NewResourceGroupIndex()
+	groups.Add("sub-a", &azure.ResourceGroupDetails{Name: "kept", Filtered: true})
+	groups.Add("sub-a", &azure.ResourceGroupDetails{Name: "removed"})
+
+	cases := []struct {
+		name  string
+		group string
+		id    string
+		want  queue.ItemState
+	}{
+		{
+			name:  "filtered resource group",
+			group: "kept",
+			want:  queue.ItemStateFiltered,
+		},
+		{
+			name: "filtered resource group from id",
+			id:   "/subscriptions/sub-a/resourceGroups/KEPT/providers/Microsoft.Network/applicationGateways/gateway",
+			want: queue.ItemStateFiltered,
+		},
+		{
+			name:  "resource group that is not filtered",
+			group: "removed",
+			want:  queue.ItemStateNew,
+		},
+		{
+			name:  "unknown resource group",
+			group: "unknown",
+			want:  queue.ItemStateNew,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &ApplicationGateway{
+				BaseResource: &BaseResource{
+					SubscriptionID: ptr.String("sub-a"),
+				},
+				ID: ptr.String(tc.id),
+			}
+			if tc.group != "" {
+				r.ResourceGroup = ptr.String(tc.group)
+			}
+
+			item := &queue.Item{
+				Resource: r,
+				State:    queue.ItemStateNew,
+				Type:     ApplicationGatewayResource,
+				Opts:     &azure.ListerOpts{SubscriptionID: "sub-a", ResourceGroupDetails: groups},
+			}
+			enqueue(item)
+
+			assert.Equal(t, tc.want, item.State)
+		})
+	}
+}
@@ -5,11 +5,9 @@ import (
 	"time"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/go-azure-helpers/resourcemanager/commonids"
 	"github.com/hashicorp/go-azure-sdk/resource-manager/resources/2022-09-01/resourcegroups"
-	"github.com/hashicorp/go-azure-sdk/sdk/environments"
 
 	"github.com/ekristen/libnuke/pkg/registry"
 	"github.com/ekristen/libnuke/pkg/resource"
@@ -20,12 +18,19 @@ import (
 
 const ResourceGroupResource = "ResourceGroup"
 
+// ResourceGroupFilterResourcesSetting is the setting that filters the resources of a resource group whenever the
+// resource group itself is filtered
+const ResourceGroupFilterResourcesSetting = "FilterResources"
+
 func init() {
 	registry.Register(&registry.Registration{
 		Name:     ResourceGroupResource,
 		Scope:    azure.SubscriptionScope,
 		Resource: &ResourceGroup{},
 		Lister:   &ResourceGroupLister{},
+		Settings: []string{
+			ResourceGroupFilterResourcesSetting,
+		},
 	})
 }
 
@@ -34,11 +39,15 @@ type ResourceGroup struct {
 	*BaseResource `property:",inline"`
 
 	client *resourcegroups.ResourceGroupsClient
-	Name   *string            `description:"The Name of the resource group."`
-	Tags   *map[string]string `description:"The tags assigned to the resource group."`
+	ID     *string `property:"-"`
+	Name   *string `description:"The Name of the resource group."`
 }
 
 func (r *ResourceGroup) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(30*time.Second))
 	defer cancel()
 
@@ -63,17 +72,19 @@ type ResourceGroupLister struct {
 
 func (l ResourceGroupLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
 
 	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(30*time.Second))
 	defer cancel()
 
-	log := logrus.WithField("r", ResourceGroupResource).WithField("s", opts.SubscriptionID)
+	log := opts.Logger(ResourceGroupResource)
 
-	client, err := resourcegroups.NewResourceGroupsClientWithBaseURI(environments.AzurePublic().ResourceManager)
+	client, err := resourcegroups.NewResourceGroupsClientWithBaseURI(opts.Authorizers.Environment.ResourceManager)
 	if err != nil {
 		return nil, err
 	}
 	client.Client.Authorizer = opts.Authorizers.Management
+	opts.Authorizers.Pipeline.ConfigureResourceManager(client.Client)
 
 	resources := make([]resource.Resource, 0)
 
@@ -91,10 +102,12 @@ func (l ResourceGroupLister) List(ctx context.Context, o interface{}) ([]resourc
 			BaseResource: &BaseResource{
 				Region:         ptr.String(entity.Location),
 				SubscriptionID: ptr.String(opts.SubscriptionID),
+				SystemData:     opts.SystemData(ctx, entity.Id),
+				Tags:           azure.Tags(entity.Tags),
 			},
 			client: client,
+			ID:     entity.Id,
 			Name:   entity.Name,
-			Tags:   entity.Tags,
 		})
 	}
 
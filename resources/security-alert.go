@@ -4,10 +4,8 @@ import (
 	"context"
 	"fmt"
 	"regexp"
-	"time"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/services/preview/security/mgmt/v3.0/security" //nolint:staticcheck
 
@@ -46,10 +44,14 @@ func (r *SecurityAlert) Filter() error {
 		return fmt.Errorf("alert already dismissed")
 	}
 
-	return nil
+	return r.BaseResource.Filter()
 }
 
 func (r *SecurityAlert) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	// Note: we cannot actually remove alerts :(
 	// So we just have to dismiss them instead
 	_, err := r.client.UpdateSubscriptionLevelStateToDismiss(ctx, *r.Region, r.Name)
@@ -71,19 +73,17 @@ type SecurityAlertsLister struct {
 
 func (l SecurityAlertsLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
 
-	log := logrus.
-		WithField("r", SecurityAlertResource).
-		WithField("s", opts.SubscriptionID)
+	log := opts.Logger(SecurityAlertResource)
 
 	log.Trace("creating client")
 
 	locationRe := regexp.MustCompile(SecurityAlertLocation)
 
-	client := security.NewAlertsClient(opts.SubscriptionID)
+	client := security.NewAlertsClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
 	client.Authorizer = opts.Authorizers.Management
-	client.RetryAttempts = 1
-	client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	resources := make([]resource.Resource, 0)
 
@@ -100,7 +100,8 @@ func (l SecurityAlertsLister) List(ctx context.Context, o interface{}) ([]resour
 			matches := locationRe.FindStringSubmatch(ptr.ToString(g.ID))
 			resources = append(resources, &SecurityAlert{
 				BaseResource: &BaseResource{
-					Region: ptr.String(matches[1]),
+					Region:     ptr.String(matches[1]),
+					SystemData: opts.SystemData(ctx, g.ID),
 				},
 				client:      client,
 				ID:          *g.ID,
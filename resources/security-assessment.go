@@ -6,7 +6,6 @@ import (
 	"strings"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/security/armsecurity"
 	"github.com/Azure/go-autorest/autorest/to"
@@ -40,10 +39,14 @@ type SecurityAssessment struct {
 }
 
 func (r *SecurityAssessment) Filter() error {
-	return nil
+	return r.BaseResource.Filter()
 }
 
 func (r *SecurityAssessment) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Delete(ctx, strings.TrimLeft(to.String(r.ResourceID), "/"), to.String(r.Name), nil)
 	return err
 }
@@ -63,14 +66,13 @@ type SecurityAssessmentLister struct {
 
 func (l SecurityAssessmentLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
 
-	log := logrus.
-		WithField("r", SecurityAssessmentResource).
-		WithField("s", opts.SubscriptionID)
+	log := opts.Logger(SecurityAssessmentResource)
 
 	log.Trace("creating client")
 
-	clientFactory, err := armsecurity.NewClientFactory(opts.SubscriptionID, opts.Authorizers.IdentityCreds, nil)
+	clientFactory, err := armsecurity.NewClientFactory(opts.SubscriptionID, opts.Authorizers.IdentityCreds, opts.ARMClientOptions())
 	if err != nil {
 		return nil, err
 	}
@@ -98,7 +100,8 @@ func (l SecurityAssessmentLister) List(ctx context.Context, o interface{}) ([]re
 			parts := strings.Split(to.String(v.ID), "/providers/Microsoft.Security")
 			resources = append(resources, &SecurityAssessment{
 				BaseResource: &BaseResource{
-					Region: ptr.String("global"),
+					Region:     ptr.String("global"),
+					SystemData: opts.SystemData(ctx, v.ID),
 				},
 				client:     client,
 				ResourceID: ptr.String(parts[0]),
@@ -3,10 +3,8 @@ package resources
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/services/preview/security/mgmt/v3.0/security" //nolint:staticcheck
 
@@ -43,10 +41,14 @@ func (r *SecurityPricing) Filter() error {
 	if r.PricingTier == "Free" {
 		return fmt.Errorf("already set to free tier")
 	}
-	return nil
+	return r.BaseResource.Filter()
 }
 
 func (r *SecurityPricing) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Update(ctx, *r.Name, security.Pricing{
 		PricingProperties: &security.PricingProperties{
 			PricingTier: "Free",
@@ -70,17 +72,15 @@ type SecurityPricingLister struct {
 
 func (l SecurityPricingLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
 
-	log := logrus.
-		WithField("r", SecurityPricingResource).
-		WithField("s", opts.SubscriptionID)
+	log := opts.Logger(SecurityPricingResource)
 
 	log.Trace("creating client")
 
-	client := security.NewPricingsClient(opts.SubscriptionID)
+	client := security.NewPricingsClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
 	client.Authorizer = opts.Authorizers.Management
-	client.RetryAttempts = 1
-	client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	resources := make([]resource.Resource, 0)
 
@@ -94,7 +94,8 @@ func (l SecurityPricingLister) List(ctx context.Context, o interface{}) ([]resou
 	for _, price := range *list.Value {
 		resources = append(resources, &SecurityPricing{
 			BaseResource: &BaseResource{
-				Region: ptr.String("global"),
+				Region:     ptr.String("global"),
+				SystemData: opts.SystemData(ctx, price.ID),
 			},
 			client:      client,
 			Name:        price.Name,
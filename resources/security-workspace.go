@@ -2,10 +2,8 @@ package resources
 
 import (
 	"context"
-	"time"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/services/preview/security/mgmt/v3.0/security" //nolint:staticcheck
 
@@ -36,6 +34,10 @@ type SecurityWorkspace struct {
 }
 
 func (r *SecurityWorkspace) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Delete(ctx, *r.Name)
 	return err
 }
@@ -55,17 +57,15 @@ type SecurityWorkspaceLister struct {
 
 func (l SecurityWorkspaceLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
 
-	log := logrus.
-		WithField("r", SecurityWorkspaceResource).
-		WithField("s", opts.SubscriptionID)
+	log := opts.Logger(SecurityWorkspaceResource)
 
 	log.Trace("creating client")
 
-	client := security.NewWorkspaceSettingsClient(opts.SubscriptionID)
+	client := security.NewWorkspaceSettingsClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
 	client.Authorizer = opts.Authorizers.Management
-	client.RetryAttempts = 1
-	client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	resources := make([]resource.Resource, 0)
 
@@ -81,7 +81,8 @@ func (l SecurityWorkspaceLister) List(ctx context.Context, o interface{}) ([]res
 		for _, g := range list.Values() {
 			resources = append(resources, &SecurityWorkspace{
 				BaseResource: &BaseResource{
-					Region: ptr.String("global"),
+					Region:     ptr.String("global"),
+					SystemData: opts.SystemData(ctx, g.ID),
 				},
 				client: client,
 				Name:   g.Name,
@@ -0,0 +1,141 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+func TestIdentityRunningTheNukeIsFiltered(t *testing.T) {
+	caller := &azure.Identity{ObjectID: "sp-object-id", AppID: "app-client-id"}
+
+	cases := map[string]struct {
+		self  interface{ Filter() error }
+		other interface{ Filter() error }
+	}{
+		ApplicationResource: {
+			self:  &Application{clientID: ptr.String("APP-CLIENT-ID"), caller: caller},
+			other: &Application{clientID: ptr.String("other"), caller: caller},
+		},
+		ApplicationSecretResource: {
+			self:  &ApplicationSecret{clientID: ptr.String("app-client-id"), caller: caller},
+			other: &ApplicationSecret{clientID: ptr.String("other"), caller: caller},
+		},
+		ApplicationCertificateResource: {
+			self:  &ApplicationCertificate{clientID: ptr.String("app-client-id"), caller: caller},
+			other: &ApplicationCertificate{clientID: ptr.String("other"), caller: caller},
+		},
+		ApplicationFederatedCredentialResource: {
+			self:  &ApplicationFederatedCredential{clientID: ptr.String("app-client-id"), caller: caller},
+			other: &ApplicationFederatedCredential{clientID: ptr.String("other"), caller: caller},
+		},
+		ServicePrincipalResource: {
+			self:  &ServicePrincipal{ID: ptr.String("sp-object-id"), caller: caller},
+			other: &ServicePrincipal{ID: ptr.String("other"), appID: ptr.String("other"), caller: caller},
+		},
+		AzureADUserResource: {
+			self:  &AzureADUser{ID: ptr.String("sp-object-id"), caller: caller},
+			other: &AzureADUser{ID: ptr.String("other"), caller: caller},
+		},
+		SubscriptionRoleAssignmentResource: {
+			self: &SubscriptionRoleAssignment{
+				PrincipalID: ptr.String("sp-object-id"), caller: caller,
+				scope: ptr.String("/subscriptions/sub"), subscriptionID: ptr.String("sub"),
+			},
+			other: &SubscriptionRoleAssignment{
+				PrincipalID: ptr.String("other"), caller: caller,
+				scope: ptr.String("/subscriptions/sub"), subscriptionID: ptr.String("sub"),
+			},
+		},
+		ManagementGroupRoleAssignmentResource: {
+			self: &ManagementGroupRoleAssignment{
+				PrincipalID: ptr.String("sp-object-id"), caller: caller, scope: ptr.String("/mg"), mgScope: "/mg",
+			},
+			other: &ManagementGroupRoleAssignment{
+				PrincipalID: ptr.String("other"), caller: caller, scope: ptr.String("/mg"), mgScope: "/mg",
+			},
+		},
+		GenericResourceResource: {
+			self: &GenericResource{
+				ResourceType: ptr.String(userAssignedIdentityResourceType), caller: caller,
+				principalID: ptr.String("sp-object-id"), clientID: ptr.String("app-client-id"),
+			},
+			other: &GenericResource{
+				ResourceType: ptr.String(userAssignedIdentityResourceType), caller: caller,
+				principalID: ptr.String("other"), clientID: ptr.String("other"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		err := tc.self.Filter()
+		assert.Error(t, err, name)
+		assert.Contains(t, err.Error(), "identity running the nuke", name)
+		assert.NoError(t, tc.other.Filter(), name)
+	}
+}
+
+func TestManagedIdentityRunningTheNukeIsFiltered(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000001"
+	const identities = "/subscriptions/" + subscriptionID +
+		"/resourceGroups/rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// Note: the resource ID is appended to the path of the endpoint as is, which results in a double slash
+		path := "/" + strings.TrimLeft(r.URL.Path, "/")
+
+		switch {
+		case path == "/subscriptions/"+subscriptionID+"/resources":
+			_, _ = fmt.Fprintf(w, `{"value":[
+				{"id":"%[1]sself","name":"self","type":"%[2]s","location":"eastus"},
+				{"id":"%[1]sother","name":"other","type":"%[2]s","location":"eastus"},
+				{"id":"%[1]sunknown","name":"unknown","type":"%[2]s","location":"eastus"}
+			]}`, identities, userAssignedIdentityResourceType)
+		case strings.HasSuffix(path, "/providers/Microsoft.ManagedIdentity"):
+			_, _ = w.Write([]byte(`{"resourceTypes":[
+				{"resourceType":"userAssignedIdentities","apiVersions":["2023-01-31","2024-11-30-preview"]}
+			]}`))
+		case path == identities+"self" && r.URL.Query().Get("api-version") == "2023-01-31":
+			_, _ = w.Write([]byte(`{"properties":{"principalId":"sp-object-id","clientId":"app-client-id"}}`))
+		case path == identities+"other" && r.URL.Query().Get("api-version") == "2023-01-31":
+			_, _ = w.Write([]byte(`{"properties":{"principalId":"other","clientId":"other"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":"NotFound","message":"not found"}}`))
+		}
+	}))
+	defer server.Close()
+
+	authorizers := newTestAuthorizers(t, server.URL)
+	authorizers.Identity = &azure.Identity{ObjectID: "sp-object-id", AppID: "app-client-id"}
+
+	listed, err := GenericResourceLister{}.List(context.Background(), &azure.ListerOpts{
+		Authorizers:    authorizers,
+		SubscriptionID: subscriptionID,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, listed, 3)
+
+	filtered := make(map[string]string)
+	for _, r := range listed {
+		generic := r.(*GenericResource)
+		if err := generic.Filter(); err != nil {
+			filtered[generic.String()] = err.Error()
+		}
+	}
+
+	assert.Equal(t, map[string]string{
+		"self":    "cannot delete the managed identity of the identity running the nuke",
+		"unknown": "cannot verify that the managed identity is not the identity running the nuke",
+	}, filtered)
+}
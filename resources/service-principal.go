@@ -6,7 +6,6 @@ import (
 	"strings"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/go-azure-sdk/sdk/odata"
 	"github.com/manicminer/hamilton/msgraph"
@@ -37,9 +36,16 @@ type ServicePrincipal struct {
 	Name     *string
 	AppOwner *string `property:"name=AppOwnerId"`
 	SPType   *string `property:"name=ServicePrincipalType"`
+
+	appID  *string
+	caller *azure.Identity
 }
 
 func (r *ServicePrincipal) Filter() error {
+	if r.caller.IsObject(r.ID) || r.caller.IsApplication(r.appID) {
+		return fmt.Errorf("cannot delete the service principal of the identity running the nuke")
+	}
+
 	if ptr.ToString(r.SPType) == "ManagedIdentity" {
 		return fmt.Errorf("cannot delete managed service principals")
 	}
@@ -56,7 +62,7 @@ func (r *ServicePrincipal) Filter() error {
 		return fmt.Errorf("cannot delete defender linked service principals")
 	}
 
-	return nil
+	return r.BaseResource.Filter()
 }
 
 func (r *ServicePrincipal) Remove(ctx context.Context) error {
@@ -81,11 +87,12 @@ func (l ServicePrincipalsLister) List(ctx context.Context, o interface{}) ([]res
 	var resources []resource.Resource
 	opts := o.(*azure.ListerOpts)
 
-	log := logrus.WithField("r", ServicePrincipalResource).WithField("s", opts.SubscriptionID)
+	log := opts.Logger(ServicePrincipalResource)
 
 	client := msgraph.NewServicePrincipalsClient()
+	client.BaseClient.Endpoint = opts.MicrosoftGraphEndpoint()
 	client.BaseClient.Authorizer = opts.Authorizers.MicrosoftGraph
-	client.BaseClient.DisableRetries = true
+	opts.Authorizers.Pipeline.ConfigureGraph(&client.BaseClient)
 
 	log.Trace("attempting to list service principals")
 
@@ -115,6 +122,8 @@ func (l ServicePrincipalsLister) List(ctx context.Context, o interface{}) ([]res
 			Name:     entity.DisplayName,
 			AppOwner: entity.AppOwnerOrganizationId,
 			SPType:   entity.ServicePrincipalType,
+			appID:    entity.AppId,
+			caller:   opts.Authorizers.Identity,
 		})
 	}
 
@@ -0,0 +1,194 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gotidy/ptr"
+
+	"github.com/Azure/azure-sdk-for-go/services/preview/sql/mgmt/v5.0/sql" //nolint:staticcheck
+
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+	"github.com/ekristen/libnuke/pkg/types"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+const SQLDatabaseResource = "SQLDatabase"
+
+func init() {
+	registry.Register(&registry.Registration{
+		Name:     SQLDatabaseResource,
+		Scope:    azure.ResourceGroupScope,
+		Resource: &SQLDatabase{},
+		Lister:   &SQLDatabaseLister{},
+	})
+}
+
+type SQLDatabase struct {
+	*BaseResource `property:",inline"`
+
+	client sql.DatabasesClient
+	ID     *string `property:"-"`
+	Name   *string
+	Server *string `description:"The name of the SQL server that the database belongs to."`
+	Tier   *string `description:"The tier of the database (i.e. GeneralPurpose or DataWarehouse)."`
+	Status *string `description:"The status of the database (i.e. Online or Paused)."`
+}
+
+func (r *SQLDatabase) Filter() error {
+	if ptr.ToString(r.Name) == "master" {
+		return fmt.Errorf("cannot remove the master database")
+	}
+
+	return r.BaseResource.Filter()
+}
+
+func (r *SQLDatabase) Remove(ctx context.Context) error {
+	if r.StopMode() {
+		return r.Stop(ctx)
+	}
+
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
+	_, err := r.client.Delete(ctx, *r.ResourceGroup, *r.Server, *r.Name)
+	return err
+}
+
+// StopAction returns no action for the databases that cannot be paused, only dedicated SQL pools (data warehouses)
+// can be paused on demand
+func (r *SQLDatabase) StopAction() string {
+	if !strings.EqualFold(ptr.ToString(r.Tier), "DataWarehouse") {
+		return ""
+	}
+
+	return "pause"
+}
+
+func (r *SQLDatabase) Stopped() bool {
+	status := sql.DatabaseStatus(ptr.ToString(r.Status))
+	return status == sql.DatabaseStatusPaused || status == sql.DatabaseStatusPausing
+}
+
+func (r *SQLDatabase) Stop(ctx context.Context) error {
+	_, err := r.client.Pause(ctx, *r.ResourceGroup, *r.Server, *r.Name)
+	return err
+}
+
+func (r *SQLDatabase) VolatileProperties() []string {
+	return []string{"Status"}
+}
+
+func (r *SQLDatabase) Properties() types.Properties {
+	return types.NewPropertiesFromStruct(r)
+}
+
+func (r *SQLDatabase) String() string {
+	return fmt.Sprintf("%s/%s", *r.Server, *r.Name)
+}
+
+// -----------------------------------------
+
+type SQLDatabaseLister struct {
+}
+
+func (l SQLDatabaseLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
+	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
+
+	if opts.SkipByInventory("microsoft.sql/servers/databases") {
+		return nil, nil
+	}
+
+	return opts.ListByResourceGroup(ctx, SQLDatabaseResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
+	})
+}
+
+// listSubscription lists the sql databases of every resource group of the subscription
+func (l SQLDatabaseLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
+	log := opts.Logger(SQLDatabaseResource)
+
+	serversClient := sql.NewServersClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
+	serversClient.Authorizer = opts.Authorizers.Management
+	opts.Authorizers.Pipeline.ConfigureAutorest(&serversClient.Client)
+
+	client := sql.NewDatabasesClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
+	client.Authorizer = opts.Authorizers.Management
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
+
+	resources := make([]resource.Resource, 0)
+
+	log.Trace("attempting to list sql servers")
+
+	serverList, err := serversClient.ListComplete(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Note: only the databases of the servers in the resource groups that are listed are listed
+	servers := make([]sql.Server, 0)
+	for serverList.NotDone() {
+		server := serverList.Value()
+		if resourceGroup := opts.ResourceGroupFromID(server.ID); resourceGroup != nil && opts.ListsResourceGroup(*resourceGroup) {
+			servers = append(servers, server)
+		}
+
+		if err := serverList.NextWithContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, server := range servers {
+		resourceGroup := opts.ResourceGroupFromID(server.ID)
+
+		log.Trace("attempting to list sql databases")
+
+		list, err := client.ListByServerComplete(ctx, *resourceGroup, *server.Name, "")
+		if err != nil {
+			return nil, err
+		}
+
+		for list.NotDone() {
+			g := list.Value()
+
+			var tier *string
+			if g.Sku != nil {
+				tier = g.Sku.Tier
+			}
+
+			var status *string
+			if g.DatabaseProperties != nil {
+				status = ptr.String(string(g.Status))
+			}
+
+			resources = append(resources, &SQLDatabase{
+				BaseResource: &BaseResource{
+					Region:         g.Location,
+					ResourceGroup:  resourceGroup,
+					SubscriptionID: &opts.SubscriptionID,
+					SystemData:     opts.SystemData(ctx, g.ID),
+					Tags:           g.Tags,
+				},
+				client: client,
+				ID:     g.ID,
+				Name:   g.Name,
+				Server: server.Name,
+				Tier:   tier,
+				Status: status,
+			})
+
+			if err := list.NextWithContext(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	log.Trace("done")
+
+	return resources, nil
+}
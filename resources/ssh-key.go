@@ -2,9 +2,6 @@ package resources
 
 import (
 	"context"
-	"time"
-
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute" //nolint:staticcheck
 
@@ -30,11 +27,15 @@ type SSHPublicKey struct {
 	*BaseResource `property:",inline"`
 
 	client compute.SSHPublicKeysClient
+	ID     *string `property:"-"`
 	Name   *string
-	Tags   map[string]*string
 }
 
 func (r *SSHPublicKey) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Delete(ctx, *r.ResourceGroup, *r.Name)
 	return err
 }
@@ -54,13 +55,17 @@ type SSHPublicKeyLister struct {
 
 func (l SSHPublicKeyLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
+
+	if opts.SkipByInventory("microsoft.compute/sshpublickeys") {
+		return nil, nil
+	}
 
-	log := logrus.WithField("r", SSHPublicKeyResource).WithField("s", opts.SubscriptionID)
+	log := opts.Logger(SSHPublicKeyResource)
 
-	client := compute.NewSSHPublicKeysClient(opts.SubscriptionID)
+	client := compute.NewSSHPublicKeysClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
 	client.Authorizer = opts.Authorizers.Management
-	client.RetryAttempts = 1
-	client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	resources := make([]resource.Resource, 0)
 
@@ -81,10 +86,12 @@ func (l SSHPublicKeyLister) List(ctx context.Context, o interface{}) ([]resource
 					Region:         &opts.Region,
 					SubscriptionID: &opts.SubscriptionID,
 					ResourceGroup:  azure.GetResourceGroupFromID(*g.ID),
+					SystemData:     opts.SystemData(ctx, g.ID),
+					Tags:           g.Tags,
 				},
 				client: client,
+				ID:     g.ID,
 				Name:   g.Name,
-				Tags:   g.Tags,
 			})
 		}
 
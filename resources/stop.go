@@ -0,0 +1,58 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/resource"
+)
+
+// Stopper is implemented by the resources that can be stopped instead of removed (i.e. a virtual machine that can be
+// deallocated). In stop mode every resource that is not a Stopper is skipped, the others are stopped instead of
+// removed by their Remove function.
+type Stopper interface {
+	// StopAction describes what stopping the resource does (i.e. deallocate), it is empty if the resource has no
+	// safe stop action
+	StopAction() string
+
+	// Stopped checks if the resource is already stopped
+	Stopped() bool
+
+	// Stop stops the resource
+	Stop(ctx context.Context) error
+}
+
+// StopMode checks if the resource has to be stopped instead of removed, this must be checked by the Remove function
+// of every Stopper.
+func (r *BaseResource) StopMode() bool {
+	return r.stop
+}
+
+// NoStopAction is the reason the resources that cannot be stopped are skipped in stop mode
+const NoStopAction = "no stop action"
+
+// checkStop skips the resources that have no stop action or that are already stopped
+func (r *BaseResource) checkStop(i *queue.Item) {
+	r.stop = true
+
+	if i.State == queue.ItemStateFiltered {
+		return
+	}
+
+	stopper, ok := i.Resource.(Stopper)
+	if !ok || stopper.StopAction() == "" {
+		r.filter(NoStopAction)
+		return
+	}
+
+	if stopper.Stopped() {
+		r.filter(fmt.Sprintf("already stopped (%s)", stopper.StopAction()))
+	}
+}
+
+// CanStop checks if the resource can be stopped, the removal of every other resource is refused in stop mode
+func CanStop(r resource.Resource) bool {
+	stopper, ok := r.(Stopper)
+	return ok && stopper.StopAction() != ""
+}
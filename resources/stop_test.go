@@ -0,0 +1,147 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+func TestStopMode(t *testing.T) {
+	base := func() *BaseResource {
+		return &BaseResource{
+			SubscriptionID: ptr.String("sub-a"),
+			ResourceGroup:  ptr.String("rg"),
+		}
+	}
+
+	cases := []struct {
+		name     string
+		resource resource.QueueItemHook
+		filtered bool
+		want     queue.ItemState
+		reason   string
+	}{
+		{
+			name:     "running virtual machine is deallocated",
+			resource: &VirtualMachine{BaseResource: base(), Name: ptr.String("vm"), PowerState: ptr.String("running")},
+			want:     queue.ItemStateNew,
+		},
+		{
+			name:     "deallocated virtual machine is skipped",
+			resource: &VirtualMachine{BaseResource: base(), Name: ptr.String("vm"), PowerState: ptr.String("deallocated")},
+			want:     queue.ItemStateFiltered,
+			reason:   "already stopped (deallocate)",
+		},
+		{
+			name:     "scale set is scaled to zero",
+			resource: &VirtualMachineScaleSet{BaseResource: base(), Name: ptr.String("vmss"), Capacity: ptr.Int64(3)},
+			want:     queue.ItemStateNew,
+		},
+		{
+			name: "scale set of a kubernetes node pool is skipped",
+			resource: &VirtualMachineScaleSet{
+				BaseResource: &BaseResource{Tags: map[string]*string{"aks-managed-poolName": ptr.String("pool")}},
+				Name:         ptr.String("aks-pool"),
+				Capacity:     ptr.Int64(3),
+			},
+			want:   queue.ItemStateFiltered,
+			reason: "no stop action",
+		},
+		{
+			name:     "stopped kubernetes cluster is skipped",
+			resource: &KubernetesCluster{BaseResource: base(), Name: ptr.String("aks"), PowerState: ptr.String("Stopped")},
+			want:     queue.ItemStateFiltered,
+			reason:   "already stopped (stop)",
+		},
+		{
+			name: "dedicated sql pool is paused",
+			resource: &SQLDatabase{
+				BaseResource: base(), Name: ptr.String("dw"), Server: ptr.String("sql"),
+				Tier: ptr.String("DataWarehouse"), Status: ptr.String("Online"),
+			},
+			want: queue.ItemStateNew,
+		},
+		{
+			name: "sql database that cannot be paused is skipped",
+			resource: &SQLDatabase{
+				BaseResource: base(), Name: ptr.String("db"), Server: ptr.String("sql"),
+				Tier: ptr.String("GeneralPurpose"), Status: ptr.String("Online"),
+			},
+			want:   queue.ItemStateFiltered,
+			reason: "no stop action",
+		},
+		{
+			name:     "app service plan with a single instance is skipped",
+			resource: &AppServicePlan{BaseResource: base(), Capacity: ptr.Int32(1)},
+			want:     queue.ItemStateFiltered,
+			reason:   "already stopped (scale down to one instance)",
+		},
+		{
+			name:     "resource without a stop action is skipped",
+			resource: &Disk{BaseResource: base(), Name: ptr.String("disk")},
+			want:     queue.ItemStateFiltered,
+			reason:   "no stop action",
+		},
+		{
+			name:     "filtered resource keeps its reason",
+			resource: &VirtualMachine{BaseResource: base(), Name: ptr.String("vm"), PowerState: ptr.String("running")},
+			filtered: true,
+			want:     queue.ItemStateFiltered,
+			reason:   "filtered by resource",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			item := &queue.Item{
+				Resource: tc.resource.(resource.Resource),
+				State:    queue.ItemStateNew,
+				Opts:     &azure.ListerOpts{Stop: true},
+			}
+
+			if tc.filtered {
+				item.State = queue.ItemStateFiltered
+				item.Reason = "filtered by resource"
+			}
+
+			enqueue(item)
+			assert.Equal(t, tc.want, item.State)
+			assert.Equal(t, tc.reason, item.Reason)
+		})
+	}
+}
+
+func TestStopModeIsSet(t *testing.T) {
+	vm := &VirtualMachine{BaseResource: &BaseResource{}, Name: ptr.String("vm")}
+	assert.False(t, vm.StopMode())
+
+	vm.BeforeEnqueue(&queue.Item{Resource: vm, State: queue.ItemStateNew, Opts: &azure.ListerOpts{Stop: true}})
+	assert.True(t, vm.StopMode())
+}
+
+func TestVolatilePropertiesAreNotCompared(t *testing.T) {
+	vm := func(name, powerState, lastModifiedBy string) *VirtualMachine {
+		return &VirtualMachine{
+			BaseResource: &BaseResource{
+				SubscriptionID: ptr.String("sub-a"),
+				ResourceGroup:  ptr.String("rg"),
+				SystemData:     &azure.SystemData{LastModifiedBy: ptr.String(lastModifiedBy)},
+			},
+			Name:       ptr.String(name),
+			PowerState: ptr.String(powerState),
+		}
+	}
+
+	queued := vm("vm", "running", "user")
+
+	assert.True(t, SameResource(queued, vm("vm", "deallocating", "user")))
+	assert.True(t, SameResource(queued, vm("vm", "running", "nuke")))
+	assert.False(t, SameResource(queued, vm("other", "running", "user")))
+	assert.False(t, SameResource(queued, &Disk{BaseResource: &BaseResource{}, Name: ptr.String("vm")}))
+}
@@ -2,9 +2,6 @@ package resources
 
 import (
 	"context"
-	"time"
-
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-09-01/storage" //nolint:staticcheck
 
@@ -33,11 +30,15 @@ type StorageAccount struct {
 	*BaseResource `property:",inline"`
 
 	client storage.AccountsClient
+	ID     *string `property:"-"`
 	Name   *string
-	Tags   map[string]*string
 }
 
 func (r *StorageAccount) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Delete(ctx, *r.ResourceGroup, *r.Name)
 	return err
 }
@@ -57,19 +58,30 @@ type StorageAccountLister struct {
 
 func (l StorageAccountLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
+
+	if opts.SkipByInventory("microsoft.storage/storageaccounts") {
+		return nil, nil
+	}
+
+	return opts.ListByResourceGroup(ctx, StorageAccountResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
+	})
+}
 
-	log := logrus.WithField("r", StorageAccountResource).WithField("s", opts.SubscriptionID)
+// listSubscription lists the storage accounts of every resource group of the subscription
+func (l StorageAccountLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
+	log := opts.Logger(StorageAccountResource)
 
-	client := storage.NewAccountsClient(opts.SubscriptionID)
+	client := storage.NewAccountsClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
 	client.Authorizer = opts.Authorizers.Management
-	client.RetryAttempts = 1
-	client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	resources := make([]resource.Resource, 0)
 
 	log.Trace("attempting to list ssh key")
 
-	list, err := client.ListByResourceGroup(ctx, opts.ResourceGroup)
+	list, err := client.List(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -82,12 +94,14 @@ func (l StorageAccountLister) List(ctx context.Context, o interface{}) ([]resour
 			resources = append(resources, &StorageAccount{
 				BaseResource: &BaseResource{
 					Region:         g.Location,
-					ResourceGroup:  &opts.ResourceGroup,
+					ResourceGroup:  opts.ResourceGroupFromID(g.ID),
 					SubscriptionID: &opts.SubscriptionID,
+					SystemData:     opts.SystemData(ctx, g.ID),
+					Tags:           g.Tags,
 				},
 				client: client,
+				ID:     g.ID,
 				Name:   g.Name,
-				Tags:   g.Tags,
 			})
 		}
 
@@ -6,13 +6,10 @@ import (
 	"strings"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/go-azure-sdk/sdk/odata"
 	"github.com/manicminer/hamilton/msgraph"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization"
 
 	"github.com/ekristen/libnuke/pkg/registry"
@@ -51,19 +48,28 @@ type SubscriptionRoleAssignment struct {
 	PrincipalType    *string
 	scope            *string
 	subscriptionID   *string
+	caller           *azure.Identity
 }
 
 func (r *SubscriptionRoleAssignment) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Delete(ctx, *r.scope, *r.Name, nil)
 	return err
 }
 
 func (r *SubscriptionRoleAssignment) Filter() error {
-	if *r.scope != fmt.Sprintf("/subscriptions/%s", *r.subscriptionID) {
+	if r.caller.IsObject(r.PrincipalID) {
+		return fmt.Errorf("cannot delete role assignments of the identity running the nuke")
+	}
+
+	if ptr.ToString(r.scope) != fmt.Sprintf("/subscriptions/%s", ptr.ToString(r.subscriptionID)) {
 		return fmt.Errorf("role assigned at a different level than the subscription")
 	}
 
-	return nil
+	return r.BaseResource.Filter()
 }
 
 func (r *SubscriptionRoleAssignment) Properties() types.Properties {
@@ -81,36 +87,39 @@ type SubscriptionRoleAssignmentLister struct {
 
 func (l *SubscriptionRoleAssignmentLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) { //nolint:gocyclo,funlen
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
 	var resources []resource.Resource
 
-	log := logrus.WithField("r", SubscriptionRoleAssignmentResource).WithField("s", opts.SubscriptionID)
+	log := opts.Logger(SubscriptionRoleAssignmentResource)
+
+	clientOptions := opts.ARMClientOptions()
+	clientOptions.APIVersion = "2022-04-01"
 
 	client, err := armauthorization.NewRoleAssignmentsClient(
-		opts.SubscriptionID, opts.Authorizers.IdentityCreds, &arm.ClientOptions{
-			ClientOptions: azcore.ClientOptions{
-				APIVersion: "2022-04-01",
-			},
-		})
+		opts.SubscriptionID, opts.Authorizers.IdentityCreds, clientOptions)
 	if err != nil {
 		return resources, nil
 	}
 
-	defClient, err := armauthorization.NewRoleDefinitionsClient(opts.Authorizers.IdentityCreds, nil)
+	defClient, err := armauthorization.NewRoleDefinitionsClient(opts.Authorizers.IdentityCreds, opts.ARMClientOptions())
 	if err != nil {
 		return resources, nil
 	}
 
 	userClient := msgraph.NewUsersClient()
+	userClient.BaseClient.Endpoint = opts.MicrosoftGraphEndpoint()
 	userClient.BaseClient.Authorizer = opts.Authorizers.Graph
-	userClient.BaseClient.DisableRetries = true
+	opts.Authorizers.Pipeline.ConfigureGraph(&userClient.BaseClient)
 
 	groupClient := msgraph.NewGroupsClient()
+	groupClient.BaseClient.Endpoint = opts.MicrosoftGraphEndpoint()
 	groupClient.BaseClient.Authorizer = opts.Authorizers.MicrosoftGraph
-	groupClient.BaseClient.DisableRetries = true
+	opts.Authorizers.Pipeline.ConfigureGraph(&groupClient.BaseClient)
 
 	spClient := msgraph.NewServicePrincipalsClient()
+	spClient.BaseClient.Endpoint = opts.MicrosoftGraphEndpoint()
 	spClient.BaseClient.Authorizer = opts.Authorizers.MicrosoftGraph
-	spClient.BaseClient.DisableRetries = true
+	opts.Authorizers.Pipeline.ConfigureGraph(&spClient.BaseClient)
 
 	log.Debug("listing subscription role assignments")
 	pager := client.NewListPager(&armauthorization.RoleAssignmentsClientListOptions{Filter: ptr.String("atScope()")})
@@ -174,7 +183,8 @@ func (l *SubscriptionRoleAssignmentLister) List(ctx context.Context, o interface
 
 			resources = append(resources, &SubscriptionRoleAssignment{
 				BaseResource: &BaseResource{
-					Region: ptr.String("global"),
+					Region:     ptr.String("global"),
+					SystemData: opts.SystemData(ctx, t.ID),
 				},
 				client:           client,
 				scope:            t.Properties.Scope,
@@ -187,6 +197,7 @@ func (l *SubscriptionRoleAssignmentLister) List(ctx context.Context, o interface
 				PrincipalID:      t.Properties.PrincipalID,
 				PrincipalName:    principalName,
 				PrincipalType:    principalType,
+				caller:           opts.Authorizers.Identity,
 			})
 		}
 	}
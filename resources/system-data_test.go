@@ -0,0 +1,63 @@
+package resources
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+func TestListersSetSystemData(t *testing.T) {
+	server := newListerTestServer()
+	defer server.Close()
+
+	// Note: entra does not return a creation time for the credentials of an application or for service principals
+	skip := []string{
+		ApplicationCertificateResource, ApplicationFederatedCredentialResource, ApplicationSecretResource,
+		ServicePrincipalResource,
+	}
+
+	for name, reg := range registry.GetRegistrations() {
+		if slices.Contains(skip, name) {
+			continue
+		}
+
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			resources, err := reg.Lister.List(ctx, &azure.ListerOpts{
+				Authorizers:       newTestAuthorizers(t, server.URL),
+				TenantID:          "00000000-0000-0000-0000-000000000000",
+				ManagementGroupID: "test-mg",
+				SubscriptionID:    "00000000-0000-0000-0000-000000000001",
+				ResourceGroup:     "test-rg",
+				Regions:           []string{"all"},
+			})
+			assert.NoError(t, err)
+			assert.NotEmpty(t, resources)
+
+			for _, r := range resources {
+				properties := r.(resource.PropertyGetter).Properties()
+
+				assert.Equal(t, "2024-01-02T03:04:05Z", properties.Get("CreatedAt"))
+
+				if reg.Scope == azure.TenantScope {
+					continue
+				}
+
+				assert.Equal(t, "creator@example.com", properties.Get("CreatedBy"))
+				assert.Equal(t, "User", properties.Get("CreatedByType"))
+				assert.Equal(t, "2024-02-03T04:05:06Z", properties.Get("LastModifiedAt"))
+				assert.Equal(t, "00000000-0000-0000-0000-000000000003", properties.Get("LastModifiedBy"))
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package resources
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+func TestListersSetTags(t *testing.T) {
+	server := newListerTestServer()
+	defer server.Close()
+
+	for name, reg := range registry.GetRegistrations() {
+		if reg.Scope == azure.TenantScope || slices.Contains(untaggedResourceTypes, name) {
+			continue
+		}
+
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			resources, err := reg.Lister.List(ctx, &azure.ListerOpts{
+				Authorizers:       newTestAuthorizers(t, server.URL),
+				TenantID:          "00000000-0000-0000-0000-000000000000",
+				ManagementGroupID: "test-mg",
+				SubscriptionID:    "00000000-0000-0000-0000-000000000001",
+				ResourceGroup:     "test-rg",
+				Regions:           []string{"all"},
+			})
+			assert.NoError(t, err)
+			assert.NotEmpty(t, resources)
+
+			for _, r := range resources {
+				properties := r.(resource.PropertyGetter).Properties()
+				assert.Equal(t, "test", properties.Get("tag:owner"), "the lister does not set the tags of the resource")
+				assert.NotEmpty(t, azure.ResourceID(r), "the lister does not set the ID the resource is tagged by")
+			}
+		})
+	}
+}
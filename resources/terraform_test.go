@@ -0,0 +1,141 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ekristen/libnuke/pkg/queue"
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+	"github.com/ekristen/azure-nuke/pkg/terraform"
+)
+
+func newTestTerraformState(t *testing.T, ids ...string) *terraform.State {
+	resources := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		resources = append(resources, map[string]interface{}{
+			"mode": "managed",
+			"type": "azurerm_resource_group_template_deployment",
+			"name": "test",
+			"instances": []map[string]interface{}{
+				{"attributes": map[string]interface{}{"id": id}},
+			},
+		})
+	}
+
+	raw, err := json.Marshal(map[string]interface{}{"version": 4, "resources": resources})
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "terraform.tfstate"), raw, 0600))
+
+	state, err := terraform.Load(dir, "terraform.tfstate")
+	assert.NoError(t, err)
+
+	return state
+}
+
+func TestResourcesManagedByTerraform(t *testing.T) {
+	managedID := "/subscriptions/sub-a/resourceGroups/rg/providers/Microsoft.Compute/disks/managed"
+	state := newTestTerraformState(t, managedID)
+
+	cases := []struct {
+		name    string
+		id      string
+		state   *terraform.State
+		managed string
+		source  string
+	}{
+		{
+			name:    "resource in the state",
+			id:      "/subscriptions/sub-a/resourcegroups/RG/providers/Microsoft.Compute/disks/managed",
+			state:   state,
+			managed: "true",
+			source:  "terraform.tfstate",
+		},
+		{
+			name:    "resource that is not in the state",
+			id:      "/subscriptions/sub-a/resourceGroups/rg/providers/Microsoft.Compute/disks/manual",
+			state:   state,
+			managed: "false",
+		},
+		{
+			name: "no terraform state",
+			id:   managedID,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &Disk{BaseResource: &BaseResource{}, Name: ptr.String("disk"), ID: ptr.String(tc.id)}
+			r.BeforeEnqueue(&queue.Item{
+				Resource: r, State: queue.ItemStateNew, Type: DiskResource,
+				Opts: &azure.ListerOpts{Terraform: tc.state},
+			})
+
+			assert.Equal(t, tc.managed, r.Properties().Get("ManagedByTerraform"))
+			assert.Equal(t, tc.source, r.Properties().Get("TerraformState"))
+			assert.Equal(t, tc.id, r.Properties().Get("ResourceID"))
+		})
+	}
+}
+
+func TestResourcesAreSameAsListingAfterEnqueue(t *testing.T) {
+	server := newListerTestServer()
+	defer server.Close()
+
+	for name, reg := range registry.GetRegistrations() {
+		if reg.Scope != azure.ResourceGroupScope {
+			continue
+		}
+
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			groups := azure.NewResourceGroupIndex()
+			groups.Add("00000000-0000-0000-0000-000000000001", &azure.ResourceGroupDetails{
+				Name:     "test-rg",
+				Location: "westeurope",
+				Tags:     map[string]*string{"keep": ptr.String("true")},
+			})
+
+			opts := &azure.ListerOpts{
+				Authorizers:          newTestAuthorizers(t, server.URL),
+				TenantID:             "00000000-0000-0000-0000-000000000000",
+				SubscriptionID:       "00000000-0000-0000-0000-000000000001",
+				ResourceGroup:        "test-rg",
+				Regions:              []string{"all"},
+				ResourceGroupDetails: groups,
+				Terraform:            newTestTerraformState(t),
+			}
+
+			resources, err := reg.Lister.List(ctx, opts)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, resources)
+
+			item := &queue.Item{Resource: resources[0], State: queue.ItemStateNew, Type: name, Opts: opts}
+			resources[0].(resource.QueueItemHook).BeforeEnqueue(item)
+
+			listed, err := reg.Lister.List(ctx, opts)
+			assert.NoError(t, err)
+			assert.False(t, item.Equals(listed[0]))
+			assert.True(t, SameResource(item.Resource, listed[0]), "the resource is the same as the listing of it")
+
+			props := resources[0].(resource.PropertyGetter).Properties()
+			assert.NotEmpty(t, props.Get("ResourceID"))
+			assert.Equal(t, "westeurope", props.Get("ResourceGroupLocation"))
+			assert.Equal(t, "true", props.Get("rg-tag:keep"))
+			assert.NotEmpty(t, props.Get("ManagedByTerraform"))
+		})
+	}
+}
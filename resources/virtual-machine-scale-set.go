@@ -0,0 +1,156 @@
+package resources
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gotidy/ptr"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute" //nolint:staticcheck
+
+	"github.com/ekristen/libnuke/pkg/registry"
+	"github.com/ekristen/libnuke/pkg/resource"
+	"github.com/ekristen/libnuke/pkg/types"
+
+	"github.com/ekristen/azure-nuke/pkg/azure"
+)
+
+const VirtualMachineScaleSetResource = "VirtualMachineScaleSet"
+
+func init() {
+	registry.Register(&registry.Registration{
+		Name:     VirtualMachineScaleSetResource,
+		Scope:    azure.ResourceGroupScope,
+		Resource: &VirtualMachineScaleSet{},
+		Lister:   &VirtualMachineScaleSetLister{},
+	})
+}
+
+type VirtualMachineScaleSet struct {
+	*BaseResource `property:",inline"`
+
+	client   compute.VirtualMachineScaleSetsClient
+	ID       *string `property:"-"`
+	Name     *string
+	Capacity *int64 `description:"The number of virtual machines in the scale set."`
+}
+
+func (r *VirtualMachineScaleSet) Remove(ctx context.Context) error {
+	if r.StopMode() {
+		return r.Stop(ctx)
+	}
+
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
+	_, err := r.client.Delete(ctx, *r.ResourceGroup, *r.Name, nil)
+	return err
+}
+
+// StopAction returns no action for the scale sets of the node pools of a kubernetes cluster, those are stopped
+// along with their cluster
+func (r *VirtualMachineScaleSet) StopAction() string {
+	for key := range r.Tags {
+		if strings.HasPrefix(key, "aks-managed-") {
+			return ""
+		}
+	}
+
+	return "scale to zero"
+}
+
+func (r *VirtualMachineScaleSet) Stopped() bool {
+	return ptr.ToInt64(r.Capacity) == 0
+}
+
+func (r *VirtualMachineScaleSet) Stop(ctx context.Context) error {
+	_, err := r.client.Update(ctx, *r.ResourceGroup, *r.Name, compute.VirtualMachineScaleSetUpdate{
+		Sku: &compute.Sku{
+			Capacity: ptr.Int64(0),
+		},
+	})
+	return err
+}
+
+func (r *VirtualMachineScaleSet) VolatileProperties() []string {
+	return []string{"Capacity"}
+}
+
+func (r *VirtualMachineScaleSet) Properties() types.Properties {
+	return types.NewPropertiesFromStruct(r)
+}
+
+func (r *VirtualMachineScaleSet) String() string {
+	return *r.Name
+}
+
+// -----------------------------------------
+
+type VirtualMachineScaleSetLister struct {
+}
+
+func (l VirtualMachineScaleSetLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
+	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
+
+	if opts.SkipByInventory("microsoft.compute/virtualmachinescalesets") {
+		return nil, nil
+	}
+
+	return opts.ListByResourceGroup(ctx, VirtualMachineScaleSetResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
+	})
+}
+
+// listSubscription lists the virtual machine scale sets of every resource group of the subscription
+func (l VirtualMachineScaleSetLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
+	log := opts.Logger(VirtualMachineScaleSetResource)
+
+	client := compute.NewVirtualMachineScaleSetsClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
+	client.Authorizer = opts.Authorizers.Management
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
+
+	resources := make([]resource.Resource, 0)
+
+	log.Trace("attempting to list virtual machine scale sets")
+
+	list, err := client.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Trace("listing resources")
+
+	for list.NotDone() {
+		log.Trace("list not done")
+		for _, g := range list.Values() {
+			var capacity *int64
+			if g.Sku != nil {
+				capacity = g.Sku.Capacity
+			}
+
+			resources = append(resources, &VirtualMachineScaleSet{
+				BaseResource: &BaseResource{
+					Region:         g.Location,
+					ResourceGroup:  opts.ResourceGroupFromID(g.ID),
+					SubscriptionID: &opts.SubscriptionID,
+					SystemData:     opts.SystemData(ctx, g.ID),
+					Tags:           g.Tags,
+				},
+				client:   client,
+				ID:       g.ID,
+				Name:     g.Name,
+				Capacity: capacity,
+			})
+		}
+
+		if err := list.NextWithContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Trace("done")
+
+	return resources, nil
+}
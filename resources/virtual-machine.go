@@ -2,10 +2,10 @@ package resources
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/gotidy/ptr"
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute" //nolint:staticcheck
 
@@ -31,16 +31,43 @@ type VirtualMachine struct {
 	*BaseResource `property:",inline"`
 
 	client       compute.VirtualMachinesClient
+	ID           *string `property:"-"`
 	Name         *string
-	Tags         map[string]*string
 	CreationDate *time.Time
+	PowerState   *string `description:"The power state of the virtual machine (i.e. running or deallocated)."`
 }
 
 func (r *VirtualMachine) Remove(ctx context.Context) error {
+	if r.StopMode() {
+		return r.Stop(ctx)
+	}
+
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Delete(ctx, *r.ResourceGroup, *r.Name, &[]bool{true}[0])
 	return err
 }
 
+func (r *VirtualMachine) StopAction() string {
+	return "deallocate"
+}
+
+func (r *VirtualMachine) Stopped() bool {
+	state := ptr.ToString(r.PowerState)
+	return state == "deallocated" || state == "deallocating"
+}
+
+func (r *VirtualMachine) Stop(ctx context.Context) error {
+	_, err := r.client.Deallocate(ctx, *r.ResourceGroup, *r.Name)
+	return err
+}
+
+func (r *VirtualMachine) VolatileProperties() []string {
+	return []string{"PowerState"}
+}
+
 func (r *VirtualMachine) Properties() types.Properties {
 	return types.NewPropertiesFromStruct(r)
 }
@@ -56,19 +83,30 @@ type VirtualMachineLister struct {
 
 func (l VirtualMachineLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
 
-	log := logrus.WithField("r", VirtualMachineResource).WithField("s", opts.SubscriptionID)
+	if opts.SkipByInventory("microsoft.compute/virtualmachines") {
+		return nil, nil
+	}
+
+	return opts.ListByResourceGroup(ctx, VirtualMachineResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
+	})
+}
+
+// listSubscription lists the virtual machines of every resource group of the subscription
+func (l VirtualMachineLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
+	log := opts.Logger(VirtualMachineResource)
 
-	client := compute.NewVirtualMachinesClient(opts.SubscriptionID)
+	client := compute.NewVirtualMachinesClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
 	client.Authorizer = opts.Authorizers.Management
-	client.RetryAttempts = 1
-	client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	resources := make([]resource.Resource, 0)
 
 	log.Trace("attempting to list virtual machines")
 
-	list, err := client.List(ctx, opts.ResourceGroup)
+	list, err := client.ListAll(ctx, "true")
 	if err != nil {
 		return nil, err
 	}
@@ -78,29 +116,30 @@ func (l VirtualMachineLister) List(ctx context.Context, o interface{}) ([]resour
 	for list.NotDone() {
 		log.Trace("list not done")
 		for _, g := range list.Values() {
-			instanceView, err := client.InstanceView(ctx, opts.ResourceGroup, *g.Name)
-			if err != nil {
-				return nil, err
-			}
-
 			var creationDate *time.Time
-			for _, status := range *instanceView.Statuses {
-				if status.Code != nil && *status.Code == "ProvisioningState/succeeded" {
+			var powerState *string
+			for _, status := range virtualMachineStatuses(g) {
+				if status.Code != nil && *status.Code == "ProvisioningState/succeeded" && status.Time != nil {
 					creationDate = ptr.Time(status.Time.Time)
-					break
+				}
+				if status.Code != nil && strings.HasPrefix(*status.Code, "PowerState/") {
+					powerState = ptr.String(strings.TrimPrefix(*status.Code, "PowerState/"))
 				}
 			}
 
 			resources = append(resources, &VirtualMachine{
 				BaseResource: &BaseResource{
 					Region:         g.Location,
-					ResourceGroup:  &opts.ResourceGroup,
+					ResourceGroup:  opts.ResourceGroupFromID(g.ID),
 					SubscriptionID: &opts.SubscriptionID,
+					SystemData:     opts.SystemData(ctx, g.ID),
+					Tags:           g.Tags,
 				},
 				client:       client,
+				ID:           g.ID,
 				Name:         g.Name,
-				Tags:         g.Tags,
 				CreationDate: creationDate,
+				PowerState:   powerState,
 			})
 		}
 
@@ -113,3 +152,13 @@ func (l VirtualMachineLister) List(ctx context.Context, o interface{}) ([]resour
 
 	return resources, nil
 }
+
+// virtualMachineStatuses returns the statuses of the instance view of the virtual machine, the instance view is part
+// of the listing as the virtual machines are listed with their status
+func virtualMachineStatuses(vm compute.VirtualMachine) []compute.InstanceViewStatus {
+	if vm.VirtualMachineProperties == nil || vm.InstanceView == nil || vm.InstanceView.Statuses == nil {
+		return nil
+	}
+
+	return *vm.InstanceView.Statuses
+}
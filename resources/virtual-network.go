@@ -2,9 +2,6 @@ package resources
 
 import (
 	"context"
-	"time"
-
-	"github.com/sirupsen/logrus"
 
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-05-01/network" //nolint:staticcheck
 
@@ -31,19 +28,30 @@ type VirtualNetworkLister struct {
 
 func (l VirtualNetworkLister) List(ctx context.Context, o interface{}) ([]resource.Resource, error) {
 	opts := o.(*azure.ListerOpts)
+	ctx = azure.WithSystemData(ctx)
+
+	if opts.SkipByInventory("microsoft.network/virtualnetworks") {
+		return nil, nil
+	}
+
+	return opts.ListByResourceGroup(ctx, VirtualNetworkResource, func(ctx context.Context) ([]resource.Resource, error) {
+		return l.listSubscription(ctx, opts)
+	})
+}
 
-	log := logrus.WithField("r", VirtualNetworkResource).WithField("s", opts.SubscriptionID)
+// listSubscription lists the virtual networks of every resource group of the subscription
+func (l VirtualNetworkLister) listSubscription(ctx context.Context, opts *azure.ListerOpts) ([]resource.Resource, error) {
+	log := opts.Logger(VirtualNetworkResource)
 
-	client := network.NewVirtualNetworksClient(opts.SubscriptionID)
+	client := network.NewVirtualNetworksClientWithBaseURI(opts.ResourceManagerEndpoint(), opts.SubscriptionID)
 	client.Authorizer = opts.Authorizers.Management
-	client.RetryAttempts = 1
-	client.RetryDuration = time.Second * 2
+	opts.Authorizers.Pipeline.ConfigureAutorest(&client.Client)
 
 	resources := make([]resource.Resource, 0)
 
 	log.Trace("attempting to list virtual networks")
 
-	list, err := client.List(ctx, opts.ResourceGroup)
+	list, err := client.ListAll(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -55,12 +63,14 @@ func (l VirtualNetworkLister) List(ctx context.Context, o interface{}) ([]resour
 			resources = append(resources, &VirtualNetwork{
 				BaseResource: &BaseResource{
 					Region:         g.Location,
-					ResourceGroup:  &opts.ResourceGroup,
+					ResourceGroup:  opts.ResourceGroupFromID(g.ID),
 					SubscriptionID: &opts.SubscriptionID,
+					SystemData:     opts.SystemData(ctx, g.ID),
+					Tags:           g.Tags,
 				},
 				client: client,
+				ID:     g.ID,
 				Name:   g.Name,
-				Tags:   g.Tags,
 			})
 		}
 
@@ -80,11 +90,15 @@ type VirtualNetwork struct {
 	*BaseResource `property:",inline"`
 
 	client network.VirtualNetworksClient
+	ID     *string `property:"-"`
 	Name   *string
-	Tags   map[string]*string
 }
 
 func (r *VirtualNetwork) Remove(ctx context.Context) error {
+	if err := r.HoldForLocks(); err != nil {
+		return err
+	}
+
 	_, err := r.client.Delete(ctx, *r.ResourceGroup, *r.Name)
 	return err
 }
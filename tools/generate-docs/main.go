@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"reflect"
 	"sort"
 	"strings"
 
@@ -23,7 +24,7 @@ func main() {
 			continue
 		}
 
-		propMap := docs.GeneratePropertiesMap(reg.Resource)
+		propMap := propertiesMap(reg.Resource)
 
 		niceName := strings.Join(camelcase.Split(reg.Name), " ")
 
@@ -36,7 +37,8 @@ func main() {
 		markdown += "\n"
 
 		markdown += "## Properties\n\n"
-		for k, v := range propMap {
+		for _, k := range sortedKeys(propMap) {
+			v := propMap[k]
 			if v == "" {
 				v = "No description provided"
 			}
@@ -92,3 +94,57 @@ func main() {
 		fmt.Printf("- %s: resources/%s.md\n", name, filename)
 	}
 }
+
+// propertiesMap returns the properties of the resource, the properties of inlined structs (i.e. BaseResource) are
+// listed as properties of the resource itself as that is how they are exposed to the filters, the same goes for maps
+// with their own tag prefix (i.e. `rg-tag:<key>:`)
+func propertiesMap(resource interface{}) map[string]string {
+	properties := docs.GeneratePropertiesMap(resource)
+
+	t := reflect.TypeOf(resource)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		options := strings.Split(field.Tag.Get("property"), ",")
+		if !field.IsExported() {
+			continue
+		}
+
+		if tagPrefix, ok := strings.CutPrefix(options[0], "tagPrefix="); ok && field.Type.Kind() == reflect.Map {
+			delete(properties, field.Name)
+			properties[fmt.Sprintf("%s:<key>:", tagPrefix)] = field.Tag.Get("description")
+			continue
+		}
+
+		if len(options) != 2 || options[1] != "inline" {
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		delete(properties, field.Name)
+		for k, v := range propertiesMap(reflect.New(fieldType).Interface()) {
+			properties[k] = v
+		}
+	}
+
+	return properties
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}